@@ -15,35 +15,111 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/leaderboard/join": {
-            "post": {
-                "description": "Match a player to a competition or enqueue them",
-                "summary": "Join a leaderboard competition",
+        "/admin/audit": {
+            "get": {
+                "description": "List the moderations of the scores and players, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the audit log",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Player ID",
+                        "description": "Only list the moderations of this player",
                         "name": "player_id",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/storage.AuditRecord"
+                            }
+                        }
                     }
+                }
+            }
+        },
+        "/admin/flagged": {
+            "get": {
+                "description": "List the score submissions rejected by the anti-cheat validators, oldest first, for review",
+                "produces": [
+                    "application/json"
                 ],
+                "summary": "List flagged score submissions",
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/leaderboard.FlaggedSubmission"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/leaderboard/{leaderboardID}/players/{playerID}/adjust": {
+            "post": {
+                "description": "Add points to the score of a player, or remove them with negative points, whatever the scoring mode",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Adjust a score",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Leaderboard ID",
+                        "name": "leaderboardID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "playerID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "points and reason",
+                        "name": "adjustment",
+                        "in": "body",
+                        "required": true,
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/storage.AuditRecord"
+                        }
                     },
                     "400": {
-                        "description": "Player ID is empty or player not found",
+                        "description": "Invalid request body, zero points or empty reason",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Leaderboard or player not found",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "409": {
-                        "description": "Player already in competition",
+                        "description": "Competition has not started or has ended",
                         "schema": {
                             "type": "string"
                         }
@@ -51,41 +127,155 @@ const docTemplate = `{
                 }
             }
         },
-        "/leaderboard/player/{playerID}": {
-            "get": {
-                "description": "Get current or last competition for a player",
-                "summary": "Get player leaderboard",
+        "/admin/leaderboard/{leaderboardID}/players/{playerID}/disqualify": {
+            "post": {
+                "description": "Remove a player from the ranking of a competition. The player keeps their score and history\nbut cannot submit scores to the competition anymore.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Disqualify a player",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Leaderboard ID",
+                        "name": "leaderboardID",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "string",
                         "description": "Player ID",
                         "name": "playerID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "reason",
+                        "name": "disqualification",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/storage.AuditRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or empty reason",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Leaderboard or player not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Competition has not started or has ended, or player already disqualified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/leaderboard/{leaderboardID}/players/{playerID}/void": {
+            "post": {
+                "description": "Cancel a submission applied for a player and recompute their score without it.\nThe submission is identified by its submission_id, or by its seq in the score history of the player,\nwhich submissions sent without an ID can only be voided by. It stays in the history marked as voided.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Void a score submission",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Leaderboard ID",
+                        "name": "leaderboardID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "playerID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "submission_id or seq, and reason",
+                        "name": "void",
+                        "in": "body",
+                        "required": true,
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/storage.AuditRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, neither or both of submission ID and seq, or empty reason",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Leaderboard, player or submission not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Competition has not started or has ended",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
                 }
             }
         },
-        "/leaderboard/score": {
+        "/admin/players/{playerID}/ban": {
             "post": {
-                "description": "Add score to the player's current competition",
+                "description": "Prevent a player from joining competitions. A player already in a competition stays in it.",
                 "consumes": [
                     "application/json"
                 ],
-                "summary": "Submit score",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Ban a player",
                 "parameters": [
                     {
-                        "description": "Score submission",
-                        "name": "score",
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "playerID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "reason",
+                        "name": "ban",
                         "in": "body",
                         "required": true,
                         "schema": {
@@ -97,12 +287,24 @@ const docTemplate = `{
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/storage.AuditRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or empty reason",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "409": {
-                        "description": "Conflict: no active competition",
+                        "description": "Player already banned",
                         "schema": {
                             "type": "string"
                         }
@@ -110,35 +312,1303 @@ const docTemplate = `{
                 }
             }
         },
-        "/leaderboard/{leaderboardID}": {
-            "get": {
-                "description": "Get leaderboard by ID",
-                "summary": "Get leaderboard",
+        "/admin/players/{playerID}/unban": {
+            "post": {
+                "description": "Allow a banned player to join competitions again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Unban a player",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Leaderboard ID",
-                        "name": "leaderboardID",
+                        "description": "Player ID",
+                        "name": "playerID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "reason",
+                        "name": "unban",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/storage.AuditRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or empty reason",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Player not banned",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
+                }
+            }
+        },
+        "/admin/webhooks": {
+            "get": {
+                "description": "List the registered webhooks, without their secrets",
+                "produces": [
+                    "application/json"
                 ],
+                "summary": "List webhooks",
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/webhooks.Webhook"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register an endpoint that receives the final leaderboard of each ended competition as a JSON webhooks.Payload.\nEach delivery is signed in the X-Leaderboard-Signature header with \"sha256=\" followed by the hexadecimal\nHMAC-SHA256 of the body with the secret. Failed deliveries are retried with an exponential backoff.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Register a webhook",
+                "parameters": [
+                    {
+                        "description": "url and secret",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/webhooks.Webhook"
+                        }
                     },
-                    "404": {
-                        "description": "Not found",
+                    "400": {
+                        "description": "Invalid request body, URL or empty secret",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             }
+        },
+        "/admin/webhooks/dead-letters": {
+            "get": {
+                "description": "List the deliveries whose attempts all failed, oldest first, with their payload and last error",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List failed webhook deliveries",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/webhooks.Delivery"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/dead-letters/{deliveryID}/replay": {
+            "post": {
+                "description": "Remove a delivery from the dead letters and deliver its payload again, with the same delivery ID,\nto the current URL and with the current secret of its webhook",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Replay a failed webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "deliveryID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/webhooks.Delivery"
+                        }
+                    },
+                    "404": {
+                        "description": "Dead letter or webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{webhookID}": {
+            "delete": {
+                "description": "Stop the deliveries to a webhook. Deliveries being retried are still attempted",
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboard/join": {
+            "post": {
+                "description": "Match a player to a competition or enqueue them",
+                "summary": "Join a leaderboard competition",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "player_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Player ID is empty or player not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Player is banned from competitions",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Player already in competition",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a waiting player from the matchmaking queue",
+                "summary": "Leave matchmaking",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "player_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Player ID is empty or player not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Player is not waiting for a match",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboard/player/{playerID}": {
+            "get": {
+                "description": "Get current or last competition for a player",
+                "summary": "Get player leaderboard",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "playerID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboard/queue/{playerID}": {
+            "get": {
+                "description": "Get the queue state of a player, the time waited, the size of the waiting competition, the levels searched and the estimated time to start",
+                "summary": "Get matchmaking queue status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "playerID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/matchmaking.QueueStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Player ID is empty or player not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Player has not joined matchmaking",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboard/score": {
+            "post": {
+                "description": "Submit a score to the player's current competition. The score is added, kept if it is the best,\nreplaces the previous one or is capped per time window depending on the scoring mode of the competition.\nNegative scores are only accepted as penalties by competitions with the penalties scoring mode.\nClients can retry a submission with the same optional submission_id: a submission already applied\nfor the player is not applied again and is reported with the replayed status.\nImplausible submissions, with too many points for any player or for the level of the player, too frequent,\nor far above the submissions of the other players of the competition, are rejected and flagged for review.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Submit score",
+                "parameters": [
+                    {
+                        "description": "Score submission",
+                        "name": "score",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/leaderboard.ScoreSubmissionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid player ID, submission ID or negative score",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict: no active competition or player disqualified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Score rejected by the anti-cheat validation",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboard/scores": {
+            "post": {
+                "description": "Submit the scores of many players at once. Each entry is applied like a single score submission,\nin order, and the entries of a competition are applied together. The response has the result of each\nentry in the order of the batch: applied, replayed, or rejected with the HTTP status and message\nthe single score submission would return.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Submit a batch of scores",
+                "parameters": [
+                    {
+                        "description": "Score submissions (at most 1000)",
+                        "name": "scores",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/leaderboard.ScoreEntry"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/leaderboard.BatchScoreResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or batch size",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboard/{leaderboardID}": {
+            "get": {
+                "description": "Get a page of a leaderboard by ID, or the players ranked around a player. Ranks follow the configured tie policy",
+                "summary": "Get leaderboard",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Leaderboard ID",
+                        "name": "leaderboardID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of players to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of players to return (default 50, maximum 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the players ranked around this player instead of a page",
+                        "name": "around",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of players above and below the around player (default 5, maximum 50)",
+                        "name": "radius",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/leaderboard.LeaderboardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid offset, limit or radius",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Leaderboard not found or player not in the leaderboard",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboard/{leaderboardID}/players/{playerID}/history": {
+            "get": {
+                "description": "Get the accepted score submissions of a player in a competition, oldest first, with the points submitted,\nthe change of the score, the resulting score and where the submission comes from",
+                "summary": "Get the score history of a player",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Leaderboard ID",
+                        "name": "leaderboardID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "playerID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/leaderboard.ScoreHistoryResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Leaderboard not found or player not in the leaderboard",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboard/{leaderboardID}/ws": {
+            "get": {
+                "description": "Open a WebSocket that receives JSON events: a snapshot of the leaderboard on connect, then rank_changed,\ncompetition_started and competition_ended events. The connection is closed after competition_ended.\nA client that does not keep up with the events is sent a new snapshot instead of the missed events.",
+                "summary": "Follow a leaderboard live",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Leaderboard ID",
+                        "name": "leaderboardID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "$ref": "#/definitions/live.Event"
+                        }
+                    },
+                    "404": {
+                        "description": "Leaderboard not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Too many subscribers for this leaderboard",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboards/country/{countryCode}": {
+            "get": {
+                "description": "Get the all-time standings of the competitions played by the players of a country",
+                "summary": "Get the leaderboard of a country",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ISO 3166-1 alpha-2 country code",
+                        "name": "countryCode",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of standings to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of standings to return (default 50, maximum 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/leaderboard.AggregateLeaderboardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid country code, offset or limit",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboards/global": {
+            "get": {
+                "description": "Get the all-time standings of all players, accumulated from the results of ended competitions",
+                "summary": "Get the global leaderboard",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of standings to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of standings to return (default 50, maximum 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/leaderboard.AggregateLeaderboardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid offset or limit",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboards/level/{level}": {
+            "get": {
+                "description": "Get the all-time standings of the competitions played at a level",
+                "summary": "Get the leaderboard of a level",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Player level",
+                        "name": "level",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of standings to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of standings to return (default 50, maximum 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/leaderboard.AggregateLeaderboardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid level, offset or limit",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/players": {
+            "post": {
+                "description": "Register a new player",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Register player",
+                "parameters": [
+                    {
+                        "description": "Player with id, level and country_code",
+                        "name": "player",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/players.PlayerResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid player ID, level or country code",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Player already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/players/{playerID}": {
+            "get": {
+                "description": "Get a player profile by ID",
+                "summary": "Get player",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "playerID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/players.PlayerResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a player. A waiting player is removed from matchmaking, a player in a started competition keeps their leaderboard entry",
+                "summary": "Delete player",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "playerID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update the level and/or country code of a player. The new level is used the next time the player joins a competition",
+                "consumes": [
+                    "application/json"
+                ],
+                "summary": "Update player",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "playerID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update: level, country_code",
+                        "name": "player",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/players.PlayerResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid level or country code",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Player is waiting for a match",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/players/{playerID}/events": {
+            "get": {
+                "description": "Open a Server-Sent Events stream of the matchmaking and competition events of a player:\nqueued, matched, competition_started, rank_changed and competition_ended. The data of each event is a JSON notifications.Notification.\nEvents published while the stream is closed are not replayed.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "summary": "Stream the events of a player",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "playerID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/notifications.Notification"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many event streams for this player",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "leaderboard.AggregateLeaderboardResponse": {
+            "type": "object",
+            "properties": {
+                "board": {
+                    "type": "string"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "standings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/leaderboard.Standing"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "leaderboard.BatchScoreResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "description": "Results of the entries in the order they were submitted",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/leaderboard.ScoreSubmissionResponse"
+                    }
+                }
+            }
+        },
+        "leaderboard.FlaggedSubmission": {
+            "type": "object",
+            "properties": {
+                "flagged_at": {
+                    "type": "string"
+                },
+                "leaderboard_id": {
+                    "type": "string"
+                },
+                "player_id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "submission_id": {
+                    "type": "string"
+                },
+                "validator": {
+                    "type": "string"
+                }
+            }
+        },
+        "leaderboard.LeaderboardResponse": {
+            "type": "object",
+            "properties": {
+                "ends_at": {
+                    "type": "string"
+                },
+                "leaderboard": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/leaderboard.PlayerScore"
+                    }
+                },
+                "leaderboard_id": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "leaderboard.PlayerScore": {
+            "type": "object",
+            "properties": {
+                "player_id": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                }
+            }
+        },
+        "leaderboard.ScoreChangeResponse": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "delta": {
+                    "description": "Change of the score once the scoring mode of the competition is applied, and the resulting score",
+                    "type": "integer"
+                },
+                "points": {
+                    "description": "Points submitted",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "Why a moderator changed the score, and whether they voided the submission",
+                    "type": "string"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "seq": {
+                    "description": "Order of the change in the competition, used to void a submission sent without an ID",
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "submission_id": {
+                    "type": "string"
+                },
+                "voided": {
+                    "type": "boolean"
+                },
+                "voided_seq": {
+                    "description": "Seq of the submission voided by a change with the void source",
+                    "type": "integer"
+                }
+            }
+        },
+        "leaderboard.ScoreEntry": {
+            "type": "object",
+            "properties": {
+                "player_id": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "submission_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "leaderboard.ScoreHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "disqualified": {
+                    "type": "boolean"
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/leaderboard.ScoreChangeResponse"
+                    }
+                },
+                "leaderboard_id": {
+                    "type": "string"
+                },
+                "player_id": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "integer"
+                }
+            }
+        },
+        "leaderboard.ScoreSubmissionResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "HTTP status and message of a rejected entry of a batch",
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "player_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "\"applied\", \"replayed\" if a submission with the same ID was already applied, or \"rejected\" in a batch",
+                    "type": "string"
+                },
+                "submission_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "leaderboard.Standing": {
+            "type": "object",
+            "properties": {
+                "competitions": {
+                    "type": "integer"
+                },
+                "player_id": {
+                    "type": "string"
+                },
+                "podiums": {
+                    "type": "integer"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "wins": {
+                    "type": "integer"
+                }
+            }
+        },
+        "live.Event": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "change": {
+                    "description": "Change of the rank_changed events",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/live.RankChange"
+                        }
+                    ]
+                },
+                "leaderboard": {
+                    "description": "Leaderboard of the snapshot, competition_started and competition_ended events",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/leaderboard.LeaderboardResponse"
+                        }
+                    ]
+                },
+                "leaderboard_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "live.RankChange": {
+            "type": "object",
+            "properties": {
+                "player_id": {
+                    "type": "string"
+                },
+                "previous_rank": {
+                    "type": "integer"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                }
+            }
+        },
+        "matchmaking.QueueStatus": {
+            "type": "object",
+            "properties": {
+                "competition_id": {
+                    "type": "string"
+                },
+                "estimated_start_seconds": {
+                    "description": "Estimated time until the next attempt to start the waiting competition",
+                    "type": "integer"
+                },
+                "failures": {
+                    "description": "Number of consecutive failed attempts and the last error",
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "lobby_size": {
+                    "description": "Number of players in the waiting competition",
+                    "type": "integer"
+                },
+                "max_level": {
+                    "type": "integer"
+                },
+                "min_level": {
+                    "description": "Levels currently searched for matching competitions",
+                    "type": "integer"
+                },
+                "player_id": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "waited_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "notifications.Notification": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "description": "End of the competition, in the competition_started notification",
+                    "type": "string"
+                },
+                "leaderboard_id": {
+                    "type": "string"
+                },
+                "player_id": {
+                    "type": "string"
+                },
+                "standing": {
+                    "description": "Score and rank of the player, in the rank_changed and competition_ended notifications",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/notifications.Standing"
+                        }
+                    ]
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "notifications.Standing": {
+            "type": "object",
+            "properties": {
+                "previous_rank": {
+                    "type": "integer"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                }
+            }
+        },
+        "players.PlayerResponse": {
+            "type": "object",
+            "properties": {
+                "banned": {
+                    "type": "boolean"
+                },
+                "competition_id": {
+                    "type": "string"
+                },
+                "country_code": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "integer"
+                }
+            }
+        },
+        "storage.AuditRecord": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "at": {
+                    "type": "string"
+                },
+                "leaderboard_id": {
+                    "type": "string"
+                },
+                "player_id": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "seq": {
+                    "description": "Sequence number of the voided submission in the history of the player",
+                    "type": "integer"
+                },
+                "submission_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhooks.Delivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhooks.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
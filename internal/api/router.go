@@ -17,9 +17,40 @@ func Router() http.Handler {
 	r.Handle("/metrics", promhttp.Handler())
 
 	r.Post("/leaderboard/join", handlers.JoinHandler)
+	r.Delete("/leaderboard/join", handlers.CancelJoinHandler)
+	r.Get("/leaderboard/queue/{playerID}", handlers.QueueStatusHandler)
 	r.Post("/leaderboard/score", handlers.SubmitScoreHandler)
+	r.Post("/leaderboard/scores", handlers.SubmitScoresHandler)
 	r.Get("/leaderboard/player/{playerID}", handlers.PlayerLeaderboardHandler)
 	r.Get("/leaderboard/{leaderboardID}", handlers.LeaderboardHandler)
+	r.Get("/leaderboard/{leaderboardID}/players/{playerID}/history", handlers.ScoreHistoryHandler)
+	r.Get("/leaderboard/{leaderboardID}/ws", handlers.LeaderboardSocketHandler)
+
+	r.Get("/leaderboards/global", handlers.GlobalLeaderboardHandler)
+	r.Get("/leaderboards/level/{level}", handlers.LevelLeaderboardHandler)
+	r.Get("/leaderboards/country/{countryCode}", handlers.CountryLeaderboardHandler)
+
+	r.Post("/players", handlers.CreatePlayerHandler)
+	r.Get("/players/{playerID}", handlers.GetPlayerHandler)
+	r.Patch("/players/{playerID}", handlers.UpdatePlayerHandler)
+	r.Delete("/players/{playerID}", handlers.DeletePlayerHandler)
+	r.Get("/players/{playerID}/events", handlers.PlayerEventsHandler)
+
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(handlers.AdminAuth)
+		r.Get("/flagged", handlers.FlaggedSubmissionsHandler)
+		r.Get("/audit", handlers.AuditLogHandler)
+		r.Post("/leaderboard/{leaderboardID}/players/{playerID}/void", handlers.VoidSubmissionHandler)
+		r.Post("/leaderboard/{leaderboardID}/players/{playerID}/adjust", handlers.AdjustScoreHandler)
+		r.Post("/leaderboard/{leaderboardID}/players/{playerID}/disqualify", handlers.DisqualifyPlayerHandler)
+		r.Post("/players/{playerID}/ban", handlers.BanPlayerHandler)
+		r.Post("/players/{playerID}/unban", handlers.UnbanPlayerHandler)
+		r.Post("/webhooks", handlers.RegisterWebhookHandler)
+		r.Get("/webhooks", handlers.ListWebhooksHandler)
+		r.Delete("/webhooks/{webhookID}", handlers.DeleteWebhookHandler)
+		r.Get("/webhooks/dead-letters", handlers.DeadLettersHandler)
+		r.Post("/webhooks/dead-letters/{deliveryID}/replay", handlers.ReplayDeliveryHandler)
+	})
 
 	return r
 }
@@ -7,14 +7,86 @@ import "time"
 var (
 	MatchWaitDuration       = 30 * time.Second
 	MatchRetryInterval      = 1 * time.Second
+	MatchMaxWait            = 2 * time.Minute // Waiting players are removed from the queue after this duration
+	MatchMaxFailures        = 3               // Waiting players are removed from the queue after this many failed attempts in a row
+	MatchmakingEngine       = "loop"          // "loop" or "mutex"
+	MatchWheelTick          = 10 * time.Millisecond
+	MatchWheelSlots         = 1024 // Slots of the timing wheel of the loop engine, attempts further away wait several rounds
 	CompetitionDuration     = 1 * time.Hour
+	FinalizeInterval        = 1 * time.Second // Interval at which the competitions past their end time are finalized
 	MaxCompetitionsInMemory = 100
-)
 
-const (
 	MaxPlayersForCompetition = 10
 	MinPlayersForCompetition = 2
 
+	RankTiePolicy          = "ordinal" // "ordinal", "competition" or "dense"
+	PodiumSize             = 3         // Ranks counted as podiums by the aggregate leaderboards
+	LeaderboardPageSize    = 50        // Default number of entries per page of the leaderboards
+	MaxLeaderboardPageSize = 100       // Maximum number of entries per page of the leaderboards
+	LeaderboardRadius      = 5         // Default number of players returned above and below a player of a competition leaderboard
+	MaxLeaderboardRadius   = 50
+
+	TieBreaker  = "player_id"         // "player_id", "earliest", "fewest_submissions", "higher_level", "lower_level" or "shared"
+	TieBreakers = map[string]string{} // Tie-breaker of the competitions created by each matchmaking mode, overriding TieBreaker
+
+	ScoringMode        = "increment"         // "increment", "penalties", "best", "latest" or "window"
+	ScoringModes       = map[string]string{} // Scoring mode of the competitions created by each matchmaking mode, overriding ScoringMode
+	ScoreWindow        = 1 * time.Minute     // Duration of the windows of the window scoring mode, from the start of the competition
+	MaxPointsPerWindow = 1000                // Points a player can add in each window of the window scoring mode
+	SubmissionIdTTL    = 24 * time.Hour      // How long the submission IDs of applied scores are remembered to ignore retries
+	MaxScoreBatchSize  = 1000                // Maximum number of entries of a batch of score submissions
+
+	MaxPointsPerSubmission = 10000           // Points a player can submit at once
+	LevelPointCeilings     = map[int]int{}   // Points a player of each level can submit at once, lower than MaxPointsPerSubmission
+	ScoreRateInterval      = 1 * time.Minute // Interval over which the score submissions of a player are counted
+	MaxScoresPerInterval   = 60              // Score submissions a player can make in each ScoreRateInterval
+	OutlierDeviations      = 4.0             // Standard deviations above the mean of the other players' submissions a submission can be
+	OutlierMinSamples      = 20              // Submissions of the other players needed before detecting outliers
+	MaxFlaggedSubmissions  = 10000           // Rejected submissions kept for review, the oldest are forgotten first
+
+	MatchmakingMode     = "level"                     // "level", "country", "skill", "fifo" or "oldest"
+	MatchFallbackLadder = []string{"region", "level"} // Fallback steps tried when no match is found within the wait duration
+	SkillWindow         = 1                           // Level difference accepted by the skill and oldest matchmaking modes
+
+	// Countries matched together by the "region" fallback step of the country matchmaking mode
+	CountryRegions = map[string]string{
+		"US": "NA", "CA": "NA", "MX": "NA",
+		"AR": "SA", "BR": "SA", "CL": "SA", "CO": "SA", "EC": "SA", "PE": "SA", "UY": "SA",
+		"BG": "EU", "CH": "EU", "DE": "EU", "ES": "EU", "FI": "EU", "FR": "EU", "GB": "EU", "HU": "EU", "IE": "EU",
+		"IT": "EU", "NL": "EU", "NO": "EU", "PL": "EU", "PT": "EU", "RO": "EU", "RS": "EU", "RU": "EU", "SE": "EU",
+		"AE": "MEA", "DZ": "MEA", "EG": "MEA", "IL": "MEA", "JO": "MEA", "LB": "MEA", "MA": "MEA", "SA": "MEA",
+		"SD": "MEA", "SN": "MEA", "TN": "MEA", "TR": "MEA",
+		"BD": "APAC", "CN": "APAC", "IN": "APAC", "JP": "APAC", "KR": "APAC", "PK": "APAC", "SG": "APAC",
+		"AU": "OCE", "NZ": "OCE",
+	}
+
+	AdminToken = "" // Bearer token required by the admin endpoints, which are disabled if empty
+
+	MaxSubscribersPerCompetition = 1000             // Clients that can follow the live leaderboard of a competition
+	SubscriberBufferSize         = 64               // Events queued for a client of a live leaderboard before it is sent a new snapshot instead
+	SubscriberWriteTimeout       = 10 * time.Second // Time a client of a live leaderboard has to receive an event before it is disconnected
+
+	MaxEventStreamsPerPlayer = 5                // Event streams a player can open at once
+	EventStreamBufferSize    = 64               // Events queued for an event stream before new ones are dropped
+	EventStreamKeepAlive     = 15 * time.Second // Interval of the comments sent on an idle event stream so proxies keep it open
+
+	WebhookTimeout        = 10 * time.Second // Time a webhook endpoint has to answer a delivery attempt
+	WebhookMaxAttempts    = 6                // Attempts of a delivery before it is moved to the dead letters
+	WebhookInitialBackoff = 1 * time.Second  // Wait before the second attempt, doubled after each failed attempt
+	WebhookMaxBackoff     = 5 * time.Minute  // Maximum wait between two attempts
+	MaxDeadLetters        = 1000             // Failed deliveries kept for replay, the oldest are dropped first
+
+	StorageType            = "memory"           // "memory", "file" or "wal"
+	StorageFilePath        = "leaderboard.json" // Snapshot file used by the file and wal storages
+	FileSaveDelay          = 1 * time.Second    // Maximum time the file storage waits to sync the appended changes to disk
+	FileCompactionInterval = 5 * time.Minute    // Interval at which the log of the file storage is compacted into its snapshot
+
+	WalFilePath           = "leaderboard.wal" // Write-ahead log used by the wal storage
+	WalSyncInterval       = 10 * time.Millisecond
+	WalCompactionInterval = 5 * time.Minute
+)
+
+const (
 	MaxLevel = 10 // Maximum level a player can have
 	MinLevel = 1  // Minimum level a player can have
 )
@@ -0,0 +1,119 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type subscriber struct {
+	// accepts returns true for the events of the type subscribed to
+	accepts func(Event) bool
+	handle  func(Event)
+	// Queue of an asynchronous subscriber, nil for a synchronous one
+	queue chan Event
+	// Closed when an asynchronous subscriber unsubscribes
+	done chan struct{}
+}
+
+var (
+	// Subscribers in the order they subscribed. The slice is replaced, never modified, so it can be read without the lock
+	bus = struct {
+		sync.Mutex
+		subscribers []*subscriber
+	}{}
+
+	eventsDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "leaderboard_events_dropped_total",
+		Help: "The total number of events dropped because the queue of an asynchronous subscriber was full",
+	}, []string{"event"})
+)
+
+// Publish calls the synchronous subscribers of an event in the goroutine of the publisher, then queues the event
+// for the asynchronous subscribers without blocking. Events are published while the publisher may hold locks,
+// so synchronous subscribers must return quickly and must not call back into the publisher.
+func Publish(event Event) {
+	bus.Lock()
+	subscribers := bus.subscribers
+	bus.Unlock()
+
+	for _, s := range subscribers {
+		if !s.accepts(event) {
+			continue
+		}
+		if s.queue == nil {
+			s.handle(event)
+			continue
+		}
+		select {
+		case s.queue <- event:
+		default:
+			eventsDropped.WithLabelValues(event.Name()).Inc()
+		}
+	}
+}
+
+// Subscribe calls handler in the goroutine of the publisher for each published event of type E.
+// E can be the Event interface to receive all events. Returns a function that unsubscribes.
+func Subscribe[E Event](handler func(E)) (unsubscribe func()) {
+	return add(newSubscriber(handler))
+}
+
+// SubscribeAsync calls handler in its own goroutine for each published event of type E, in the order they were
+// published. Up to bufferSize events are queued, later ones are dropped until the handler catches up.
+// E can be the Event interface to receive all events in order. Returns a function that unsubscribes.
+func SubscribeAsync[E Event](handler func(E), bufferSize int) (unsubscribe func()) {
+	s := newSubscriber(handler)
+	s.queue = make(chan Event, bufferSize)
+	s.done = make(chan struct{})
+	go func() {
+		for {
+			select {
+			case event := <-s.queue:
+				s.handle(event)
+			case <-s.done:
+				return
+			}
+		}
+	}()
+	return add(s)
+}
+
+func newSubscriber[E Event](handler func(E)) *subscriber {
+	return &subscriber{
+		accepts: func(event Event) bool {
+			_, ok := event.(E)
+			return ok
+		},
+		handle: func(event Event) {
+			handler(event.(E))
+		},
+	}
+}
+
+func add(s *subscriber) func() {
+	bus.Lock()
+	defer bus.Unlock()
+	bus.subscribers = append(bus.subscribers[:len(bus.subscribers):len(bus.subscribers)], s)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { remove(s) })
+	}
+}
+
+func remove(s *subscriber) {
+	bus.Lock()
+	defer bus.Unlock()
+	subscribers := make([]*subscriber, 0, len(bus.subscribers))
+	for _, other := range bus.subscribers {
+		if other != s {
+			subscribers = append(subscribers, other)
+		}
+	}
+	bus.subscribers = subscribers
+	if s.done != nil {
+		close(s.done)
+	}
+}
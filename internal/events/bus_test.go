@@ -0,0 +1,76 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubscribe_ReceivesEventsOfType(t *testing.T) {
+	queued := []PlayerQueued{}
+	unsubscribe := Subscribe(func(event PlayerQueued) { queued = append(queued, event) })
+	all := 0
+	unsubscribeAll := Subscribe(func(Event) { all++ })
+	defer unsubscribeAll()
+
+	Publish(PlayerQueued{PlayerId: "alice"})
+	Publish(CompetitionEvicted{LeaderboardId: "comp1"})
+	unsubscribe()
+	Publish(PlayerQueued{PlayerId: "bob"})
+
+	if len(queued) != 1 || queued[0].PlayerId != "alice" {
+		t.Errorf("expected only the queued event of alice, got %+v", queued)
+	}
+	if all != 3 {
+		t.Errorf("expected a subscriber of all events to receive 3 events, got %d", all)
+	}
+	unsubscribe()
+}
+
+func TestSubscribeAsync_ReceivesEventsInOrder(t *testing.T) {
+	received := make(chan Event, 3)
+	unsubscribe := SubscribeAsync(func(event Event) { received <- event }, 10)
+	defer unsubscribe()
+
+	Publish(CompetitionCreated{LeaderboardId: "comp1"})
+	Publish(CompetitionStarted{LeaderboardId: "comp1"})
+	Publish(CompetitionEnded{LeaderboardId: "comp1"})
+
+	for _, name := range []string{"competition_created", "competition_started", "competition_ended"} {
+		select {
+		case event := <-received:
+			if event.Name() != name {
+				t.Errorf("expected %s, got %s", name, event.Name())
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected %s", name)
+		}
+	}
+}
+
+func TestSubscribeAsync_DropsEventsWhenQueueIsFull(t *testing.T) {
+	release := make(chan struct{})
+	handled := make(chan string, 3)
+	unsubscribe := SubscribeAsync(func(event ScoreAdded) {
+		<-release
+		handled <- event.PlayerId
+	}, 1)
+	defer unsubscribe()
+
+	Publish(ScoreAdded{PlayerId: "alice"})
+	// Wait for the handler to take the first event, then fill the queue
+	time.Sleep(50 * time.Millisecond)
+	Publish(ScoreAdded{PlayerId: "bob"})
+	Publish(ScoreAdded{PlayerId: "carol"})
+	close(release)
+
+	for _, playerId := range []string{"alice", "bob"} {
+		if handled := <-handled; handled != playerId {
+			t.Errorf("expected the event of %s, got %s", playerId, handled)
+		}
+	}
+	select {
+	case playerId := <-handled:
+		t.Errorf("expected the event of carol to be dropped, got %s", playerId)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
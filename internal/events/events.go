@@ -0,0 +1,86 @@
+package events
+
+import "time"
+
+// Event is a domain event published on the bus
+type Event interface {
+	// Name of the event, used to label its metrics
+	Name() string
+}
+
+// PlayerQueued is published when a player joins matchmaking and waits in a competition that has not started
+type PlayerQueued struct {
+	PlayerId      string
+	LeaderboardId string
+	At            time.Time
+}
+
+// CompetitionCreated is published when matchmaking creates a competition for a player
+type CompetitionCreated struct {
+	LeaderboardId string
+	InitialLevel  int
+	At            time.Time
+}
+
+// CompetitionStarted is published when a competition starts with its players
+type CompetitionStarted struct {
+	LeaderboardId string
+	PlayerIds     []string
+	StartedAt     time.Time
+	EndsAt        time.Time
+}
+
+// ScoreAdded is published for every change of the score of a player: submissions, voided submissions and adjustments.
+// Ranks follow config.RankTiePolicy and are 0 when the player is not ranked.
+type ScoreAdded struct {
+	LeaderboardId string
+	PlayerId      string
+	At            time.Time
+	// Points submitted, the change of the score once the scoring mode is applied and the resulting score
+	Points       int
+	Delta        int
+	Score        int
+	Source       string
+	SubmissionId string
+	Rank         int
+	PreviousRank int
+}
+
+// PlayerDisqualified is published when a player is removed from the ranking of a competition
+type PlayerDisqualified struct {
+	LeaderboardId string
+	PlayerId      string
+	At            time.Time
+	Score         int
+	PreviousRank  int
+}
+
+// CompetitionEnded is published at the end of a competition with the final standing of each player, best first.
+// Disqualified players are last with rank 0.
+type CompetitionEnded struct {
+	LeaderboardId string
+	// Scheduled end of the competition
+	EndsAt    time.Time
+	EndedAt   time.Time
+	Standings []Standing
+}
+
+type Standing struct {
+	PlayerId string
+	Score    int
+	Rank     int
+}
+
+// CompetitionEvicted is published when an ended competition is removed from storage
+type CompetitionEvicted struct {
+	LeaderboardId string
+	At            time.Time
+}
+
+func (PlayerQueued) Name() string       { return "player_queued" }
+func (CompetitionCreated) Name() string { return "competition_created" }
+func (CompetitionStarted) Name() string { return "competition_started" }
+func (ScoreAdded) Name() string         { return "score_added" }
+func (PlayerDisqualified) Name() string { return "player_disqualified" }
+func (CompetitionEnded) Name() string   { return "competition_ended" }
+func (CompetitionEvicted) Name() string { return "competition_evicted" }
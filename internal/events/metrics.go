@@ -0,0 +1,17 @@
+package events
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	competitionsStarted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "leaderboard_competitions_started_total",
+		Help: "The total number of competitions started",
+	})
+)
+
+func init() {
+	Subscribe(func(CompetitionStarted) { competitionsStarted.Inc() })
+}
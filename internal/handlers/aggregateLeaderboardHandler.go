@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"leaderboard/internal/config"
+	"leaderboard/internal/leaderboard"
+	"leaderboard/internal/model"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var errInvalidPageParam = errors.New("offset and limit must be integers")
+
+// GlobalLeaderboardHandler godoc
+// @Summary      Get the global leaderboard
+// @Description  Get the all-time standings of all players, accumulated from the results of ended competitions
+// @Param        offset  query  int  false  "Number of standings to skip"
+// @Param        limit   query  int  false  "Number of standings to return (default 50, maximum 100)"
+// @Success      200  {object}  leaderboard.AggregateLeaderboardResponse
+// @Failure      400  {string}  string  "Invalid offset or limit"
+// @Router       /leaderboards/global [get]
+func GlobalLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	offset, limit, err := parsePage(r)
+	if err != nil {
+		http.Error(w, "Invalid offset or limit", http.StatusBadRequest)
+		return
+	}
+	response, err := leaderboard.GetGlobalLeaderboard(offset, limit)
+	writeAggregateLeaderboard(w, response, err)
+}
+
+// LevelLeaderboardHandler godoc
+// @Summary      Get the leaderboard of a level
+// @Description  Get the all-time standings of the competitions played at a level
+// @Param        level   path   int  true   "Player level"
+// @Param        offset  query  int  false  "Number of standings to skip"
+// @Param        limit   query  int  false  "Number of standings to return (default 50, maximum 100)"
+// @Success      200  {object}  leaderboard.AggregateLeaderboardResponse
+// @Failure      400  {string}  string  "Invalid level, offset or limit"
+// @Router       /leaderboards/level/{level} [get]
+func LevelLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	level, err := strconv.Atoi(chi.URLParam(r, "level"))
+	if err != nil {
+		http.Error(w, "Level must be an integer", http.StatusBadRequest)
+		return
+	}
+	offset, limit, err := parsePage(r)
+	if err != nil {
+		http.Error(w, "Invalid offset or limit", http.StatusBadRequest)
+		return
+	}
+	response, err := leaderboard.GetLevelLeaderboard(level, offset, limit)
+	writeAggregateLeaderboard(w, response, err)
+}
+
+// CountryLeaderboardHandler godoc
+// @Summary      Get the leaderboard of a country
+// @Description  Get the all-time standings of the competitions played by the players of a country
+// @Param        countryCode  path   string  true   "ISO 3166-1 alpha-2 country code"
+// @Param        offset       query  int     false  "Number of standings to skip"
+// @Param        limit        query  int     false  "Number of standings to return (default 50, maximum 100)"
+// @Success      200  {object}  leaderboard.AggregateLeaderboardResponse
+// @Failure      400  {string}  string  "Invalid country code, offset or limit"
+// @Router       /leaderboards/country/{countryCode} [get]
+func CountryLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	offset, limit, err := parsePage(r)
+	if err != nil {
+		http.Error(w, "Invalid offset or limit", http.StatusBadRequest)
+		return
+	}
+	response, err := leaderboard.GetCountryLeaderboard(chi.URLParam(r, "countryCode"), offset, limit)
+	writeAggregateLeaderboard(w, response, err)
+}
+
+// parsePage reads the offset and limit query parameters, defaulting to the first page
+func parsePage(r *http.Request) (offset int, limit int, err error) {
+	offset, limit = 0, config.LeaderboardPageSize
+	if value := r.URL.Query().Get("offset"); value != "" {
+		if offset, err = strconv.Atoi(value); err != nil {
+			return 0, 0, errInvalidPageParam
+		}
+	}
+	if value := r.URL.Query().Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil {
+			return 0, 0, errInvalidPageParam
+		}
+	}
+	return offset, limit, nil
+}
+
+func writeAggregateLeaderboard(w http.ResponseWriter, response *leaderboard.AggregateLeaderboardResponse, err error) {
+	if err == leaderboard.ErrInvalidPage {
+		http.Error(w, "Invalid offset or limit", http.StatusBadRequest)
+		return
+	} else if err == model.ErrInvalidLevel || err == model.ErrInvalidCountryCode {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, fmt.Sprintf("Internal server error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"leaderboard/internal/leaderboard"
+	"leaderboard/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var (
+	origGetGlobalLeaderboard  = leaderboard.GetGlobalLeaderboard
+	origGetLevelLeaderboard   = leaderboard.GetLevelLeaderboard
+	origGetCountryLeaderboard = leaderboard.GetCountryLeaderboard
+)
+
+func teardownAggregateLeaderboards() {
+	leaderboard.GetGlobalLeaderboard = origGetGlobalLeaderboard
+	leaderboard.GetLevelLeaderboard = origGetLevelLeaderboard
+	leaderboard.GetCountryLeaderboard = origGetCountryLeaderboard
+}
+
+func aggregateRequest(target, param, value string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	rctx := chi.NewRouteContext()
+	if param != "" {
+		rctx.URLParams.Add(param, value)
+	}
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestGlobalLeaderboardHandler_Success(t *testing.T) {
+	defer teardownAggregateLeaderboards()
+	var gotOffset, gotLimit int
+	leaderboard.GetGlobalLeaderboard = func(offset, limit int) (*leaderboard.AggregateLeaderboardResponse, error) {
+		gotOffset, gotLimit = offset, limit
+		return &leaderboard.AggregateLeaderboardResponse{
+			Board:     "global",
+			Total:     1,
+			Offset:    offset,
+			Limit:     limit,
+			Standings: []leaderboard.Standing{{Rank: 1, PlayerId: "alice", Points: 30, Wins: 1, Podiums: 1, Competitions: 1}},
+		}, nil
+	}
+	rr := httptest.NewRecorder()
+
+	GlobalLeaderboardHandler(rr, aggregateRequest("/leaderboards/global?offset=10&limit=5", "", ""))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if gotOffset != 10 || gotLimit != 5 {
+		t.Errorf("expected offset 10 and limit 5, got %d and %d", gotOffset, gotLimit)
+	}
+	var resp leaderboard.AggregateLeaderboardResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Board != "global" || len(resp.Standings) != 1 || resp.Standings[0].PlayerId != "alice" || resp.Standings[0].Wins != 1 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestGlobalLeaderboardHandler_DefaultPage(t *testing.T) {
+	defer teardownAggregateLeaderboards()
+	var gotOffset, gotLimit int
+	leaderboard.GetGlobalLeaderboard = func(offset, limit int) (*leaderboard.AggregateLeaderboardResponse, error) {
+		gotOffset, gotLimit = offset, limit
+		return &leaderboard.AggregateLeaderboardResponse{}, nil
+	}
+	rr := httptest.NewRecorder()
+
+	GlobalLeaderboardHandler(rr, aggregateRequest("/leaderboards/global", "", ""))
+
+	if gotOffset != 0 || gotLimit != 50 {
+		t.Errorf("expected offset 0 and limit 50, got %d and %d", gotOffset, gotLimit)
+	}
+}
+
+func TestAggregateLeaderboardHandlers_Errors(t *testing.T) {
+	defer teardownAggregateLeaderboards()
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		request        *http.Request
+		err            error
+		expectedStatus int
+	}{
+		{"Global invalid limit", GlobalLeaderboardHandler, aggregateRequest("/leaderboards/global?limit=abc", "", ""), nil, http.StatusBadRequest},
+		{"Global page out of range", GlobalLeaderboardHandler, aggregateRequest("/leaderboards/global?limit=1000", "", ""), leaderboard.ErrInvalidPage, http.StatusBadRequest},
+		{"Global internal error", GlobalLeaderboardHandler, aggregateRequest("/leaderboards/global", "", ""), errors.New("unexpected error"), http.StatusInternalServerError},
+		{"Level not an integer", LevelLeaderboardHandler, aggregateRequest("/leaderboards/level/abc", "level", "abc"), nil, http.StatusBadRequest},
+		{"Level out of range", LevelLeaderboardHandler, aggregateRequest("/leaderboards/level/99", "level", "99"), model.ErrInvalidLevel, http.StatusBadRequest},
+		{"Invalid country code", CountryLeaderboardHandler, aggregateRequest("/leaderboards/country/usa", "countryCode", "usa"), model.ErrInvalidCountryCode, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaderboard.GetGlobalLeaderboard = func(offset, limit int) (*leaderboard.AggregateLeaderboardResponse, error) {
+				return nil, tt.err
+			}
+			leaderboard.GetLevelLeaderboard = func(level, offset, limit int) (*leaderboard.AggregateLeaderboardResponse, error) {
+				return nil, tt.err
+			}
+			leaderboard.GetCountryLeaderboard = func(countryCode string, offset, limit int) (*leaderboard.AggregateLeaderboardResponse, error) {
+				return nil, tt.err
+			}
+			rr := httptest.NewRecorder()
+
+			tt.handler(rr, tt.request)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+		})
+	}
+}
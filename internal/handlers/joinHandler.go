@@ -14,6 +14,7 @@ import (
 // @Success      200  {object}  map[string]interface{}
 // @Accepted     202  {string}  string  "Player queued for matchmaking"
 // @Failure      400  {string}  string  "Player ID is empty or player not found"
+// @Failure      403  {string}  string  "Player is banned from competitions"
 // @Failure      409  {string}  string  "Player already in competition"
 // @Router       /leaderboard/join [post]
 func JoinHandler(w http.ResponseWriter, r *http.Request) {
@@ -34,6 +35,9 @@ func JoinHandler(w http.ResponseWriter, r *http.Request) {
 		} else if err == matchmaking.ErrPlayerAlreadyInCompetition {
 			http.Error(w, "Player already in competition", http.StatusConflict)
 			return
+		} else if err == matchmaking.ErrPlayerBanned {
+			http.Error(w, "Player is banned from competitions", http.StatusForbidden)
+			return
 		}
 		http.Error(w, fmt.Sprintf("Error joining competition: %v", err), http.StatusInternalServerError)
 		return
@@ -65,3 +69,35 @@ func JoinHandler(w http.ResponseWriter, r *http.Request) {
 		"message": "Player queued for matchmaking",
 	})
 }
+
+// CancelJoinHandler godoc
+// @Summary      Leave matchmaking
+// @Description  Remove a waiting player from the matchmaking queue
+// @Param        player_id  query  string  true  "Player ID"
+// @Success      204  {string}  string  "No Content"
+// @Failure      400  {string}  string  "Player ID is empty or player not found"
+// @Failure      409  {string}  string  "Player is not waiting for a match"
+// @Router       /leaderboard/join [delete]
+func CancelJoinHandler(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	if playerID == "" {
+		http.Error(w, "Player ID is required", http.StatusBadRequest)
+		return
+	}
+
+	err := matchmaking.CancelMatchmaking(playerID)
+	if err == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	} else if err == matchmaking.ErrPlayerIdEmpty {
+		http.Error(w, "Player ID cannot be empty", http.StatusBadRequest)
+		return
+	} else if err == matchmaking.ErrPlayerNotFound {
+		http.Error(w, "Player not found", http.StatusBadRequest)
+		return
+	} else if err == matchmaking.ErrPlayerNotWaiting {
+		http.Error(w, "Player is not waiting for a match", http.StatusConflict)
+		return
+	}
+	http.Error(w, fmt.Sprintf("Error leaving matchmaking: %v", err), http.StatusInternalServerError)
+}
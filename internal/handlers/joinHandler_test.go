@@ -15,9 +15,11 @@ import (
 
 // Save original function to restore after tests
 var origJoinCompetition = matchmaking.JoinCompetition
+var origCancelMatchmaking = matchmaking.CancelMatchmaking
 
 func teardown() {
 	matchmaking.JoinCompetition = origJoinCompetition
+	matchmaking.CancelMatchmaking = origCancelMatchmaking
 }
 
 func TestJoinHandler_PlayerIDMissing(t *testing.T) {
@@ -90,6 +92,21 @@ func TestJoinHandler_PlayerAlreadyInCompetitionError(t *testing.T) {
 	}
 }
 
+func TestJoinHandler_PlayerBanned(t *testing.T) {
+	defer teardown()
+	matchmaking.JoinCompetition = func(playerID string) (model.ICompetition, error) {
+		return nil, matchmaking.ErrPlayerBanned
+	}
+	req := httptest.NewRequest(http.MethodPost, "/leaderboard/join?player_id=abc", nil)
+	rr := httptest.NewRecorder()
+
+	JoinHandler(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d", rr.Code)
+	}
+}
+
 func TestJoinHandler_InternalServerError(t *testing.T) {
 	defer teardown()
 	matchmaking.JoinCompetition = func(playerID string) (model.ICompetition, error) {
@@ -187,3 +204,67 @@ func TestJoinHandler_CompetitionNotStarted(t *testing.T) {
 		t.Errorf("unexpected message: %v", resp["message"])
 	}
 }
+
+func TestCancelJoinHandler_PlayerIDMissing(t *testing.T) {
+	defer teardown()
+	req := httptest.NewRequest(http.MethodDelete, "/leaderboard/join", nil)
+	rr := httptest.NewRecorder()
+
+	CancelJoinHandler(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
+
+func TestCancelJoinHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Player not found", matchmaking.ErrPlayerNotFound, http.StatusBadRequest, "Player not found"},
+		{"Player not waiting", matchmaking.ErrPlayerNotWaiting, http.StatusConflict, "Player is not waiting for a match"},
+		{"Internal error", errors.New("unexpected error"), http.StatusInternalServerError, "Error leaving matchmaking"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer teardown()
+			matchmaking.CancelMatchmaking = func(playerID string) error {
+				return tt.err
+			}
+			req := httptest.NewRequest(http.MethodDelete, "/leaderboard/join?player_id=abc", nil)
+			rr := httptest.NewRecorder()
+
+			CancelJoinHandler(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+			if !strings.Contains(rr.Body.String(), tt.expectedBody) {
+				t.Errorf("expected body to contain %q, got %s", tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestCancelJoinHandler_Success(t *testing.T) {
+	defer teardown()
+	var cancelled string
+	matchmaking.CancelMatchmaking = func(playerID string) error {
+		cancelled = playerID
+		return nil
+	}
+	req := httptest.NewRequest(http.MethodDelete, "/leaderboard/join?player_id=abc", nil)
+	rr := httptest.NewRecorder()
+
+	CancelJoinHandler(rr, req)
+
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", rr.Code)
+	}
+	if cancelled != "abc" {
+		t.Errorf("expected abc to be cancelled, got %q", cancelled)
+	}
+}
@@ -3,30 +3,64 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"leaderboard/internal/config"
 	"leaderboard/internal/leaderboard"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 )
 
 // LeaderboardHandler godoc
 // @Summary      Get leaderboard
-// @Description  Get leaderboard by ID
-// @Param        leaderboardID  path  string  true  "Leaderboard ID"
-// @Success      200  {object}  map[string]interface{}
-// @Failure      404  {string}  string  "Not found"
+// @Description  Get a page of a leaderboard by ID, or the players ranked around a player. Ranks follow the configured tie policy
+// @Param        leaderboardID  path   string  true   "Leaderboard ID"
+// @Param        offset         query  int     false  "Number of players to skip"
+// @Param        limit          query  int     false  "Number of players to return (default 50, maximum 100)"
+// @Param        around         query  string  false  "Return the players ranked around this player instead of a page"
+// @Param        radius         query  int     false  "Number of players above and below the around player (default 5, maximum 50)"
+// @Success      200  {object}  leaderboard.LeaderboardResponse
+// @Failure      400  {string}  string  "Invalid offset, limit or radius"
+// @Failure      404  {string}  string  "Leaderboard not found or player not in the leaderboard"
 // @Router       /leaderboard/{leaderboardID} [get]
 func LeaderboardHandler(w http.ResponseWriter, r *http.Request) {
 
 	leaderboardID := chi.URLParam(r, "leaderboardID")
 
-	response, err := leaderboard.GetLeaderboard(leaderboardID)
+	var response *leaderboard.LeaderboardResponse
+	var err error
+	if around := r.URL.Query().Get("around"); around != "" {
+		radius := config.LeaderboardRadius
+		if value := r.URL.Query().Get("radius"); value != "" {
+			if radius, err = strconv.Atoi(value); err != nil {
+				http.Error(w, "Radius must be an integer", http.StatusBadRequest)
+				return
+			}
+		}
+		response, err = leaderboard.GetLeaderboardAround(leaderboardID, around, radius)
+	} else {
+		offset, limit, pageErr := parsePage(r)
+		if pageErr != nil {
+			http.Error(w, "Invalid offset or limit", http.StatusBadRequest)
+			return
+		}
+		response, err = leaderboard.GetLeaderboard(leaderboardID, offset, limit)
+	}
 	if err == leaderboard.ErrCompetetionNotFound {
 		http.Error(w, "Leaderboard not found", http.StatusNotFound)
 		return
 	} else if err == leaderboard.ErrLeaderboardIdEmpty {
 		http.Error(w, "Leaderboard ID cannot be empty", http.StatusBadRequest)
 		return
+	} else if err == leaderboard.ErrInvalidPage {
+		http.Error(w, "Invalid offset or limit", http.StatusBadRequest)
+		return
+	} else if err == leaderboard.ErrInvalidRadius {
+		http.Error(w, "Invalid radius", http.StatusBadRequest)
+		return
+	} else if err == leaderboard.ErrPlayerNotInLeaderboard {
+		http.Error(w, "Player not found in leaderboard", http.StatusNotFound)
+		return
 	} else if err != nil {
 		http.Error(w, fmt.Sprintf("Internal server error: %v", err), http.StatusInternalServerError)
 		return
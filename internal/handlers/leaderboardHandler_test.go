@@ -19,7 +19,7 @@ var mockGetLeaderboard func(string) (*leaderboard.LeaderboardResponse, error)
 
 func setupMock() func() {
 	orig := leaderboard.GetLeaderboard
-	leaderboard.GetLeaderboard = func(id string) (*leaderboard.LeaderboardResponse, error) {
+	leaderboard.GetLeaderboard = func(id string, offset, limit int) (*leaderboard.LeaderboardResponse, error) {
 		return mockGetLeaderboard(id)
 	}
 	return func() { leaderboard.GetLeaderboard = orig }
@@ -142,3 +142,70 @@ func TestLeaderboardHandler_InternalError(t *testing.T) {
 		t.Errorf("expected internal error message, got %s", string(body))
 	}
 }
+
+func TestLeaderboardHandler_Around(t *testing.T) {
+	orig := leaderboard.GetLeaderboardAround
+	defer func() { leaderboard.GetLeaderboardAround = orig }()
+	var gotPlayer string
+	var gotRadius int
+	leaderboard.GetLeaderboardAround = func(id string, playerId string, radius int) (*leaderboard.LeaderboardResponse, error) {
+		gotPlayer, gotRadius = playerId, radius
+		return &leaderboard.LeaderboardResponse{
+			Id:          id,
+			Leaderboard: []leaderboard.PlayerScore{{Rank: 4, PlayerId: "alice", Score: 10}},
+		}, nil
+	}
+
+	req := aggregateRequest("/leaderboard/abc?around=alice&radius=3", "leaderboardID", "abc")
+	rr := httptest.NewRecorder()
+	LeaderboardHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if gotPlayer != "alice" || gotRadius != 3 {
+		t.Errorf("expected alice with radius 3, got %s with radius %d", gotPlayer, gotRadius)
+	}
+	if !bytes.Contains(rr.Body.Bytes(), []byte(`"rank":4`)) {
+		t.Errorf("expected the rank in the response, got %s", rr.Body.String())
+	}
+}
+
+func TestLeaderboardHandler_InvalidArguments(t *testing.T) {
+	origAround := leaderboard.GetLeaderboardAround
+	restore := setupMock()
+	defer func() {
+		restore()
+		leaderboard.GetLeaderboardAround = origAround
+	}()
+
+	tests := []struct {
+		name           string
+		target         string
+		err            error
+		expectedStatus int
+	}{
+		{"Offset not an integer", "/leaderboard/abc?offset=x", nil, http.StatusBadRequest},
+		{"Radius not an integer", "/leaderboard/abc?around=alice&radius=x", nil, http.StatusBadRequest},
+		{"Page out of range", "/leaderboard/abc?limit=1000", leaderboard.ErrInvalidPage, http.StatusBadRequest},
+		{"Radius out of range", "/leaderboard/abc?around=alice&radius=1000", leaderboard.ErrInvalidRadius, http.StatusBadRequest},
+		{"Player not in leaderboard", "/leaderboard/abc?around=bob", leaderboard.ErrPlayerNotInLeaderboard, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGetLeaderboard = func(string) (*leaderboard.LeaderboardResponse, error) {
+				return nil, tt.err
+			}
+			leaderboard.GetLeaderboardAround = func(string, string, int) (*leaderboard.LeaderboardResponse, error) {
+				return nil, tt.err
+			}
+			rr := httptest.NewRecorder()
+
+			LeaderboardHandler(rr, aggregateRequest(tt.target, "leaderboardID", "abc"))
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"leaderboard/internal/live"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/net/websocket"
+)
+
+// LeaderboardSocketHandler godoc
+// @Summary      Follow a leaderboard live
+// @Description  Open a WebSocket that receives JSON events: a snapshot of the leaderboard on connect, then rank_changed,
+// @Description  competition_started and competition_ended events. The connection is closed after competition_ended.
+// @Description  A client that does not keep up with the events is sent a new snapshot instead of the missed events.
+// @Param        leaderboardID  path  string  true  "Leaderboard ID"
+// @Success      101  {object}  live.Event
+// @Failure      404  {string}  string  "Leaderboard not found"
+// @Failure      503  {string}  string  "Too many subscribers for this leaderboard"
+// @Router       /leaderboard/{leaderboardID}/ws [get]
+func LeaderboardSocketHandler(w http.ResponseWriter, r *http.Request) {
+	subscriber, err := live.Subscribe(chi.URLParam(r, "leaderboardID"))
+	if err == live.ErrCompetitionNotFound {
+		http.Error(w, "Leaderboard not found", http.StatusNotFound)
+		return
+	} else if err == live.ErrTooManySubscribers {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	} else if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	served := false
+	websocket.Server{Handler: func(conn *websocket.Conn) {
+		served = true
+		subscriber.Serve(conn)
+	}}.ServeHTTP(w, r)
+	if !served {
+		// The handshake failed
+		subscriber.Unsubscribe()
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"leaderboard/internal/live"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var origSubscribe = live.Subscribe
+
+func socketRequest(leaderboardID string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/leaderboard/"+leaderboardID+"/ws", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("leaderboardID", leaderboardID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestLeaderboardSocketHandler_Errors(t *testing.T) {
+	defer func() { live.Subscribe = origSubscribe }()
+	tests := []struct {
+		err            error
+		expectedStatus int
+	}{
+		{live.ErrCompetitionNotFound, http.StatusNotFound},
+		{live.ErrTooManySubscribers, http.StatusServiceUnavailable},
+		{errors.New("some internal error"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		live.Subscribe = func(leaderboardId string) (*live.Subscriber, error) {
+			return nil, tt.err
+		}
+		w := httptest.NewRecorder()
+		LeaderboardSocketHandler(w, socketRequest("comp1"))
+		if w.Code != tt.expectedStatus {
+			t.Errorf("for error %v expected status %d, got %d", tt.err, tt.expectedStatus, w.Code)
+		}
+	}
+}
+
+func TestLeaderboardSocketHandler_NotWebSocket(t *testing.T) {
+	defer func() { live.Subscribe = origSubscribe }()
+	live.Subscribe = func(leaderboardId string) (*live.Subscriber, error) {
+		return &live.Subscriber{}, nil
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(LeaderboardSocketHandler))
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() returned error %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 without a WebSocket handshake, got %d", resp.StatusCode)
+	}
+}
@@ -18,15 +18,35 @@ func (m *mockCompetition) Id() string           { return m.id }
 func (m *mockCompetition) StartedAt() time.Time { return m.startedAt }
 func (m *mockCompetition) EndsAt() time.Time    { return m.endsAt }
 func (m *mockCompetition) InitialLevel() int    { return 0 }
+func (m *mockCompetition) TieBreaker() string   { return model.TieBreakerPlayerId }
+func (m *mockCompetition) SetTieBreaker(tieBreaker string) error {
+	return nil // Not needed for these tests
+}
+func (m *mockCompetition) ScoringMode() string { return model.ScoringIncrement }
+func (m *mockCompetition) SetScoringMode(mode string) error {
+	return nil // Not needed for these tests
+}
 func (m *mockCompetition) PlayersMap() map[string]*model.CompetingPlayer {
 	return nil // Not needed for these tests
 }
 func (m *mockCompetition) AddPlayer(player *model.Player) error {
 	return nil // Not needed for these tests
 }
+func (m *mockCompetition) RemovePlayer(playerId string) error {
+	return nil // Not needed for these tests
+}
 func (m *mockCompetition) Leaderboard() []*model.CompetingPlayer {
 	return nil // Not needed for these tests
 }
+func (m *mockCompetition) Standings(offset, count int) []model.RankedPlayer {
+	return nil // Not needed for these tests
+}
+func (m *mockCompetition) RankedCount() int {
+	return 0 // Not needed for these tests
+}
+func (m *mockCompetition) Position(playerId string) (int, bool) {
+	return 0, false // Not needed for these tests
+}
 func (m *mockCompetition) Start() error {
 	m.startedAt = timeprovider.Current.Now()
 	m.endsAt = m.startedAt.Add(config.CompetitionDuration)
@@ -35,3 +55,39 @@ func (m *mockCompetition) Start() error {
 func (m *mockCompetition) AddScore(playerId string, points int) error {
 	return nil
 }
+func (m *mockCompetition) SubmitScore(playerId string, points int, submissionId string) (bool, error) {
+	return true, nil
+}
+func (m *mockCompetition) SubmitScores(submissions []model.ScoreSubmission) []model.SubmissionResult {
+	return nil // Not needed for these tests
+}
+func (m *mockCompetition) History(playerId string) ([]model.ScoreChange, bool) {
+	return nil, false // Not needed for these tests
+}
+func (m *mockCompetition) AppliedSubmissions() []model.AppliedSubmission {
+	return nil // Not needed for these tests
+}
+func (m *mockCompetition) VoidSubmission(playerId, submissionId, reason string) (model.ScoreChange, error) {
+	return model.ScoreChange{}, nil // Not needed for these tests
+}
+func (m *mockCompetition) VoidChange(playerId string, seq int, reason string) (model.ScoreChange, error) {
+	return model.ScoreChange{}, nil // Not needed for these tests
+}
+func (m *mockCompetition) AdjustScore(playerId string, points int, reason string) (model.ScoreChange, error) {
+	return model.ScoreChange{}, nil // Not needed for these tests
+}
+func (m *mockCompetition) Disqualify(playerId string) error {
+	return nil // Not needed for these tests
+}
+func (m *mockCompetition) End() error {
+	return nil // Not needed for these tests
+}
+func (m *mockCompetition) Ended() bool {
+	return false // Not needed for these tests
+}
+func (m *mockCompetition) State() model.CompetitionState {
+	return model.CompetitionState{StartedAt: m.startedAt, EndsAt: m.endsAt}
+}
+func (m *mockCompetition) Submitted(playerId, submissionId string) bool {
+	return false // Not needed for these tests
+}
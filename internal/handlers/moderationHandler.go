@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"leaderboard/internal/config"
+	"leaderboard/internal/leaderboard"
+	"leaderboard/internal/model"
+	"leaderboard/internal/moderation"
+	"leaderboard/internal/storage"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// moderationRequest is the body of the moderation endpoints, each using some of the fields
+type moderationRequest struct {
+	SubmissionId string `json:"submission_id"`
+	Seq          int    `json:"seq"`
+	Points       int    `json:"points"`
+	Reason       string `json:"reason"`
+}
+
+// AdminAuth requires the admin endpoints to be called with the config.AdminToken bearer token.
+// The endpoints are unavailable when no token is set.
+func AdminAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if config.AdminToken == "" {
+			http.Error(w, "Admin endpoints are disabled", http.StatusServiceUnavailable)
+			return
+		}
+		expected := "Bearer " + config.AdminToken
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// FlaggedSubmissionsHandler godoc
+// @Summary      List flagged score submissions
+// @Description  List the score submissions rejected by the anti-cheat validators, oldest first, for review
+// @Produce      json
+// @Success      200  {array}  leaderboard.FlaggedSubmission
+// @Router       /admin/flagged [get]
+func FlaggedSubmissionsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(leaderboard.GetFlaggedSubmissions())
+}
+
+// AuditLogHandler godoc
+// @Summary      Get the audit log
+// @Description  List the moderations of the scores and players, oldest first
+// @Produce      json
+// @Param        player_id  query  string  false  "Only list the moderations of this player"
+// @Success      200  {array}  storage.AuditRecord
+// @Router       /admin/audit [get]
+func AuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(moderation.GetAuditLog(r.URL.Query().Get("player_id")))
+}
+
+// VoidSubmissionHandler godoc
+// @Summary      Void a score submission
+// @Description  Cancel a submission applied for a player and recompute their score without it.
+// @Description  The submission is identified by its submission_id, or by its seq in the score history of the player,
+// @Description  which submissions sent without an ID can only be voided by. It stays in the history marked as voided.
+// @Accept       json
+// @Produce      json
+// @Param        leaderboardID  path  string  true  "Leaderboard ID"
+// @Param        playerID       path  string  true  "Player ID"
+// @Param        void           body  map[string]interface{}  true  "submission_id or seq, and reason"
+// @Success      200  {object}  storage.AuditRecord
+// @Failure      400  {string}  string  "Invalid request body, neither or both of submission ID and seq, or empty reason"
+// @Failure      404  {string}  string  "Leaderboard, player or submission not found"
+// @Failure      409  {string}  string  "Competition has not started or has ended"
+// @Router       /admin/leaderboard/{leaderboardID}/players/{playerID}/void [post]
+func VoidSubmissionHandler(w http.ResponseWriter, r *http.Request) {
+	var req moderationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	record, err := moderation.VoidSubmission(chi.URLParam(r, "leaderboardID"), chi.URLParam(r, "playerID"), req.SubmissionId, req.Seq, req.Reason)
+	writeModeration(w, record, err)
+}
+
+// AdjustScoreHandler godoc
+// @Summary      Adjust a score
+// @Description  Add points to the score of a player, or remove them with negative points, whatever the scoring mode
+// @Accept       json
+// @Produce      json
+// @Param        leaderboardID  path  string  true  "Leaderboard ID"
+// @Param        playerID       path  string  true  "Player ID"
+// @Param        adjustment     body  map[string]interface{}  true  "points and reason"
+// @Success      200  {object}  storage.AuditRecord
+// @Failure      400  {string}  string  "Invalid request body, zero points or empty reason"
+// @Failure      404  {string}  string  "Leaderboard or player not found"
+// @Failure      409  {string}  string  "Competition has not started or has ended"
+// @Router       /admin/leaderboard/{leaderboardID}/players/{playerID}/adjust [post]
+func AdjustScoreHandler(w http.ResponseWriter, r *http.Request) {
+	var req moderationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	record, err := moderation.AdjustScore(chi.URLParam(r, "leaderboardID"), chi.URLParam(r, "playerID"), req.Points, req.Reason)
+	writeModeration(w, record, err)
+}
+
+// DisqualifyPlayerHandler godoc
+// @Summary      Disqualify a player
+// @Description  Remove a player from the ranking of a competition. The player keeps their score and history
+// @Description  but cannot submit scores to the competition anymore.
+// @Accept       json
+// @Produce      json
+// @Param        leaderboardID  path  string  true  "Leaderboard ID"
+// @Param        playerID       path  string  true  "Player ID"
+// @Param        disqualification  body  map[string]interface{}  true  "reason"
+// @Success      200  {object}  storage.AuditRecord
+// @Failure      400  {string}  string  "Invalid request body or empty reason"
+// @Failure      404  {string}  string  "Leaderboard or player not found"
+// @Failure      409  {string}  string  "Competition has not started or has ended, or player already disqualified"
+// @Router       /admin/leaderboard/{leaderboardID}/players/{playerID}/disqualify [post]
+func DisqualifyPlayerHandler(w http.ResponseWriter, r *http.Request) {
+	var req moderationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	record, err := moderation.DisqualifyPlayer(chi.URLParam(r, "leaderboardID"), chi.URLParam(r, "playerID"), req.Reason)
+	writeModeration(w, record, err)
+}
+
+// BanPlayerHandler godoc
+// @Summary      Ban a player
+// @Description  Prevent a player from joining competitions. A player already in a competition stays in it.
+// @Accept       json
+// @Produce      json
+// @Param        playerID  path  string  true  "Player ID"
+// @Param        ban       body  map[string]interface{}  true  "reason"
+// @Success      200  {object}  storage.AuditRecord
+// @Failure      400  {string}  string  "Invalid request body or empty reason"
+// @Failure      404  {string}  string  "Player not found"
+// @Failure      409  {string}  string  "Player already banned"
+// @Router       /admin/players/{playerID}/ban [post]
+func BanPlayerHandler(w http.ResponseWriter, r *http.Request) {
+	var req moderationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	record, err := moderation.BanPlayer(chi.URLParam(r, "playerID"), req.Reason)
+	writeModeration(w, record, err)
+}
+
+// UnbanPlayerHandler godoc
+// @Summary      Unban a player
+// @Description  Allow a banned player to join competitions again
+// @Accept       json
+// @Produce      json
+// @Param        playerID  path  string  true  "Player ID"
+// @Param        unban     body  map[string]interface{}  true  "reason"
+// @Success      200  {object}  storage.AuditRecord
+// @Failure      400  {string}  string  "Invalid request body or empty reason"
+// @Failure      404  {string}  string  "Player not found"
+// @Failure      409  {string}  string  "Player not banned"
+// @Router       /admin/players/{playerID}/unban [post]
+func UnbanPlayerHandler(w http.ResponseWriter, r *http.Request) {
+	var req moderationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	record, err := moderation.UnbanPlayer(chi.URLParam(r, "playerID"), req.Reason)
+	writeModeration(w, record, err)
+}
+
+// writeModeration writes the audit record of a moderation, or the HTTP status and message of its error
+func writeModeration(w http.ResponseWriter, record *storage.AuditRecord, err error) {
+	if err == moderation.ErrCompetitionNotFound || err == moderation.ErrPlayerNotFound ||
+		err == model.ErrPlayerNotFound || err == model.ErrSubmissionNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err == model.ErrCompetitionNotStarted || err == model.ErrCompetitionEnded || err == model.ErrPlayerDisqualified ||
+		err == moderation.ErrPlayerBanned || err == moderation.ErrPlayerNotBanned {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	} else if err == moderation.ErrLeaderboardIdEmpty || err == moderation.ErrPlayerIdEmpty ||
+		err == moderation.ErrSubmissionIdEmpty || err == moderation.ErrSubmissionAmbiguous || err == moderation.ErrReasonEmpty || err == moderation.ErrPointsZero {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(record)
+}
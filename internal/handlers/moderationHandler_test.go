@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"leaderboard/internal/config"
+	"leaderboard/internal/model"
+	"leaderboard/internal/moderation"
+	"leaderboard/internal/storage"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var origAdjustScore = moderation.AdjustScore
+
+func adjustRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/admin/leaderboard/comp1/players/alice/adjust", bytes.NewReader([]byte(body)))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("leaderboardID", "comp1")
+	rctx.URLParams.Add("playerID", "alice")
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestAdjustScoreHandler_Success(t *testing.T) {
+	defer func() { moderation.AdjustScore = origAdjustScore }()
+	moderation.AdjustScore = func(leaderboardId, playerId string, points int, reason string) (*storage.AuditRecord, error) {
+		return &storage.AuditRecord{Action: moderation.ActionAdjust, LeaderboardId: leaderboardId, PlayerId: playerId, Points: points, Reason: reason}, nil
+	}
+
+	w := httptest.NewRecorder()
+	AdjustScoreHandler(w, adjustRequest(`{"points":-20,"reason":"exploit"}`))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var record storage.AuditRecord
+	if err := json.NewDecoder(w.Body).Decode(&record); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if record.LeaderboardId != "comp1" || record.PlayerId != "alice" || record.Points != -20 || record.Reason != "exploit" {
+		t.Errorf("unexpected audit record %+v", record)
+	}
+}
+
+func TestAdjustScoreHandler_Errors(t *testing.T) {
+	defer func() { moderation.AdjustScore = origAdjustScore }()
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"CompetitionNotFound", moderation.ErrCompetitionNotFound, http.StatusNotFound},
+		{"PlayerNotInCompetition", model.ErrPlayerNotFound, http.StatusNotFound},
+		{"CompetitionNotStarted", model.ErrCompetitionNotStarted, http.StatusConflict},
+		{"CompetitionEnded", model.ErrCompetitionEnded, http.StatusConflict},
+		{"ReasonEmpty", moderation.ErrReasonEmpty, http.StatusBadRequest},
+		{"PointsZero", moderation.ErrPointsZero, http.StatusBadRequest},
+		{"InternalServerError", errors.New("disk full"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moderation.AdjustScore = func(string, string, int, string) (*storage.AuditRecord, error) {
+				return nil, tt.err
+			}
+			w := httptest.NewRecorder()
+			AdjustScoreHandler(w, adjustRequest(`{"points":5,"reason":"bonus"}`))
+			if w.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
+
+func TestAdminAuth(t *testing.T) {
+	defer func() { config.AdminToken = "" }()
+	handler := AdminAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		expected      int
+	}{
+		{"NoTokenConfigured", "", "", http.StatusServiceUnavailable},
+		{"EmptyBearerWithoutToken", "", "Bearer ", http.StatusServiceUnavailable},
+		{"MissingToken", "secret", "", http.StatusUnauthorized},
+		{"WrongToken", "secret", "Bearer guess", http.StatusUnauthorized},
+		{"ValidToken", "secret", "Bearer secret", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.AdminToken = tt.token
+			req := httptest.NewRequest(http.MethodGet, "/admin/audit", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
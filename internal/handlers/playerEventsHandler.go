@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"leaderboard/internal/config"
+	"leaderboard/internal/notifications"
+	"leaderboard/internal/players"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// PlayerEventsHandler godoc
+// @Summary      Stream the events of a player
+// @Description  Open a Server-Sent Events stream of the matchmaking and competition events of a player:
+// @Description  queued, matched, competition_started, rank_changed and competition_ended. The data of each event is a JSON notifications.Notification.
+// @Description  Events published while the stream is closed are not replayed.
+// @Produce      text/event-stream
+// @Param        playerID  path  string  true  "Player ID"
+// @Success      200  {object}  notifications.Notification
+// @Failure      404  {string}  string  "Player not found"
+// @Failure      429  {string}  string  "Too many event streams for this player"
+// @Router       /players/{playerID}/events [get]
+func PlayerEventsHandler(w http.ResponseWriter, r *http.Request) {
+	playerID := chi.URLParam(r, "playerID")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	if _, err := players.GetPlayer(playerID); err == players.ErrPlayerNotFound {
+		http.Error(w, "Player not found", http.StatusNotFound)
+		return
+	} else if err == players.ErrPlayerIdEmpty {
+		http.Error(w, "Player ID cannot be empty", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	subscription, err := notifications.Subscribe(playerID)
+	if err == notifications.ErrTooManySubscriptions {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	} else if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer subscription.Unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(config.EventStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case notification := <-subscription.Notifications():
+			data, err := json.Marshal(notification)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", notification.Type, data); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
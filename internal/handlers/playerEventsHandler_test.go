@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"leaderboard/internal/notifications"
+	"leaderboard/internal/players"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var origSubscribeNotifications = notifications.Subscribe
+
+func eventsServer(t *testing.T) *httptest.Server {
+	r := chi.NewRouter()
+	r.Get("/players/{playerID}/events", PlayerEventsHandler)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPlayerEventsHandler_StreamsEvents(t *testing.T) {
+	defer func() { players.GetPlayer = origGetPlayer }()
+	players.GetPlayer = func(id string) (*players.PlayerResponse, error) {
+		return &players.PlayerResponse{Id: id}, nil
+	}
+
+	resp, err := http.Get(eventsServer(t).URL + "/players/alice/events")
+	if err != nil {
+		t.Fatalf("Get() returned error %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected an event stream, got status %d and type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	notifications.Publish(notifications.Notification{Type: notifications.Matched, PlayerId: "alice", LeaderboardId: "comp1", At: time.Now()})
+	reader := bufio.NewReader(resp.Body)
+	if line, _ := reader.ReadString('\n'); line != "event: matched\n" {
+		t.Fatalf("expected the matched event, got %q", line)
+	}
+	line, _ := reader.ReadString('\n')
+	var notification notifications.Notification
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &notification); err != nil {
+		t.Fatalf("failed to decode notification %q: %v", line, err)
+	}
+	if notification.PlayerId != "alice" || notification.LeaderboardId != "comp1" {
+		t.Errorf("unexpected notification %+v", notification)
+	}
+}
+
+func TestPlayerEventsHandler_Errors(t *testing.T) {
+	defer func() {
+		players.GetPlayer = origGetPlayer
+		notifications.Subscribe = origSubscribeNotifications
+	}()
+	tests := []struct {
+		name           string
+		playerErr      error
+		subscribeErr   error
+		expectedStatus int
+	}{
+		{"PlayerNotFound", players.ErrPlayerNotFound, nil, http.StatusNotFound},
+		{"PlayerError", errors.New("some internal error"), nil, http.StatusInternalServerError},
+		{"TooManyStreams", nil, notifications.ErrTooManySubscriptions, http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			players.GetPlayer = func(id string) (*players.PlayerResponse, error) {
+				return &players.PlayerResponse{Id: id}, tt.playerErr
+			}
+			notifications.Subscribe = func(playerId string) (*notifications.Subscription, error) {
+				return nil, tt.subscribeErr
+			}
+			resp, err := http.Get(eventsServer(t).URL + "/players/alice/events")
+			if err != nil {
+				t.Fatalf("Get() returned error %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+		})
+	}
+}
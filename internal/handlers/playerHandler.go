@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"leaderboard/internal/players"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// CreatePlayerHandler godoc
+// @Summary      Register player
+// @Description  Register a new player
+// @Accept       json
+// @Param        player  body  map[string]interface{}  true  "Player with id, level and country_code"
+// @Success      201  {object}  players.PlayerResponse
+// @Failure      400  {string}  string  "Invalid player ID, level or country code"
+// @Failure      409  {string}  string  "Player already exists"
+// @Router       /players [post]
+func CreatePlayerHandler(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		Id          string `json:"id"`
+		Level       int    `json:"level"`
+		CountryCode string `json:"country_code"`
+	}
+
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response, err := players.RegisterPlayer(req.Id, req.Level, req.CountryCode)
+	if err == players.ErrPlayerExists {
+		http.Error(w, "Player already exists", http.StatusConflict)
+		return
+	} else if err != nil {
+		writePlayerValidationError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetPlayerHandler godoc
+// @Summary      Get player
+// @Description  Get a player profile by ID
+// @Param        playerID  path  string  true  "Player ID"
+// @Success      200  {object}  players.PlayerResponse
+// @Failure      404  {string}  string  "Player not found"
+// @Router       /players/{playerID} [get]
+func GetPlayerHandler(w http.ResponseWriter, r *http.Request) {
+	playerID := chi.URLParam(r, "playerID")
+
+	response, err := players.GetPlayer(playerID)
+	if err == players.ErrPlayerNotFound {
+		http.Error(w, "Player not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		writePlayerValidationError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdatePlayerHandler godoc
+// @Summary      Update player
+// @Description  Update the level and/or country code of a player. The new level is used the next time the player joins a competition
+// @Accept       json
+// @Param        playerID  path  string  true  "Player ID"
+// @Param        player  body  map[string]interface{}  true  "Fields to update: level, country_code"
+// @Success      200  {object}  players.PlayerResponse
+// @Failure      400  {string}  string  "Invalid level or country code"
+// @Failure      404  {string}  string  "Player not found"
+// @Failure      409  {string}  string  "Player is waiting for a match"
+// @Router       /players/{playerID} [patch]
+func UpdatePlayerHandler(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		Level       *int    `json:"level"`
+		CountryCode *string `json:"country_code"`
+	}
+
+	playerID := chi.URLParam(r, "playerID")
+
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response, err := players.UpdatePlayer(playerID, req.Level, req.CountryCode)
+	if err == players.ErrPlayerNotFound {
+		http.Error(w, "Player not found", http.StatusNotFound)
+		return
+	} else if err == players.ErrPlayerWaiting {
+		http.Error(w, "Player is waiting for a match, cannot update player", http.StatusConflict)
+		return
+	} else if err != nil {
+		writePlayerValidationError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeletePlayerHandler godoc
+// @Summary      Delete player
+// @Description  Delete a player. A waiting player is removed from matchmaking, a player in a started competition keeps their leaderboard entry
+// @Param        playerID  path  string  true  "Player ID"
+// @Success      204  {string}  string  "No Content"
+// @Failure      404  {string}  string  "Player not found"
+// @Router       /players/{playerID} [delete]
+func DeletePlayerHandler(w http.ResponseWriter, r *http.Request) {
+	playerID := chi.URLParam(r, "playerID")
+
+	err := players.DeletePlayer(playerID)
+	if err == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	} else if err == players.ErrPlayerNotFound {
+		http.Error(w, "Player not found", http.StatusNotFound)
+		return
+	}
+	writePlayerValidationError(w, err)
+}
+
+func writePlayerValidationError(w http.ResponseWriter, err error) {
+	if err == players.ErrPlayerIdEmpty {
+		http.Error(w, "Player ID cannot be empty", http.StatusBadRequest)
+	} else if err == players.ErrInvalidLevel || err == players.ErrInvalidCountryCode {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	} else {
+		http.Error(w, fmt.Sprintf("Internal server error: %v", err), http.StatusInternalServerError)
+	}
+}
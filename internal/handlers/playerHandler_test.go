@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"leaderboard/internal/players"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var (
+	origRegisterPlayer = players.RegisterPlayer
+	origGetPlayer      = players.GetPlayer
+	origUpdatePlayer   = players.UpdatePlayer
+	origDeletePlayer   = players.DeletePlayer
+)
+
+func teardownPlayers() {
+	players.RegisterPlayer = origRegisterPlayer
+	players.GetPlayer = origGetPlayer
+	players.UpdatePlayer = origUpdatePlayer
+	players.DeletePlayer = origDeletePlayer
+}
+
+func playerRequest(method, playerID string, body []byte) *http.Request {
+	req := httptest.NewRequest(method, "/players/"+playerID, bytes.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("playerID", playerID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestCreatePlayerHandler_Success(t *testing.T) {
+	defer teardownPlayers()
+	players.RegisterPlayer = func(id string, level int, countryCode string) (*players.PlayerResponse, error) {
+		return &players.PlayerResponse{Id: id, Level: level, CountryCode: countryCode}, nil
+	}
+	body := []byte(`{"id":"alice","level":3,"country_code":"US"}`)
+	req := httptest.NewRequest(http.MethodPost, "/players", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	CreatePlayerHandler(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rr.Code)
+	}
+	var resp players.PlayerResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Id != "alice" || resp.Level != 3 || resp.CountryCode != "US" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestCreatePlayerHandler_ErrorCases_TableDriven(t *testing.T) {
+	defer teardownPlayers()
+
+	tests := []struct {
+		name           string
+		body           string
+		errorToReturn  error
+		expectedStatus int
+	}{
+		{"InvalidJSON", "{invalid", nil, http.StatusBadRequest},
+		{"PlayerIdEmpty", `{"level":1,"country_code":"US"}`, players.ErrPlayerIdEmpty, http.StatusBadRequest},
+		{"InvalidLevel", `{"id":"a","level":99,"country_code":"US"}`, players.ErrInvalidLevel, http.StatusBadRequest},
+		{"InvalidCountryCode", `{"id":"a","level":1,"country_code":"usa"}`, players.ErrInvalidCountryCode, http.StatusBadRequest},
+		{"PlayerExists", `{"id":"a","level":1,"country_code":"US"}`, players.ErrPlayerExists, http.StatusConflict},
+		{"InternalServerError", `{"id":"a","level":1,"country_code":"US"}`, errors.New("disk full"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			players.RegisterPlayer = func(_ string, _ int, _ string) (*players.PlayerResponse, error) {
+				return nil, tt.errorToReturn
+			}
+			req := httptest.NewRequest(http.MethodPost, "/players", bytes.NewReader([]byte(tt.body)))
+			rr := httptest.NewRecorder()
+
+			CreatePlayerHandler(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+		})
+	}
+}
+
+func TestGetPlayerHandler(t *testing.T) {
+	defer teardownPlayers()
+	players.GetPlayer = func(id string) (*players.PlayerResponse, error) {
+		if id == "alice" {
+			return &players.PlayerResponse{Id: "alice", Level: 3, CountryCode: "US", CompetitionId: "comp123"}, nil
+		}
+		return nil, players.ErrPlayerNotFound
+	}
+
+	rr := httptest.NewRecorder()
+	GetPlayerHandler(rr, playerRequest(http.MethodGet, "alice", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if !bytes.Contains(rr.Body.Bytes(), []byte(`"competition_id":"comp123"`)) {
+		t.Errorf("expected competition id in response, got %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	GetPlayerHandler(rr, playerRequest(http.MethodGet, "unknown", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
+	}
+}
+
+func TestUpdatePlayerHandler_PassesOnlyProvidedFields(t *testing.T) {
+	defer teardownPlayers()
+	var gotLevel *int
+	var gotCountryCode *string
+	players.UpdatePlayer = func(id string, level *int, countryCode *string) (*players.PlayerResponse, error) {
+		gotLevel, gotCountryCode = level, countryCode
+		return &players.PlayerResponse{Id: id, Level: *level, CountryCode: "US"}, nil
+	}
+
+	rr := httptest.NewRecorder()
+	UpdatePlayerHandler(rr, playerRequest(http.MethodPatch, "alice", []byte(`{"level":5}`)))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if gotLevel == nil || *gotLevel != 5 {
+		t.Errorf("expected level 5, got %v", gotLevel)
+	}
+	if gotCountryCode != nil {
+		t.Errorf("expected country code to be left unchanged, got %v", *gotCountryCode)
+	}
+}
+
+func TestUpdatePlayerHandler_ErrorCases_TableDriven(t *testing.T) {
+	defer teardownPlayers()
+
+	tests := []struct {
+		name           string
+		errorToReturn  error
+		expectedStatus int
+	}{
+		{"PlayerNotFound", players.ErrPlayerNotFound, http.StatusNotFound},
+		{"PlayerWaiting", players.ErrPlayerWaiting, http.StatusConflict},
+		{"InvalidLevel", players.ErrInvalidLevel, http.StatusBadRequest},
+		{"InvalidCountryCode", players.ErrInvalidCountryCode, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			players.UpdatePlayer = func(_ string, _ *int, _ *string) (*players.PlayerResponse, error) {
+				return nil, tt.errorToReturn
+			}
+			rr := httptest.NewRecorder()
+
+			UpdatePlayerHandler(rr, playerRequest(http.MethodPatch, "alice", []byte(`{"level":5}`)))
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+		})
+	}
+}
+
+func TestDeletePlayerHandler(t *testing.T) {
+	defer teardownPlayers()
+	players.DeletePlayer = func(id string) error {
+		if id == "alice" {
+			return nil
+		}
+		return players.ErrPlayerNotFound
+	}
+
+	rr := httptest.NewRecorder()
+	DeletePlayerHandler(rr, playerRequest(http.MethodDelete, "alice", nil))
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	DeletePlayerHandler(rr, playerRequest(http.MethodDelete, "unknown", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"leaderboard/internal/matchmaking"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// QueueStatusHandler godoc
+// @Summary      Get matchmaking queue status
+// @Description  Get the queue state of a player, the time waited, the size of the waiting competition, the levels searched and the estimated time to start
+// @Param        playerID  path  string  true  "Player ID"
+// @Success      200  {object}  matchmaking.QueueStatus
+// @Failure      400  {string}  string  "Player ID is empty or player not found"
+// @Failure      404  {string}  string  "Player has not joined matchmaking"
+// @Router       /leaderboard/queue/{playerID} [get]
+func QueueStatusHandler(w http.ResponseWriter, r *http.Request) {
+	playerID := chi.URLParam(r, "playerID")
+
+	status, err := matchmaking.GetQueueStatus(playerID)
+	if err == matchmaking.ErrPlayerIdEmpty {
+		http.Error(w, "Player ID cannot be empty", http.StatusBadRequest)
+		return
+	} else if err == matchmaking.ErrPlayerNotFound {
+		http.Error(w, "Player not found", http.StatusBadRequest)
+		return
+	} else if err == matchmaking.ErrPlayerNotQueued {
+		http.Error(w, "Player has not joined matchmaking", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, fmt.Sprintf("Internal server error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
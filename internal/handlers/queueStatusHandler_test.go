@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"leaderboard/internal/matchmaking"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var origGetQueueStatus = matchmaking.GetQueueStatus
+
+func queueStatusRequest(playerID string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/leaderboard/queue/"+playerID, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("playerID", playerID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestQueueStatusHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Player ID empty", matchmaking.ErrPlayerIdEmpty, http.StatusBadRequest, "Player ID cannot be empty"},
+		{"Player not found", matchmaking.ErrPlayerNotFound, http.StatusBadRequest, "Player not found"},
+		{"Player not queued", matchmaking.ErrPlayerNotQueued, http.StatusNotFound, "Player has not joined matchmaking"},
+		{"Internal error", errors.New("unexpected error"), http.StatusInternalServerError, "Internal server error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matchmaking.GetQueueStatus = func(playerID string) (*matchmaking.QueueStatus, error) {
+				return nil, tt.err
+			}
+			defer func() { matchmaking.GetQueueStatus = origGetQueueStatus }()
+			rr := httptest.NewRecorder()
+
+			QueueStatusHandler(rr, queueStatusRequest("abc"))
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+			if !strings.Contains(rr.Body.String(), tt.expectedBody) {
+				t.Errorf("expected body to contain %q, got %s", tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestQueueStatusHandler_Success(t *testing.T) {
+	matchmaking.GetQueueStatus = func(playerID string) (*matchmaking.QueueStatus, error) {
+		return &matchmaking.QueueStatus{
+			PlayerId:              playerID,
+			State:                 matchmaking.QueueStateWaiting,
+			WaitedSeconds:         12,
+			CompetitionId:         "comp1",
+			LobbySize:             3,
+			MinLevel:              2,
+			MaxLevel:              4,
+			EstimatedStartSeconds: 18,
+		}, nil
+	}
+	defer func() { matchmaking.GetQueueStatus = origGetQueueStatus }()
+	rr := httptest.NewRecorder()
+
+	QueueStatusHandler(rr, queueStatusRequest("abc"))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp["player_id"] != "abc" || resp["state"] != "waiting" || resp["lobby_size"] != float64(3) ||
+		resp["min_level"] != float64(2) || resp["max_level"] != float64(4) || resp["estimated_start_seconds"] != float64(18) {
+		t.Errorf("unexpected response: %v", resp)
+	}
+}
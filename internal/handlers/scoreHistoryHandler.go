@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"leaderboard/internal/leaderboard"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ScoreHistoryHandler godoc
+// @Summary      Get the score history of a player
+// @Description  Get the accepted score submissions of a player in a competition, oldest first, with the points submitted,
+// @Description  the change of the score, the resulting score and where the submission comes from
+// @Param        leaderboardID  path  string  true  "Leaderboard ID"
+// @Param        playerID       path  string  true  "Player ID"
+// @Success      200  {object}  leaderboard.ScoreHistoryResponse
+// @Failure      404  {string}  string  "Leaderboard not found or player not in the leaderboard"
+// @Router       /leaderboard/{leaderboardID}/players/{playerID}/history [get]
+func ScoreHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	response, err := leaderboard.GetScoreHistory(chi.URLParam(r, "leaderboardID"), chi.URLParam(r, "playerID"))
+	if err == leaderboard.ErrCompetetionNotFound {
+		http.Error(w, "Leaderboard not found", http.StatusNotFound)
+		return
+	} else if err == leaderboard.ErrPlayerNotInLeaderboard {
+		http.Error(w, "Player not found in leaderboard", http.StatusNotFound)
+		return
+	} else if err == leaderboard.ErrLeaderboardIdEmpty || err == leaderboard.ErrPlayerIdEmpty {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, fmt.Sprintf("Internal server error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"leaderboard/internal/leaderboard"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var origGetScoreHistory = leaderboard.GetScoreHistory
+
+func historyRequest(leaderboardID, playerID string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/leaderboard/"+leaderboardID+"/players/"+playerID+"/history", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("leaderboardID", leaderboardID)
+	rctx.URLParams.Add("playerID", playerID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestScoreHistoryHandler_Success(t *testing.T) {
+	defer func() { leaderboard.GetScoreHistory = origGetScoreHistory }()
+	leaderboard.GetScoreHistory = func(leaderboardId, playerId string) (*leaderboard.ScoreHistoryResponse, error) {
+		return &leaderboard.ScoreHistoryResponse{
+			LeaderboardId: leaderboardId,
+			PlayerId:      playerId,
+			Score:         10,
+			History:       []leaderboard.ScoreChangeResponse{{Points: 10, Delta: 10, Score: 10, Source: "api"}},
+		}, nil
+	}
+
+	w := httptest.NewRecorder()
+	ScoreHistoryHandler(w, historyRequest("comp1", "alice"))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var response leaderboard.ScoreHistoryResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.LeaderboardId != "comp1" || response.PlayerId != "alice" || len(response.History) != 1 {
+		t.Errorf("unexpected response %+v", response)
+	}
+}
+
+func TestScoreHistoryHandler_Errors(t *testing.T) {
+	defer func() { leaderboard.GetScoreHistory = origGetScoreHistory }()
+	tests := []struct {
+		err            error
+		expectedStatus int
+	}{
+		{leaderboard.ErrCompetetionNotFound, http.StatusNotFound},
+		{leaderboard.ErrPlayerNotInLeaderboard, http.StatusNotFound},
+		{leaderboard.ErrPlayerIdEmpty, http.StatusBadRequest},
+		{errors.New("some internal error"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		leaderboard.GetScoreHistory = func(_, _ string) (*leaderboard.ScoreHistoryResponse, error) {
+			return nil, tt.err
+		}
+		w := httptest.NewRecorder()
+		ScoreHistoryHandler(w, historyRequest("comp1", "alice"))
+		if w.Code != tt.expectedStatus {
+			t.Errorf("expected status %d for %v, got %d", tt.expectedStatus, tt.err, w.Code)
+		}
+	}
+}
@@ -2,23 +2,35 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"leaderboard/internal/leaderboard"
+	"leaderboard/internal/model"
 )
 
 // SubmitScoreHandler godoc
 // @Summary      Submit score
-// @Description  Add score to the player's current competition
+// @Description  Submit a score to the player's current competition. The score is added, kept if it is the best,
+// @Description  replaces the previous one or is capped per time window depending on the scoring mode of the competition.
+// @Description  Negative scores are only accepted as penalties by competitions with the penalties scoring mode.
+// @Description  Clients can retry a submission with the same optional submission_id: a submission already applied
+// @Description  for the player is not applied again and is reported with the replayed status.
+// @Description  Implausible submissions, with too many points for any player or for the level of the player, too frequent,
+// @Description  or far above the submissions of the other players of the competition, are rejected and flagged for review.
 // @Accept       json
+// @Produce      json
 // @Param        score  body  map[string]interface{}  true  "Score submission"
-// @Success      200  {string}  string  "OK"
-// @Failure      409  {string}  string  "Conflict: no active competition"
+// @Success      200  {object}  leaderboard.ScoreSubmissionResponse
+// @Failure      400  {string}  string  "Invalid player ID, submission ID or negative score"
+// @Failure      409  {string}  string  "Conflict: no active competition or player disqualified"
+// @Failure      422  {string}  string  "Score rejected by the anti-cheat validation"
 // @Router       /leaderboard/score [post]
 func SubmitScoreHandler(w http.ResponseWriter, r *http.Request) {
 	type request struct {
-		PlayerID string `json:"player_id"`
-		Score    int    `json:"score"`
+		PlayerID     string `json:"player_id"`
+		Score        int    `json:"score"`
+		SubmissionID string `json:"submission_id"`
 	}
 
 	var req request
@@ -27,28 +39,38 @@ func SubmitScoreHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := leaderboard.AddScore(req.PlayerID, req.Score)
-	if err == nil {
-		w.WriteHeader(http.StatusOK)
-		return
-	} else if err == leaderboard.ErrCompetitionEnded {
-		http.Error(w, "Competition has ended, cannot add score", http.StatusConflict)
+	response, err := leaderboard.AddScore(req.PlayerID, req.Score, req.SubmissionID)
+	if err != nil {
+		status, message := scoreError(err)
+		http.Error(w, message, status)
 		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// scoreError returns the HTTP status and message of a rejected score submission
+func scoreError(err error) (int, string) {
+	var rejected *leaderboard.RejectedScoreError
+	if errors.As(err, &rejected) {
+		return http.StatusUnprocessableEntity, rejected.Error()
+	} else if err == leaderboard.ErrCompetitionEnded || err == model.ErrCompetitionEnded {
+		return http.StatusConflict, "Competition has ended, cannot add score"
 	} else if err == leaderboard.ErrCompetitionNotStarted {
-		http.Error(w, "Competition has not started yet, cannot add score", http.StatusConflict)
-		return
-	} else if err == leaderboard.ErrPlayerNotInCompetition {
-		http.Error(w, "Player is not in a competition, cannot add score", http.StatusConflict)
-		return
+		return http.StatusConflict, "Competition has not started yet, cannot add score"
+	} else if err == leaderboard.ErrPlayerNotInCompetition || err == model.ErrPlayerNotFound {
+		return http.StatusConflict, "Player is not in a competition, cannot add score"
+	} else if err == model.ErrPlayerDisqualified {
+		return http.StatusConflict, "Player is disqualified from the competition, cannot add score"
 	} else if err == leaderboard.ErrPlayerIdEmpty {
-		http.Error(w, "Player ID cannot be empty", http.StatusBadRequest)
-		return
+		return http.StatusBadRequest, "Player ID cannot be empty"
 	} else if err == leaderboard.ErrPlayerNotFound {
-		http.Error(w, "Player not found", http.StatusBadRequest)
-		return
-	} else {
-		// Some other error occurred
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
+		return http.StatusBadRequest, "Player not found"
+	} else if err == leaderboard.ErrInvalidSubmissionId {
+		return http.StatusBadRequest, err.Error()
+	} else if err == model.ErrPointsNegative {
+		return http.StatusBadRequest, "Score cannot be negative in this competition"
 	}
+	// Some other error occurred
+	return http.StatusInternalServerError, "Internal server error"
 }
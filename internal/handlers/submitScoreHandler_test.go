@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
@@ -9,15 +10,16 @@ import (
 	"testing"
 
 	"leaderboard/internal/leaderboard"
+	"leaderboard/internal/model"
 )
 
 // Mock leaderboard.AddScore and error variables for testing
 var (
-	mockAddScoreFunc func(playerID string, score int) error
+	mockAddScoreFunc func(playerID string, score int, submissionID string) (*leaderboard.ScoreSubmissionResponse, error)
 )
 
-func mockAddScore(playerID string, score int) error {
-	return mockAddScoreFunc(playerID, score)
+func mockAddScore(playerID string, score int, submissionID string) (*leaderboard.ScoreSubmissionResponse, error) {
+	return mockAddScoreFunc(playerID, score, submissionID)
 }
 
 func setupMocks() func() {
@@ -29,11 +31,14 @@ func setupMocks() func() {
 func TestSubmitScoreHandler_Success(t *testing.T) {
 	restore := setupMocks()
 	defer restore()
-	mockAddScoreFunc = func(playerID string, score int) error {
-		return nil
+	mockAddScoreFunc = func(playerID string, score int, submissionID string) (*leaderboard.ScoreSubmissionResponse, error) {
+		if submissionID != "s1" {
+			t.Errorf("expected submission ID s1, got %q", submissionID)
+		}
+		return &leaderboard.ScoreSubmissionResponse{PlayerId: playerID, SubmissionId: submissionID, Status: leaderboard.SubmissionReplayed}, nil
 	}
 
-	body := []byte(`{"player_id":"player1","score":100}`)
+	body := []byte(`{"player_id":"player1","score":100,"submission_id":"s1"}`)
 	req := httptest.NewRequest(http.MethodPost, "/leaderboard/score", bytes.NewReader(body))
 	w := httptest.NewRecorder()
 
@@ -43,6 +48,33 @@ func TestSubmitScoreHandler_Success(t *testing.T) {
 	if resp.StatusCode != http.StatusOK {
 		t.Errorf("expected status 200, got %d", resp.StatusCode)
 	}
+	var response leaderboard.ScoreSubmissionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil || response.Status != leaderboard.SubmissionReplayed {
+		t.Errorf("expected the replayed status, got %+v (%v)", response, err)
+	}
+}
+
+func TestSubmitScoreHandler_PlayerDisqualified(t *testing.T) {
+	restore := setupMocks()
+	defer restore()
+	mockAddScoreFunc = func(playerID string, score int, submissionID string) (*leaderboard.ScoreSubmissionResponse, error) {
+		return nil, model.ErrPlayerDisqualified
+	}
+
+	body := []byte(`{"player_id":"player1","score":100}`)
+	req := httptest.NewRequest(http.MethodPost, "/leaderboard/score", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	SubmitScoreHandler(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status 409, got %d", resp.StatusCode)
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+	if string(respBody) != "Player is disqualified from the competition, cannot add score\n" {
+		t.Errorf("unexpected body: %s", string(respBody))
+	}
 }
 
 func TestSubmitScoreHandler_InvalidJSON(t *testing.T) {
@@ -88,6 +120,11 @@ func TestSubmitScoreHandler_ErrorCases_TableDriven(t *testing.T) {
 			errorToReturn:  leaderboard.ErrCompetitionEnded,
 			expectedStatus: http.StatusConflict,
 		},
+		{
+			name:           "CompetitionFinalized",
+			errorToReturn:  model.ErrCompetitionEnded,
+			expectedStatus: http.StatusConflict,
+		},
 		{
 			name:           "CompetitionNotStarted",
 			errorToReturn:  leaderboard.ErrCompetitionNotStarted,
@@ -108,6 +145,21 @@ func TestSubmitScoreHandler_ErrorCases_TableDriven(t *testing.T) {
 			errorToReturn:  leaderboard.ErrPlayerNotFound,
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:           "InvalidSubmissionId",
+			errorToReturn:  leaderboard.ErrInvalidSubmissionId,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "PointsNegative",
+			errorToReturn:  model.ErrPointsNegative,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "ScoreRejected",
+			errorToReturn:  &leaderboard.RejectedScoreError{Validator: "rate", Err: leaderboard.ErrSubmissionRateExceeded},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
 		{
 			name:           "InternalServerError",
 			errorToReturn:  errors.New("some internal error"),
@@ -119,8 +171,8 @@ func TestSubmitScoreHandler_ErrorCases_TableDriven(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockAddScoreFunc = func(_ string, _ int) error {
-				return tt.errorToReturn
+			mockAddScoreFunc = func(_ string, _ int, _ string) (*leaderboard.ScoreSubmissionResponse, error) {
+				return nil, tt.errorToReturn
 			}
 			req := httptest.NewRequest(http.MethodPost, "/leaderboard/score", bytes.NewReader(body))
 			w := httptest.NewRecorder()
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"leaderboard/internal/leaderboard"
+)
+
+// SubmitScoresHandler godoc
+// @Summary      Submit a batch of scores
+// @Description  Submit the scores of many players at once. Each entry is applied like a single score submission,
+// @Description  in order, and the entries of a competition are applied together. The response has the result of each
+// @Description  entry in the order of the batch: applied, replayed, or rejected with the HTTP status and message
+// @Description  the single score submission would return.
+// @Accept       json
+// @Produce      json
+// @Param        scores  body  []leaderboard.ScoreEntry  true  "Score submissions (at most 1000)"
+// @Success      200  {object}  leaderboard.BatchScoreResponse
+// @Failure      400  {string}  string  "Invalid request body or batch size"
+// @Router       /leaderboard/scores [post]
+func SubmitScoresHandler(w http.ResponseWriter, r *http.Request) {
+	var entries []leaderboard.ScoreEntry
+	if err := json.NewDecoder(r.Body).Decode(&entries); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	results, err := leaderboard.AddScores(entries)
+	if err == leaderboard.ErrInvalidBatch {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := leaderboard.BatchScoreResponse{Results: make([]leaderboard.ScoreSubmissionResponse, 0, len(results))}
+	for _, result := range results {
+		if result.Err != nil {
+			result.Response.Code, result.Response.Error = scoreError(result.Err)
+		}
+		response.Results = append(response.Results, result.Response)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"leaderboard/internal/leaderboard"
+	"leaderboard/internal/model"
+)
+
+func mockAddScores(mock func(entries []leaderboard.ScoreEntry) ([]leaderboard.ScoreResult, error)) func() {
+	origAddScores := leaderboard.AddScores
+	leaderboard.AddScores = mock
+	return func() { leaderboard.AddScores = origAddScores }
+}
+
+func TestSubmitScoresHandler_ResultPerEntry(t *testing.T) {
+	defer mockAddScores(func(entries []leaderboard.ScoreEntry) ([]leaderboard.ScoreResult, error) {
+		if len(entries) != 3 || entries[0].SubmissionId != "s1" || entries[1].Score != 20 {
+			t.Errorf("unexpected entries %+v", entries)
+		}
+		return []leaderboard.ScoreResult{
+			{Response: leaderboard.ScoreSubmissionResponse{PlayerId: "alice", SubmissionId: "s1", Status: leaderboard.SubmissionApplied}},
+			{Response: leaderboard.ScoreSubmissionResponse{PlayerId: "bob", Status: leaderboard.SubmissionRejected}, Err: leaderboard.ErrCompetitionEnded},
+			{Response: leaderboard.ScoreSubmissionResponse{PlayerId: "carlos", Status: leaderboard.SubmissionRejected}, Err: leaderboard.ErrPlayerNotFound},
+		}, nil
+	})()
+
+	body := []byte(`[{"player_id":"alice","score":10,"submission_id":"s1"},{"player_id":"bob","score":20},{"player_id":"carlos","score":5}]`)
+	req := httptest.NewRequest(http.MethodPost, "/leaderboard/scores", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	SubmitScoresHandler(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	var response leaderboard.BatchScoreResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	expected := []struct {
+		status string
+		code   int
+	}{
+		{leaderboard.SubmissionApplied, 0},
+		{leaderboard.SubmissionRejected, http.StatusConflict},
+		{leaderboard.SubmissionRejected, http.StatusBadRequest},
+	}
+	for i, result := range response.Results {
+		if result.Status != expected[i].status || result.Code != expected[i].code {
+			t.Errorf("entry %d: expected %s with code %d, got %+v", i, expected[i].status, expected[i].code, result)
+		}
+	}
+	if response.Results[1].Error != "Competition has ended, cannot add score" {
+		t.Errorf("unexpected error message %q", response.Results[1].Error)
+	}
+}
+
+func TestSubmitScoresHandler_PlayerDisqualified(t *testing.T) {
+	defer mockAddScores(func(entries []leaderboard.ScoreEntry) ([]leaderboard.ScoreResult, error) {
+		return []leaderboard.ScoreResult{
+			{Response: leaderboard.ScoreSubmissionResponse{PlayerId: "alice", Status: leaderboard.SubmissionApplied}},
+			{Response: leaderboard.ScoreSubmissionResponse{PlayerId: "bob", Status: leaderboard.SubmissionRejected}, Err: model.ErrPlayerDisqualified},
+		}, nil
+	})()
+
+	body := []byte(`[{"player_id":"alice","score":10},{"player_id":"bob","score":20}]`)
+	req := httptest.NewRequest(http.MethodPost, "/leaderboard/scores", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	SubmitScoresHandler(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	var response leaderboard.BatchScoreResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Results[0].Code != 0 || response.Results[1].Code != http.StatusConflict ||
+		response.Results[1].Error != "Player is disqualified from the competition, cannot add score" {
+		t.Errorf("expected bob to be rejected as disqualified with 409, got %+v", response.Results)
+	}
+}
+
+func TestSubmitScoresHandler_InvalidRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		errorToReturn  error
+		expectedStatus int
+	}{
+		{"InvalidJSON", `{"player_id":"alice"}`, nil, http.StatusBadRequest},
+		{"InvalidBatch", `[]`, leaderboard.ErrInvalidBatch, http.StatusBadRequest},
+		{"InternalServerError", `[{"player_id":"alice","score":10}]`, errors.New("some internal error"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer mockAddScores(func(_ []leaderboard.ScoreEntry) ([]leaderboard.ScoreResult, error) {
+				return nil, tt.errorToReturn
+			})()
+			req := httptest.NewRequest(http.MethodPost, "/leaderboard/scores", bytes.NewReader([]byte(tt.body)))
+			w := httptest.NewRecorder()
+
+			SubmitScoresHandler(w, req)
+
+			if w.Result().StatusCode != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Result().StatusCode)
+			}
+		})
+	}
+}
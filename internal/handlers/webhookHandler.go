@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"leaderboard/internal/webhooks"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RegisterWebhookHandler godoc
+// @Summary      Register a webhook
+// @Description  Register an endpoint that receives the final leaderboard of each ended competition as a JSON webhooks.Payload.
+// @Description  Each delivery is signed in the X-Leaderboard-Signature header with "sha256=" followed by the hexadecimal
+// @Description  HMAC-SHA256 of the body with the secret. Failed deliveries are retried with an exponential backoff.
+// @Accept       json
+// @Produce      json
+// @Param        webhook  body  map[string]interface{}  true  "url and secret"
+// @Success      201  {object}  webhooks.Webhook
+// @Failure      400  {string}  string  "Invalid request body, URL or empty secret"
+// @Router       /admin/webhooks [post]
+func RegisterWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		Url    string `json:"url"`
+		Secret string `json:"secret"`
+	}
+
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	webhook, err := webhooks.RegisterWebhook(req.Url, req.Secret)
+	if err == webhooks.ErrUrlInvalid || err == webhooks.ErrSecretEmpty {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// ListWebhooksHandler godoc
+// @Summary      List webhooks
+// @Description  List the registered webhooks, without their secrets
+// @Produce      json
+// @Success      200  {array}  webhooks.Webhook
+// @Router       /admin/webhooks [get]
+func ListWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhooks.ListWebhooks())
+}
+
+// DeleteWebhookHandler godoc
+// @Summary      Delete a webhook
+// @Description  Stop the deliveries to a webhook. Deliveries being retried are still attempted
+// @Param        webhookID  path  string  true  "Webhook ID"
+// @Success      204
+// @Failure      404  {string}  string  "Webhook not found"
+// @Router       /admin/webhooks/{webhookID} [delete]
+func DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	err := webhooks.DeleteWebhook(chi.URLParam(r, "webhookID"))
+	if err == webhooks.ErrWebhookNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeadLettersHandler godoc
+// @Summary      List failed webhook deliveries
+// @Description  List the deliveries whose attempts all failed, oldest first, with their payload and last error
+// @Produce      json
+// @Success      200  {array}  webhooks.Delivery
+// @Router       /admin/webhooks/dead-letters [get]
+func DeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhooks.GetDeadLetters())
+}
+
+// ReplayDeliveryHandler godoc
+// @Summary      Replay a failed webhook delivery
+// @Description  Remove a delivery from the dead letters and deliver its payload again, with the same delivery ID,
+// @Description  to the current URL and with the current secret of its webhook
+// @Produce      json
+// @Param        deliveryID  path  string  true  "Delivery ID"
+// @Success      202  {object}  webhooks.Delivery
+// @Failure      404  {string}  string  "Dead letter or webhook not found"
+// @Router       /admin/webhooks/dead-letters/{deliveryID}/replay [post]
+func ReplayDeliveryHandler(w http.ResponseWriter, r *http.Request) {
+	delivery, err := webhooks.ReplayDelivery(chi.URLParam(r, "deliveryID"))
+	if err == webhooks.ErrDeliveryNotFound || err == webhooks.ErrWebhookNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(delivery)
+}
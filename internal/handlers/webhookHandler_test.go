@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"leaderboard/internal/webhooks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var (
+	origRegisterWebhook = webhooks.RegisterWebhook
+	origDeleteWebhook   = webhooks.DeleteWebhook
+	origReplayDelivery  = webhooks.ReplayDelivery
+)
+
+func webhookRequest(method, target, param, value string, body string) *http.Request {
+	req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+	rctx := chi.NewRouteContext()
+	if param != "" {
+		rctx.URLParams.Add(param, value)
+	}
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestRegisterWebhookHandler_Success(t *testing.T) {
+	defer func() { webhooks.RegisterWebhook = origRegisterWebhook }()
+	webhooks.RegisterWebhook = func(url, secret string) (*webhooks.Webhook, error) {
+		return &webhooks.Webhook{Id: "hook1", Url: url}, nil
+	}
+
+	w := httptest.NewRecorder()
+	RegisterWebhookHandler(w, webhookRequest(http.MethodPost, "/admin/webhooks", "", "", `{"url":"https://rewards.local/hook","secret":"s3cret"}`))
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", w.Code)
+	}
+	var webhook webhooks.Webhook
+	if err := json.NewDecoder(w.Body).Decode(&webhook); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if webhook.Id != "hook1" || webhook.Url != "https://rewards.local/hook" {
+		t.Errorf("unexpected webhook %+v", webhook)
+	}
+	if bytes.Contains(w.Body.Bytes(), []byte("s3cret")) {
+		t.Errorf("expected the secret not to be returned, got %s", w.Body.String())
+	}
+}
+
+func TestRegisterWebhookHandler_Errors(t *testing.T) {
+	defer func() { webhooks.RegisterWebhook = origRegisterWebhook }()
+
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		expected int
+	}{
+		{"InvalidBody", `{`, nil, http.StatusBadRequest},
+		{"UrlInvalid", `{"url":"ftp://rewards.local"}`, webhooks.ErrUrlInvalid, http.StatusBadRequest},
+		{"SecretEmpty", `{"url":"https://rewards.local"}`, webhooks.ErrSecretEmpty, http.StatusBadRequest},
+		{"InternalServerError", `{"url":"https://rewards.local","secret":"s"}`, errors.New("disk full"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhooks.RegisterWebhook = func(string, string) (*webhooks.Webhook, error) {
+				return nil, tt.err
+			}
+			w := httptest.NewRecorder()
+			RegisterWebhookHandler(w, webhookRequest(http.MethodPost, "/admin/webhooks", "", "", tt.body))
+			if w.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
+
+func TestDeleteWebhookHandler(t *testing.T) {
+	defer func() { webhooks.DeleteWebhook = origDeleteWebhook }()
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"Deleted", nil, http.StatusNoContent},
+		{"WebhookNotFound", webhooks.ErrWebhookNotFound, http.StatusNotFound},
+		{"InternalServerError", errors.New("disk full"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhooks.DeleteWebhook = func(id string) error {
+				if id != "hook1" {
+					t.Errorf("expected webhook hook1, got %s", id)
+				}
+				return tt.err
+			}
+			w := httptest.NewRecorder()
+			DeleteWebhookHandler(w, webhookRequest(http.MethodDelete, "/admin/webhooks/hook1", "webhookID", "hook1", ""))
+			if w.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
+
+func TestReplayDeliveryHandler(t *testing.T) {
+	defer func() { webhooks.ReplayDelivery = origReplayDelivery }()
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"Replayed", nil, http.StatusAccepted},
+		{"DeliveryNotFound", webhooks.ErrDeliveryNotFound, http.StatusNotFound},
+		{"WebhookNotFound", webhooks.ErrWebhookNotFound, http.StatusNotFound},
+		{"InternalServerError", errors.New("disk full"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhooks.ReplayDelivery = func(id string) (*webhooks.Delivery, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &webhooks.Delivery{Id: id}, nil
+			}
+			w := httptest.NewRecorder()
+			ReplayDeliveryHandler(w, webhookRequest(http.MethodPost, "/admin/webhooks/dead-letters/d1/replay", "deliveryID", "d1", ""))
+			if w.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package leaderboard
+
+import (
+	"errors"
+	"leaderboard/internal/config"
+	"leaderboard/internal/model"
+	"leaderboard/internal/storage"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrCompetitionNotEnded = errors.New("competition has not ended yet")
+	ErrInvalidPage         = errors.New("offset cannot be negative and limit must be between 1 and the maximum page size")
+)
+
+// Standing is the accumulated result of a player in an aggregate leaderboard
+type Standing struct {
+	Rank         int    `json:"rank"`
+	PlayerId     string `json:"player_id"`
+	Points       int    `json:"points"`
+	Wins         int    `json:"wins"`
+	Podiums      int    `json:"podiums"`
+	Competitions int    `json:"competitions"`
+}
+
+type AggregateLeaderboardResponse struct {
+	Board     string     `json:"board"`
+	Total     int        `json:"total"`
+	Offset    int        `json:"offset"`
+	Limit     int        `json:"limit"`
+	Standings []Standing `json:"standings"`
+}
+
+// aggregateBoard accumulates the results of ended competitions.
+// Standings are sorted when a page is read after new results were added.
+type aggregateBoard struct {
+	standings map[string]*Standing
+	sorted    []*Standing
+	dirty     bool
+}
+
+func newAggregateBoard() *aggregateBoard {
+	return &aggregateBoard{standings: make(map[string]*Standing)}
+}
+
+var (
+	// Synchronizes the access to the aggregate boards
+	aggregatesMutex sync.Mutex
+	globalBoard     = newAggregateBoard()
+	levelBoards     = make(map[int]*aggregateBoard)
+	countryBoards   = make(map[string]*aggregateBoard)
+	// Competitions whose results are in the aggregate boards
+	aggregatedCompetitions = make(map[string]bool)
+)
+
+// RestoreAggregates rebuilds the aggregate boards from the results in storage. It is called once on startup
+func RestoreAggregates() {
+	aggregatesMutex.Lock()
+	defer aggregatesMutex.Unlock()
+	globalBoard = newAggregateBoard()
+	levelBoards = make(map[int]*aggregateBoard)
+	countryBoards = make(map[string]*aggregateBoard)
+	aggregatedCompetitions = make(map[string]bool)
+	for _, result := range storage.Current.ListResults() {
+		aggregateResult(result)
+	}
+}
+
+// RecordResults stores the final standings of an ended competition, ranked with config.RankTiePolicy,
+// and adds them to the aggregate boards. Results of a competition are only recorded once.
+func RecordResults(comp model.ICompetition) error {
+	aggregatesMutex.Lock()
+	defer aggregatesMutex.Unlock()
+	return recordResults(comp)
+}
+
+func recordResults(comp model.ICompetition) error {
+	if aggregatedCompetitions[comp.Id()] {
+		return nil
+	}
+	if !comp.Ended() {
+		return ErrCompetitionNotEnded
+	}
+
+	// The standings are frozen once the competition has ended, so they are the ones published by Competition.End
+	standings := comp.Standings(0, math.MaxInt)
+	results := make([]storage.ResultRecord, 0, len(standings))
+	for _, ranked := range standings {
+		results = append(results, storage.ResultRecord{
+			CompetitionId: comp.Id(),
+			PlayerId:      ranked.Player.Player().Id(),
+			Level:         ranked.Player.Level(),
+			CountryCode:   ranked.Player.CountryCode(),
+			Rank:          ranked.Rank,
+			Score:         ranked.Player.Score(),
+			EndsAt:        comp.EndsAt(),
+		})
+	}
+	if err := storage.Current.PutResults(comp.Id(), results); err != nil {
+		return err
+	}
+	// A competition without ranked players has no result to aggregate
+	aggregatedCompetitions[comp.Id()] = true
+	for _, result := range results {
+		aggregateResult(result)
+	}
+	return nil
+}
+
+func aggregateResult(result storage.ResultRecord) {
+	aggregatedCompetitions[result.CompetitionId] = true
+	globalBoard.add(result)
+
+	levelBoard, found := levelBoards[result.Level]
+	if !found {
+		levelBoard = newAggregateBoard()
+		levelBoards[result.Level] = levelBoard
+	}
+	levelBoard.add(result)
+
+	countryBoard, found := countryBoards[result.CountryCode]
+	if !found {
+		countryBoard = newAggregateBoard()
+		countryBoards[result.CountryCode] = countryBoard
+	}
+	countryBoard.add(result)
+}
+
+var GetGlobalLeaderboard = func(offset, limit int) (*AggregateLeaderboardResponse, error) {
+	return getAggregateLeaderboard("global", offset, limit, func() *aggregateBoard {
+		return globalBoard
+	})
+}
+
+var GetLevelLeaderboard = func(level, offset, limit int) (*AggregateLeaderboardResponse, error) {
+	if err := model.ValidateLevel(level); err != nil {
+		return nil, err
+	}
+	return getAggregateLeaderboard("level/"+strconv.Itoa(level), offset, limit, func() *aggregateBoard {
+		return levelBoards[level]
+	})
+}
+
+var GetCountryLeaderboard = func(countryCode string, offset, limit int) (*AggregateLeaderboardResponse, error) {
+	if err := model.ValidateCountryCode(countryCode); err != nil {
+		return nil, err
+	}
+	return getAggregateLeaderboard("country/"+countryCode, offset, limit, func() *aggregateBoard {
+		return countryBoards[countryCode]
+	})
+}
+
+// getAggregateLeaderboard returns a page of a board. The results of the competitions are recorded when they end
+func getAggregateLeaderboard(name string, offset, limit int, board func() *aggregateBoard) (*AggregateLeaderboardResponse, error) {
+	if offset < 0 || limit < 1 || limit > config.MaxLeaderboardPageSize {
+		return nil, ErrInvalidPage
+	}
+	aggregatesMutex.Lock()
+	defer aggregatesMutex.Unlock()
+
+	response := &AggregateLeaderboardResponse{
+		Board:     name,
+		Offset:    offset,
+		Limit:     limit,
+		Standings: []Standing{},
+	}
+	if b := board(); b != nil {
+		response.Total, response.Standings = b.page(offset, limit)
+	}
+	return response, nil
+}
+
+func (b *aggregateBoard) add(result storage.ResultRecord) {
+	standing, found := b.standings[result.PlayerId]
+	if !found {
+		standing = &Standing{PlayerId: result.PlayerId}
+		b.standings[result.PlayerId] = standing
+		b.sorted = append(b.sorted, standing)
+	}
+	standing.Points += result.Score
+	standing.Competitions++
+	if result.Rank == 1 {
+		standing.Wins++
+	}
+	if result.Rank <= config.PodiumSize {
+		standing.Podiums++
+	}
+	b.dirty = true
+}
+
+// page returns the number of players in the board and the standings from the offset
+func (b *aggregateBoard) page(offset, limit int) (int, []Standing) {
+	if b.dirty {
+		b.sort()
+	}
+	standings := []Standing{}
+	for i := offset; i < len(b.sorted) && i < offset+limit; i++ {
+		standings = append(standings, *b.sorted[i])
+	}
+	return len(b.sorted), standings
+}
+
+// sort orders the standings by points, then wins, then podiums, then player ID
+func (b *aggregateBoard) sort() {
+	slices.SortFunc(b.sorted, func(a, b *Standing) int {
+		if a.Points != b.Points {
+			return b.Points - a.Points
+		}
+		if a.Wins != b.Wins {
+			return b.Wins - a.Wins
+		}
+		if a.Podiums != b.Podiums {
+			return b.Podiums - a.Podiums
+		}
+		return strings.Compare(a.PlayerId, b.PlayerId)
+	})
+	for i, standing := range b.sorted {
+		standing.Rank = i + 1
+	}
+	b.dirty = false
+}
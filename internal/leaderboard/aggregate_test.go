@@ -0,0 +1,163 @@
+package leaderboard
+
+import (
+	"leaderboard/internal/config"
+	"leaderboard/internal/model"
+	"leaderboard/internal/storage"
+	"leaderboard/internal/timeprovider"
+	"testing"
+)
+
+func setupAggregates(t *testing.T) func() {
+	storage.Current = storage.NewMemoryStore()
+	RestoreAggregates()
+	return func() {
+		storage.Current = storage.NewMemoryStore()
+		RestoreAggregates()
+		timeprovider.Current = timeprovider.RealTimeProvider{}
+	}
+}
+
+// playCompetition stores a started competition with the given scores
+func playCompetition(t *testing.T, players []*model.Player, scores ...int) model.ICompetition {
+	t.Helper()
+	comp := model.NewCompetition(players[0].Level())
+	for _, player := range players {
+		if err := comp.AddPlayer(player); err != nil {
+			t.Fatalf("AddPlayer() returned error %v", err)
+		}
+	}
+	if err := comp.Start(); err != nil {
+		t.Fatalf("Start() returned error %v", err)
+	}
+	for i, player := range players {
+		_ = comp.AddScore(player.Id(), scores[i])
+	}
+	_ = storage.Current.PutCompetition(comp)
+	return comp
+}
+
+func TestGetGlobalLeaderboard_AccumulatesEndedCompetitions(t *testing.T) {
+	defer setupAggregates(t)()
+	alice := model.NewPlayer("alice", 1, "US")
+	bob := model.NewPlayer("bob", 1, "GB")
+	carlos := model.NewPlayer("carlos", 2, "US")
+	diana := model.NewPlayer("diana", 2, "KR")
+
+	ended := playCompetition(t, []*model.Player{alice, bob, carlos, diana}, 10, 30, 20, 5)
+	_ = ended.End()
+	if err := RecordResults(ended); err != nil {
+		t.Fatalf("RecordResults() returned error %v", err)
+	}
+	ongoing := playCompetition(t, []*model.Player{alice, bob}, 100, 0)
+	if err := RecordResults(ongoing); err != ErrCompetitionNotEnded {
+		t.Errorf("expected ErrCompetitionNotEnded for the ongoing competition, got %v", err)
+	}
+
+	resp, err := GetGlobalLeaderboard(0, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Standing{
+		{Rank: 1, PlayerId: "bob", Points: 30, Wins: 1, Podiums: 1, Competitions: 1},
+		{Rank: 2, PlayerId: "carlos", Points: 20, Podiums: 1, Competitions: 1},
+		{Rank: 3, PlayerId: "alice", Points: 10, Podiums: 1, Competitions: 1},
+		{Rank: 4, PlayerId: "diana", Points: 5, Competitions: 1},
+	}
+	if resp.Total != 4 || len(resp.Standings) != 4 {
+		t.Fatalf("expected 4 standings, got %+v", resp)
+	}
+	for i, standing := range expected {
+		if resp.Standings[i] != standing {
+			t.Errorf("expected %+v, got %+v", standing, resp.Standings[i])
+		}
+	}
+
+	// Results are recorded once
+	if err := RecordResults(ended); err != nil {
+		t.Fatalf("RecordResults() returned error %v", err)
+	}
+	if results := storage.Current.ListResults(); len(results) != 4 {
+		t.Errorf("expected 4 recorded results, got %d", len(results))
+	}
+
+	level, _ := GetLevelLeaderboard(2, 0, 10)
+	if level.Board != "level/2" || level.Total != 2 || level.Standings[0].PlayerId != "carlos" || level.Standings[0].Rank != 1 {
+		t.Errorf("expected carlos to lead the level 2 board, got %+v", level)
+	}
+	country, _ := GetCountryLeaderboard("US", 1, 10)
+	if country.Total != 2 || len(country.Standings) != 1 || country.Standings[0].PlayerId != "alice" || country.Standings[0].Rank != 2 {
+		t.Errorf("expected alice second on the US board, got %+v", country)
+	}
+	empty, _ := GetCountryLeaderboard("FR", 0, 10)
+	if empty.Total != 0 || len(empty.Standings) != 0 {
+		t.Errorf("expected an empty board, got %+v", empty)
+	}
+}
+
+func TestRecordResults_RestoredAfterCompetitionDeleted(t *testing.T) {
+	defer setupAggregates(t)()
+	alice := model.NewPlayer("alice", 1, "US")
+	bob := model.NewPlayer("bob", 1, "GB")
+	comp := playCompetition(t, []*model.Player{alice, bob}, 10, 30)
+
+	if err := RecordResults(comp); err != ErrCompetitionNotEnded {
+		t.Errorf("expected ErrCompetitionNotEnded, got %v", err)
+	}
+	_ = comp.End()
+	if err := RecordResults(comp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = storage.Current.DeleteCompetition(comp.Id())
+
+	RestoreAggregates()
+	resp, _ := GetGlobalLeaderboard(0, 10)
+	if resp.Total != 2 || resp.Standings[0].PlayerId != "bob" || resp.Standings[0].Wins != 1 {
+		t.Errorf("expected bob to lead the restored board, got %+v", resp)
+	}
+}
+
+func TestRecordResults_RanksTiesWithPolicy(t *testing.T) {
+	defer setupAggregates(t)()
+	defer func(policy string) { config.RankTiePolicy = policy }(config.RankTiePolicy)
+	config.RankTiePolicy = model.TiePolicyCompetition
+	alice := model.NewPlayer("alice", 1, "US")
+	bob := model.NewPlayer("bob", 1, "GB")
+	carlos := model.NewPlayer("carlos", 1, "MX")
+	comp := playCompetition(t, []*model.Player{alice, bob, carlos}, 30, 30, 10)
+	_ = comp.End()
+	if err := RecordResults(comp); err != nil {
+		t.Fatalf("RecordResults() returned error %v", err)
+	}
+
+	ranks := map[string]int{}
+	for _, result := range storage.Current.ListResults() {
+		ranks[result.PlayerId] = result.Rank
+	}
+	if ranks["alice"] != 1 || ranks["bob"] != 1 || ranks["carlos"] != 3 {
+		t.Errorf("expected alice and bob to share rank 1 and carlos to be third, got %v", ranks)
+	}
+	resp, _ := GetGlobalLeaderboard(0, 10)
+	if resp.Standings[0].Wins != 1 || resp.Standings[1].Wins != 1 {
+		t.Errorf("expected alice and bob to both win, got %+v", resp.Standings)
+	}
+}
+
+func TestGetAggregateLeaderboard_InvalidArguments(t *testing.T) {
+	defer setupAggregates(t)()
+	if _, err := GetGlobalLeaderboard(-1, 10); err != ErrInvalidPage {
+		t.Errorf("expected ErrInvalidPage for a negative offset, got %v", err)
+	}
+	if _, err := GetGlobalLeaderboard(0, 0); err != ErrInvalidPage {
+		t.Errorf("expected ErrInvalidPage for an empty page, got %v", err)
+	}
+	if _, err := GetGlobalLeaderboard(0, 1000); err != ErrInvalidPage {
+		t.Errorf("expected ErrInvalidPage for a page too large, got %v", err)
+	}
+	if _, err := GetLevelLeaderboard(99, 0, 10); err != model.ErrInvalidLevel {
+		t.Errorf("expected ErrInvalidLevel, got %v", err)
+	}
+	if _, err := GetCountryLeaderboard("usa", 0, 10); err != model.ErrInvalidCountryCode {
+		t.Errorf("expected ErrInvalidCountryCode, got %v", err)
+	}
+}
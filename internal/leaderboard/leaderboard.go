@@ -4,9 +4,12 @@ package leaderboard
 
 import (
 	"errors"
+	"fmt"
+	"leaderboard/internal/config"
 	"leaderboard/internal/model"
 	"leaderboard/internal/storage"
 	"leaderboard/internal/timeprovider"
+	"math"
 	"time"
 )
 
@@ -18,32 +21,96 @@ var (
 	ErrCompetitionEnded       = errors.New("competition has ended, cannot add score for player")
 	ErrCompetitionNotStarted  = errors.New("competition has not started yet, cannot add score for player")
 	ErrPlayerNotInCompetition = errors.New("player is not in a competition, cannot add score")
+	ErrPlayerNotInLeaderboard = errors.New("player is not in the leaderboard")
+	ErrInvalidRadius          = errors.New("radius cannot be negative or exceed the maximum radius")
+	ErrInvalidSubmissionId    = fmt.Errorf("submission ID cannot be longer than %d characters", maxSubmissionIdLength)
 )
 
-var AddScore = func(playerId string, points int) error {
-	comp, err := getCompetition(playerId)
+const maxSubmissionIdLength = 128
+
+// Statuses of a score submission
+const (
+	SubmissionApplied  = "applied"
+	SubmissionReplayed = "replayed"
+	SubmissionRejected = "rejected"
+)
+
+type ScoreSubmissionResponse struct {
+	PlayerId     string `json:"player_id"`
+	SubmissionId string `json:"submission_id,omitempty"`
+	// "applied", "replayed" if a submission with the same ID was already applied, or "rejected" in a batch
+	Status string `json:"status"`
+	// HTTP status and message of a rejected entry of a batch
+	Code  int    `json:"code,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// AddScore submits a score to the current competition of a player.
+// The submission is checked by the ScoreValidators first and a *RejectedScoreError is returned if one of them rejects it.
+// A submission with the ID of a submission already applied for the player is not applied again and is reported as replayed.
+var AddScore = func(playerId string, points int, submissionId string) (*ScoreSubmissionResponse, error) {
+	comp, err := getScoringCompetition(playerId, submissionId)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if comp.StartedAt().IsZero() {
-		return ErrCompetitionNotStarted
-	} else if comp.EndsAt().Before(timeprovider.Current.Now()) {
-		return ErrCompetitionEnded
+
+	response := &ScoreSubmissionResponse{PlayerId: playerId, SubmissionId: submissionId, Status: SubmissionApplied}
+	submission := validatedSubmission(comp, playerId, points, submissionId, model.SourceApi)
+	result := comp.SubmitScores([]model.ScoreSubmission{submission})[0]
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	if !result.Applied {
+		response.Status = SubmissionReplayed
+		return response, nil
 	}
+	if err := storage.Current.PutScore(comp.Id(), playerId, result.Change); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetLeaderboard returns a page of the leaderboard of a competition
+var GetLeaderboard = func(leaderboardId string, offset, limit int) (*LeaderboardResponse, error) {
+	if offset < 0 || limit < 1 || limit > config.MaxLeaderboardPageSize {
+		return nil, ErrInvalidPage
+	}
+	comp, err := getLeaderboardCompetition(leaderboardId)
+	if err != nil {
+		return nil, err
+	}
+	return asLeaderboardPage(comp, offset, limit), nil
+}
 
-	err = comp.AddScore(playerId, points)
-	return err
+// GetLeaderboardAround returns the players ranked up to radius positions above and below a player of a competition
+var GetLeaderboardAround = func(leaderboardId string, playerId string, radius int) (*LeaderboardResponse, error) {
+	if playerId == "" {
+		return nil, ErrPlayerIdEmpty
+	}
+	if radius < 0 || radius > config.MaxLeaderboardRadius {
+		return nil, ErrInvalidRadius
+	}
+	comp, err := getLeaderboardCompetition(leaderboardId)
+	if err != nil {
+		return nil, err
+	}
+	position, found := comp.Position(playerId)
+	if !found {
+		return nil, ErrPlayerNotInLeaderboard
+	}
+	offset := max(position-radius, 0)
+	return asLeaderboardPage(comp, offset, position+radius+1-offset), nil
 }
 
-var GetLeaderboard = func(leaderboardId string) (*LeaderboardResponse, error) {
+func getLeaderboardCompetition(leaderboardId string) (model.ICompetition, error) {
 	if leaderboardId == "" {
 		return nil, ErrLeaderboardIdEmpty
 	}
-	comp, found := storage.Competitions[leaderboardId]
+	comp, found := storage.Current.GetCompetition(leaderboardId)
 	if !found {
 		return nil, ErrCompetetionNotFound
 	}
-	return asLeaderboardResponse(comp), nil
+	return comp, nil
 }
 
 var GetLeaderboardForPlayer = func(playerId string) (*LeaderboardResponse, error) {
@@ -58,11 +125,28 @@ var GetLeaderboardForPlayer = func(playerId string) (*LeaderboardResponse, error
 	}
 }
 
+// getScoringCompetition returns the competition a player can submit a score to
+func getScoringCompetition(playerId string, submissionId string) (model.ICompetition, error) {
+	if len(submissionId) > maxSubmissionIdLength {
+		return nil, ErrInvalidSubmissionId
+	}
+	comp, err := getCompetition(playerId)
+	if err != nil {
+		return nil, err
+	}
+	if comp.StartedAt().IsZero() {
+		return nil, ErrCompetitionNotStarted
+	} else if comp.EndsAt().Before(timeprovider.Current.Now()) {
+		return nil, ErrCompetitionEnded
+	}
+	return comp, nil
+}
+
 func getCompetition(playerId string) (model.ICompetition, error) {
 	if playerId == "" {
 		return nil, ErrPlayerIdEmpty
 	}
-	player, found := storage.Players[playerId]
+	player, found := storage.Current.GetPlayer(playerId)
 	if !found {
 		return nil, ErrPlayerNotFound
 	}
@@ -78,18 +162,26 @@ func asLeaderboardResponse(comp model.ICompetition) *LeaderboardResponse {
 	if comp == nil {
 		return nil
 	}
+	return asLeaderboardPage(comp, 0, math.MaxInt)
+}
 
-	leaderboard := make([]PlayerScore, 0, len(comp.PlayersMap()))
-	for _, player := range comp.Leaderboard() {
+// asLeaderboardPage returns at most limit players of the leaderboard from the offset, with their rank
+func asLeaderboardPage(comp model.ICompetition, offset, limit int) *LeaderboardResponse {
+	standings := comp.Standings(offset, limit)
+	leaderboard := make([]PlayerScore, 0, len(standings))
+	for _, standing := range standings {
 		leaderboard = append(leaderboard, PlayerScore{
-			PlayerId: player.Player().Id(),
-			Score:    player.Score(),
+			Rank:     standing.Rank,
+			PlayerId: standing.Player.Player().Id(),
+			Score:    standing.Player.Score(),
 		})
 	}
 
 	return &LeaderboardResponse{
 		Id:          comp.Id(),
 		EndsAt:      comp.EndsAt(),
+		Total:       comp.RankedCount(),
+		Offset:      offset,
 		Leaderboard: leaderboard,
 	}
 }
@@ -97,10 +189,13 @@ func asLeaderboardResponse(comp model.ICompetition) *LeaderboardResponse {
 type LeaderboardResponse struct {
 	Id          string        `json:"leaderboard_id"`
 	EndsAt      time.Time     `json:"ends_at"`
+	Total       int           `json:"total"`
+	Offset      int           `json:"offset"`
 	Leaderboard []PlayerScore `json:"leaderboard"`
 }
 
 type PlayerScore struct {
+	Rank     int    `json:"rank"`
 	PlayerId string `json:"player_id"`
 	Score    int    `json:"score"`
 }
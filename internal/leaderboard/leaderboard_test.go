@@ -0,0 +1,166 @@
+package leaderboard
+
+import (
+	"fmt"
+	"leaderboard/internal/config"
+	"leaderboard/internal/model"
+	"leaderboard/internal/storage"
+	"leaderboard/internal/timeprovider"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startLeaderboard stores a started competition where player p<i> has the i-th score
+func startLeaderboard(t *testing.T, scores ...int) model.ICompetition {
+	t.Helper()
+	players := make([]*model.Player, len(scores))
+	for i := range scores {
+		players[i] = model.NewPlayer(fmt.Sprintf("p%d", i), 1, "US")
+	}
+	return playCompetition(t, players, scores...)
+}
+
+func playerIds(resp *LeaderboardResponse) []string {
+	ids := make([]string, 0, len(resp.Leaderboard))
+	for _, entry := range resp.Leaderboard {
+		ids = append(ids, entry.PlayerId)
+	}
+	return ids
+}
+
+func TestGetLeaderboard_Page(t *testing.T) {
+	defer setupAggregates(t)()
+	comp := startLeaderboard(t, 50, 40, 30, 20, 10)
+
+	resp, err := GetLeaderboard(comp.Id(), 1, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Total != 5 || resp.Offset != 1 || fmt.Sprint(playerIds(resp)) != "[p1 p2]" {
+		t.Errorf("expected p1 and p2 of 5 players, got %+v", resp)
+	}
+	if resp.Leaderboard[0].Rank != 2 || resp.Leaderboard[0].Score != 40 {
+		t.Errorf("expected p1 at rank 2 with 40 points, got %+v", resp.Leaderboard[0])
+	}
+
+	if _, err := GetLeaderboard(comp.Id(), 0, config.MaxLeaderboardPageSize+1); err != ErrInvalidPage {
+		t.Errorf("expected ErrInvalidPage, got %v", err)
+	}
+	if _, err := GetLeaderboard("unknown", 0, 10); err != ErrCompetetionNotFound {
+		t.Errorf("expected ErrCompetetionNotFound, got %v", err)
+	}
+}
+
+func TestGetLeaderboard_TiePolicy(t *testing.T) {
+	defer setupAggregates(t)()
+	defer func() { config.RankTiePolicy = model.TiePolicyOrdinal }()
+	comp := startLeaderboard(t, 30, 20, 20, 10)
+
+	config.RankTiePolicy = model.TiePolicyCompetition
+	resp, _ := GetLeaderboard(comp.Id(), 0, 10)
+	ranks := []int{}
+	for _, entry := range resp.Leaderboard {
+		ranks = append(ranks, entry.Rank)
+	}
+	if fmt.Sprint(ranks) != "[1 2 2 4]" {
+		t.Errorf("expected competition ranks [1 2 2 4], got %v", ranks)
+	}
+}
+
+func TestGetLeaderboardAround(t *testing.T) {
+	defer setupAggregates(t)()
+	comp := startLeaderboard(t, 50, 40, 30, 20, 10)
+
+	tests := []struct {
+		name     string
+		playerId string
+		radius   int
+		expected string
+	}{
+		{"Middle", "p2", 1, "[p1 p2 p3]"},
+		{"Top", "p0", 2, "[p0 p1 p2]"},
+		{"Bottom", "p4", 1, "[p3 p4]"},
+		{"Radius zero", "p3", 0, "[p3]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := GetLeaderboardAround(comp.Id(), tt.playerId, tt.radius)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fmt.Sprint(playerIds(resp)) != tt.expected {
+				t.Errorf("expected %s, got %v", tt.expected, playerIds(resp))
+			}
+		})
+	}
+
+	if _, err := GetLeaderboardAround(comp.Id(), "unknown", 1); err != ErrPlayerNotInLeaderboard {
+		t.Errorf("expected ErrPlayerNotInLeaderboard, got %v", err)
+	}
+	if _, err := GetLeaderboardAround(comp.Id(), "p1", -1); err != ErrInvalidRadius {
+		t.Errorf("expected ErrInvalidRadius, got %v", err)
+	}
+}
+
+func TestGetLeaderboard_NotStarted_EmptyLeaderboard(t *testing.T) {
+	defer setupAggregates(t)()
+	comp := model.NewCompetition(1)
+	_ = comp.AddPlayer(model.NewPlayer("p0", 1, "US"))
+	_ = storage.Current.PutCompetition(comp)
+
+	resp, err := GetLeaderboard(comp.Id(), 0, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Leaderboard) != 0 {
+		t.Errorf("expected an empty leaderboard before the competition starts, got %v", resp.Leaderboard)
+	}
+}
+
+func TestAddScore_SubmissionIdReplayed(t *testing.T) {
+	defer setupAggregates(t)()
+	alice := model.NewPlayer("alice", 1, "US")
+	bob := model.NewPlayer("bob", 1, "US")
+	_ = storage.Current.PutPlayer(alice)
+	_ = storage.Current.PutPlayer(bob)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: start}
+	comp := playCompetition(t, []*model.Player{alice, bob}, 0, 0)
+
+	submissions := []struct {
+		playerId     string
+		submissionId string
+		expected     string
+	}{
+		{"alice", "s1", SubmissionApplied},
+		{"alice", "s1", SubmissionReplayed},
+		// Submission IDs are scoped to a player, and submissions without an ID are always applied
+		{"bob", "s1", SubmissionApplied},
+		{"alice", "", SubmissionApplied},
+		{"alice", "", SubmissionApplied},
+	}
+	for _, submission := range submissions {
+		resp, err := AddScore(submission.playerId, 10, submission.submissionId)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Status != submission.expected {
+			t.Errorf("expected %s to be %s, got %s", submission.submissionId, submission.expected, resp.Status)
+		}
+	}
+	if score := comp.PlayersMap()["alice"].Score(); score != 30 {
+		t.Errorf("expected alice to have 30 points, got %d", score)
+	}
+
+	// Submission IDs are forgotten after the TTL
+	timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: start.Add(config.SubmissionIdTTL)}
+	comp.(*model.Competition).SetEndsAt(start.Add(2 * config.SubmissionIdTTL))
+	if resp, _ := AddScore("alice", 10, "s1"); resp.Status != SubmissionApplied {
+		t.Errorf("expected the expired submission ID to be applied, got %s", resp.Status)
+	}
+
+	if _, err := AddScore("alice", 10, strings.Repeat("s", maxSubmissionIdLength+1)); err != ErrInvalidSubmissionId {
+		t.Errorf("expected ErrInvalidSubmissionId, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package leaderboard
+
+import (
+	"fmt"
+	"leaderboard/internal/config"
+	"leaderboard/internal/model"
+	"leaderboard/internal/storage"
+)
+
+var ErrInvalidBatch = fmt.Errorf("a batch must have between 1 and %d entries", config.MaxScoreBatchSize)
+
+// ScoreEntry is a score submission of a batch
+type ScoreEntry struct {
+	PlayerId     string `json:"player_id"`
+	Score        int    `json:"score"`
+	SubmissionId string `json:"submission_id,omitempty"`
+}
+
+type BatchScoreResponse struct {
+	// Results of the entries in the order they were submitted
+	Results []ScoreSubmissionResponse `json:"results"`
+}
+
+// ScoreResult is the response to an entry of a batch, and the error of a rejected entry
+type ScoreResult struct {
+	Response ScoreSubmissionResponse
+	Err      error
+}
+
+// AddScores submits a batch of scores like AddScore. The entries of each competition are validated and applied in order
+// locking the competition once, so the validators count the entries of a player applied earlier in the batch.
+// Returns the result of each entry in the order of the batch.
+var AddScores = func(entries []ScoreEntry) ([]ScoreResult, error) {
+	if len(entries) == 0 || len(entries) > config.MaxScoreBatchSize {
+		return nil, ErrInvalidBatch
+	}
+
+	results := make([]ScoreResult, len(entries))
+	// Entries of each competition, competitions in the order of their first entry
+	var comps []model.ICompetition
+	compEntries := make(map[model.ICompetition][]int)
+	for i, entry := range entries {
+		results[i].Response = ScoreSubmissionResponse{PlayerId: entry.PlayerId, SubmissionId: entry.SubmissionId, Status: SubmissionRejected}
+		comp, err := getScoringCompetition(entry.PlayerId, entry.SubmissionId)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		if _, found := compEntries[comp]; !found {
+			comps = append(comps, comp)
+		}
+		compEntries[comp] = append(compEntries[comp], i)
+	}
+
+	for _, comp := range comps {
+		indices := compEntries[comp]
+		submissions := make([]model.ScoreSubmission, 0, len(indices))
+		for _, i := range indices {
+			submissions = append(submissions, validatedSubmission(comp, entries[i].PlayerId, entries[i].Score, entries[i].SubmissionId, model.SourceBatch))
+		}
+		for j, result := range comp.SubmitScores(submissions) {
+			i := indices[j]
+			if result.Err != nil {
+				results[i].Err = result.Err
+			} else if !result.Applied {
+				results[i].Response.Status = SubmissionReplayed
+			} else if err := storage.Current.PutScore(comp.Id(), entries[i].PlayerId, result.Change); err != nil {
+				results[i].Err = err
+			} else {
+				results[i].Response.Status = SubmissionApplied
+			}
+		}
+	}
+	return results, nil
+}
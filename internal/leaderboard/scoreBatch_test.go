@@ -0,0 +1,78 @@
+package leaderboard
+
+import (
+	"leaderboard/internal/model"
+	"leaderboard/internal/storage"
+	"leaderboard/internal/timeprovider"
+	"testing"
+	"time"
+)
+
+func TestAddScores_AppliesEntriesPerCompetition(t *testing.T) {
+	defer setupAggregates(t)()
+	players := make(map[string]*model.Player)
+	for _, id := range []string{"alice", "bob", "carlos", "diana", "erin"} {
+		players[id] = model.NewPlayer(id, 1, "US")
+		_ = storage.Current.PutPlayer(players[id])
+	}
+	first := playCompetition(t, []*model.Player{players["alice"], players["bob"]}, 0, 0)
+	second := playCompetition(t, []*model.Player{players["carlos"], players["diana"]}, 0, 0)
+	// erin is waiting for a match
+	_ = model.NewCompetition(1).AddPlayer(players["erin"])
+
+	results, err := AddScores([]ScoreEntry{
+		{PlayerId: "alice", Score: 10, SubmissionId: "s1"},
+		{PlayerId: "carlos", Score: 5},
+		{PlayerId: "alice", Score: 10, SubmissionId: "s1"},
+		{PlayerId: "bob", Score: -1},
+		{PlayerId: "erin", Score: 5},
+		{PlayerId: "frank", Score: 5},
+		{PlayerId: "alice", Score: 20},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []struct {
+		status string
+		err    error
+	}{
+		{SubmissionApplied, nil},
+		{SubmissionApplied, nil},
+		{SubmissionReplayed, nil},
+		{SubmissionRejected, model.ErrPointsNegative},
+		{SubmissionRejected, ErrCompetitionNotStarted},
+		{SubmissionRejected, ErrPlayerNotFound},
+		{SubmissionApplied, nil},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(results))
+	}
+	for i, result := range results {
+		if result.Response.Status != expected[i].status || result.Err != expected[i].err {
+			t.Errorf("entry %d: expected %s with error %v, got %s with error %v",
+				i, expected[i].status, expected[i].err, result.Response.Status, result.Err)
+		}
+	}
+	if score := first.PlayersMap()["alice"].Score(); score != 30 {
+		t.Errorf("expected alice to have 30 points, got %d", score)
+	}
+	if score := second.PlayersMap()["carlos"].Score(); score != 5 {
+		t.Errorf("expected carlos to have 5 points, got %d", score)
+	}
+
+	// Entries of an ended competition are rejected
+	timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: first.EndsAt().Add(time.Second)}
+	results, _ = AddScores([]ScoreEntry{{PlayerId: "bob", Score: 5}})
+	if results[0].Err != ErrCompetitionEnded {
+		t.Errorf("expected ErrCompetitionEnded, got %v", results[0].Err)
+	}
+}
+
+func TestAddScores_InvalidBatch(t *testing.T) {
+	if _, err := AddScores(nil); err != ErrInvalidBatch {
+		t.Errorf("expected ErrInvalidBatch for an empty batch, got %v", err)
+	}
+	if _, err := AddScores(make([]ScoreEntry, 1001)); err != ErrInvalidBatch {
+		t.Errorf("expected ErrInvalidBatch for a batch too large, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package leaderboard
+
+import "time"
+
+// ScoreChangeResponse is an accepted score submission of a player, or a moderation of their score
+type ScoreChangeResponse struct {
+	// Order of the change in the competition, used to void a submission sent without an ID
+	Seq int       `json:"seq"`
+	At  time.Time `json:"at"`
+	// Points submitted
+	Points int `json:"points"`
+	// Change of the score once the scoring mode of the competition is applied, and the resulting score
+	Delta        int    `json:"delta"`
+	Score        int    `json:"score"`
+	Source       string `json:"source"`
+	SubmissionId string `json:"submission_id,omitempty"`
+	// Why a moderator changed the score, and whether they voided the submission
+	Reason string `json:"reason,omitempty"`
+	Voided bool   `json:"voided,omitempty"`
+	// Seq of the submission voided by a change with the void source
+	VoidedSeq int `json:"voided_seq,omitempty"`
+}
+
+type ScoreHistoryResponse struct {
+	LeaderboardId string                `json:"leaderboard_id"`
+	PlayerId      string                `json:"player_id"`
+	Score         int                   `json:"score"`
+	Disqualified  bool                  `json:"disqualified,omitempty"`
+	History       []ScoreChangeResponse `json:"history"`
+}
+
+// GetScoreHistory returns the accepted score submissions of a player in a competition, oldest first
+var GetScoreHistory = func(leaderboardId string, playerId string) (*ScoreHistoryResponse, error) {
+	if playerId == "" {
+		return nil, ErrPlayerIdEmpty
+	}
+	comp, err := getLeaderboardCompetition(leaderboardId)
+	if err != nil {
+		return nil, err
+	}
+	history, found := comp.History(playerId)
+	if !found {
+		return nil, ErrPlayerNotInLeaderboard
+	}
+
+	response := &ScoreHistoryResponse{
+		LeaderboardId: comp.Id(),
+		PlayerId:      playerId,
+		Score:         comp.PlayersMap()[playerId].Score(),
+		Disqualified:  comp.PlayersMap()[playerId].Disqualified(),
+		History:       make([]ScoreChangeResponse, 0, len(history)),
+	}
+	for _, change := range history {
+		response.History = append(response.History, ScoreChangeResponse{
+			Seq:          change.Seq,
+			At:           change.At,
+			Points:       change.Points,
+			Delta:        change.Delta,
+			Score:        change.Score,
+			Source:       change.Source,
+			SubmissionId: change.SubmissionId,
+			Reason:       change.Reason,
+			Voided:       change.Voided,
+			VoidedSeq:    change.VoidedSeq,
+		})
+	}
+	return response, nil
+}
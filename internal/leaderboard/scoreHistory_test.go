@@ -0,0 +1,52 @@
+package leaderboard
+
+import (
+	"leaderboard/internal/model"
+	"leaderboard/internal/storage"
+	"leaderboard/internal/timeprovider"
+	"testing"
+	"time"
+)
+
+func TestGetScoreHistory(t *testing.T) {
+	defer setupAggregates(t)()
+	alice := model.NewPlayer("alice", 1, "US")
+	bob := model.NewPlayer("bob", 1, "US")
+	_ = storage.Current.PutPlayer(alice)
+	_ = storage.Current.PutPlayer(bob)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: start}
+	comp := playCompetition(t, []*model.Player{alice, bob}, 0, 0)
+
+	timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: start.Add(time.Minute)}
+	_, _ = AddScore("alice", 10, "s1")
+	_, _ = AddScore("alice", 10, "s1")
+	_, _ = AddScores([]ScoreEntry{{PlayerId: "alice", Score: 5}, {PlayerId: "bob", Score: 7}})
+
+	resp, err := GetScoreHistory(comp.Id(), "alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The replayed submission is not in the history. playCompetition submitted 0 points first.
+	// The sequence numbers are those of the competition, bob submitted the second change
+	expected := []ScoreChangeResponse{
+		{Seq: 1, At: start, Points: 0, Delta: 0, Score: 0, Source: model.SourceApi},
+		{Seq: 3, At: start.Add(time.Minute), Points: 10, Delta: 10, Score: 10, Source: model.SourceApi, SubmissionId: "s1"},
+		{Seq: 4, At: start.Add(time.Minute), Points: 5, Delta: 5, Score: 15, Source: model.SourceBatch},
+	}
+	if resp.Score != 15 || len(resp.History) != len(expected) {
+		t.Fatalf("expected a score of 15 after %d changes, got %+v", len(expected), resp)
+	}
+	for i, change := range expected {
+		if !resp.History[i].At.Equal(change.At) || resp.History[i] != change {
+			t.Errorf("expected %+v, got %+v", change, resp.History[i])
+		}
+	}
+
+	if _, err := GetScoreHistory(comp.Id(), "carlos"); err != ErrPlayerNotInLeaderboard {
+		t.Errorf("expected ErrPlayerNotInLeaderboard, got %v", err)
+	}
+	if _, err := GetScoreHistory("missing", "alice"); err != ErrCompetetionNotFound {
+		t.Errorf("expected ErrCompetetionNotFound, got %v", err)
+	}
+}
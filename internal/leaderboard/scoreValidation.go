@@ -0,0 +1,206 @@
+package leaderboard
+
+import (
+	"errors"
+	"leaderboard/internal/config"
+	"leaderboard/internal/model"
+	"leaderboard/internal/timeprovider"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	ErrPointsAboveMaximum      = errors.New("points exceed the maximum points of a submission")
+	ErrPointsAboveLevelCeiling = errors.New("points exceed the maximum points of a submission for the level of the player")
+	ErrSubmissionRateExceeded  = errors.New("too many score submissions, try again later")
+	ErrPointsOutlier           = errors.New("points are too far above the submissions of the other players")
+)
+
+var (
+	scoresRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "leaderboard_scores_rejected_total",
+		Help: "The total number of score submissions rejected by the validators",
+	}, []string{"validator"})
+)
+
+// ScoreAttempt is a score submission checked by the validators before it is applied to a competition
+type ScoreAttempt struct {
+	Competition  model.ICompetition
+	Player       *model.CompetingPlayer
+	Points       int
+	SubmissionId string
+	Source       string
+	At           time.Time
+	// Points submitted by the other players of the competition
+	Others model.PointStats
+}
+
+// ScoreValidator rejects an implausible score submission by returning an error.
+// Validators run while the competition is locked, so they can read the player but must not call the competition.
+type ScoreValidator interface {
+	// Name identifies the validator in the flagged submissions and the metrics
+	Name() string
+	Validate(attempt ScoreAttempt) error
+}
+
+// ScoreValidators run in order on every score submission before it is applied, the first error rejects the submission
+var ScoreValidators = []ScoreValidator{maxPointsValidator{}, levelCeilingValidator{}, rateValidator{}, outlierValidator{}}
+
+// RejectedScoreError is returned for a score submission rejected by a validator
+type RejectedScoreError struct {
+	Validator string
+	Err       error
+}
+
+func (e *RejectedScoreError) Error() string {
+	return "score rejected: " + e.Err.Error()
+}
+
+func (e *RejectedScoreError) Unwrap() error {
+	return e.Err
+}
+
+// FlaggedSubmission is a score submission rejected by a validator, kept for review
+type FlaggedSubmission struct {
+	LeaderboardId string    `json:"leaderboard_id"`
+	PlayerId      string    `json:"player_id"`
+	Score         int       `json:"score"`
+	SubmissionId  string    `json:"submission_id,omitempty"`
+	Source        string    `json:"source"`
+	Validator     string    `json:"validator"`
+	Reason        string    `json:"reason"`
+	FlaggedAt     time.Time `json:"flagged_at"`
+}
+
+// Submissions rejected by the validators, oldest first. They are not persisted.
+var flagged = struct {
+	sync.Mutex
+	submissions []FlaggedSubmission
+}{}
+
+// GetFlaggedSubmissions returns the score submissions rejected by the validators, oldest first
+var GetFlaggedSubmissions = func() []FlaggedSubmission {
+	flagged.Lock()
+	defer flagged.Unlock()
+	return append([]FlaggedSubmission{}, flagged.submissions...)
+}
+
+// validatedSubmission returns a submission to a competition checked by the validators when it is applied,
+// so the checks and the submission are atomic. Submissions already applied are replayed without being validated.
+func validatedSubmission(comp model.ICompetition, playerId string, points int, submissionId, source string) model.ScoreSubmission {
+	return model.ScoreSubmission{
+		PlayerId:     playerId,
+		Points:       points,
+		SubmissionId: submissionId,
+		Source:       source,
+		Validate: func(player *model.CompetingPlayer, others model.PointStats) error {
+			return validateScore(ScoreAttempt{
+				Competition:  comp,
+				Player:       player,
+				Points:       points,
+				SubmissionId: submissionId,
+				Source:       source,
+				At:           timeprovider.Current.Now(),
+				Others:       others,
+			})
+		},
+	}
+}
+
+// validateScore runs the validators on a submission and flags it if one of them rejects it
+func validateScore(attempt ScoreAttempt) error {
+	for _, validator := range ScoreValidators {
+		if err := validator.Validate(attempt); err != nil {
+			flagSubmission(attempt, validator.Name(), err)
+			return &RejectedScoreError{Validator: validator.Name(), Err: err}
+		}
+	}
+	return nil
+}
+
+func flagSubmission(attempt ScoreAttempt, validator string, err error) {
+	scoresRejected.WithLabelValues(validator).Inc()
+	flagged.Lock()
+	defer flagged.Unlock()
+	if len(flagged.submissions) >= config.MaxFlaggedSubmissions {
+		flagged.submissions = flagged.submissions[len(flagged.submissions)-config.MaxFlaggedSubmissions+1:]
+	}
+	flagged.submissions = append(flagged.submissions, FlaggedSubmission{
+		LeaderboardId: attempt.Competition.Id(),
+		PlayerId:      attempt.Player.Player().Id(),
+		Score:         attempt.Points,
+		SubmissionId:  attempt.SubmissionId,
+		Source:        attempt.Source,
+		Validator:     validator,
+		Reason:        err.Error(),
+		FlaggedAt:     attempt.At,
+	})
+}
+
+// maxPointsValidator rejects submissions above config.MaxPointsPerSubmission
+type maxPointsValidator struct{}
+
+func (maxPointsValidator) Name() string {
+	return "max_points"
+}
+
+func (maxPointsValidator) Validate(attempt ScoreAttempt) error {
+	if attempt.Points > config.MaxPointsPerSubmission {
+		return ErrPointsAboveMaximum
+	}
+	return nil
+}
+
+// levelCeilingValidator rejects submissions above the ceiling of the level of the player in config.LevelPointCeilings
+type levelCeilingValidator struct{}
+
+func (levelCeilingValidator) Name() string {
+	return "level_ceiling"
+}
+
+func (levelCeilingValidator) Validate(attempt ScoreAttempt) error {
+	ceiling, found := config.LevelPointCeilings[attempt.Player.Level()]
+	if found && attempt.Points > ceiling {
+		return ErrPointsAboveLevelCeiling
+	}
+	return nil
+}
+
+// rateValidator rejects the submissions of a player who already made config.MaxScoresPerInterval submissions
+// in the last config.ScoreRateInterval
+type rateValidator struct{}
+
+func (rateValidator) Name() string {
+	return "rate"
+}
+
+func (rateValidator) Validate(attempt ScoreAttempt) error {
+	if attempt.Player.SubmittedSince(attempt.At.Add(-config.ScoreRateInterval)) >= config.MaxScoresPerInterval {
+		return ErrSubmissionRateExceeded
+	}
+	return nil
+}
+
+// outlierValidator rejects submissions more than config.OutlierDeviations standard deviations above the mean
+// of the points submitted by the other players of the competition, once they made config.OutlierMinSamples submissions.
+// Voided submissions and the changes made by moderators are not counted.
+type outlierValidator struct{}
+
+func (outlierValidator) Name() string {
+	return "outlier"
+}
+
+func (outlierValidator) Validate(attempt ScoreAttempt) error {
+	if attempt.Points <= 0 || attempt.Others.Count < config.OutlierMinSamples {
+		return nil
+	}
+	// Identical submissions still leave room for a point above them
+	deviation := max(attempt.Others.Deviation, 1)
+	if float64(attempt.Points) > attempt.Others.Mean+config.OutlierDeviations*deviation {
+		return ErrPointsOutlier
+	}
+	return nil
+}
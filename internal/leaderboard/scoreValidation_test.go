@@ -0,0 +1,165 @@
+package leaderboard
+
+import (
+	"errors"
+	"leaderboard/internal/config"
+	"leaderboard/internal/model"
+	"leaderboard/internal/storage"
+	"leaderboard/internal/timeprovider"
+	"sync"
+	"testing"
+	"time"
+)
+
+// setupValidation stores alice and bob of level 1 in a started competition and forgets the flagged submissions
+func setupValidation(t *testing.T) (model.ICompetition, func()) {
+	restore := setupAggregates(t)
+	flagged.submissions = nil
+	timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: time.Now()}
+	alice := model.NewPlayer("alice", 1, "US")
+	bob := model.NewPlayer("bob", 1, "GB")
+	_ = storage.Current.PutPlayer(alice)
+	_ = storage.Current.PutPlayer(bob)
+	comp := playCompetition(t, []*model.Player{alice, bob}, 0, 0)
+	// The submissions of playCompetition don't count towards the rate
+	timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: comp.StartedAt().Add(config.ScoreRateInterval)}
+	return comp, func() {
+		restore()
+		flagged.submissions = nil
+	}
+}
+
+func assertRejected(t *testing.T, err error, expected error) {
+	t.Helper()
+	var rejected *RejectedScoreError
+	if !errors.As(err, &rejected) || rejected.Err != expected {
+		t.Errorf("expected submission rejected with %v, got %v", expected, err)
+	}
+}
+
+func TestAddScore_RejectedByValidators(t *testing.T) {
+	defer func(maxPoints, maxScores, minSamples int) {
+		config.MaxPointsPerSubmission = maxPoints
+		config.MaxScoresPerInterval = maxScores
+		config.OutlierMinSamples = minSamples
+		config.LevelPointCeilings = map[int]int{}
+	}(config.MaxPointsPerSubmission, config.MaxScoresPerInterval, config.OutlierMinSamples)
+	config.MaxPointsPerSubmission = 100
+	config.LevelPointCeilings = map[int]int{1: 50}
+	config.MaxScoresPerInterval = 2
+	config.OutlierMinSamples = 3
+
+	t.Run("MaxPoints", func(t *testing.T) {
+		comp, restore := setupValidation(t)
+		defer restore()
+		_, err := AddScore("alice", 101, "s1")
+		assertRejected(t, err, ErrPointsAboveMaximum)
+		if score := comp.PlayersMap()["alice"].Score(); score != 0 {
+			t.Errorf("expected the rejected submission not to be applied, got score %d", score)
+		}
+
+		flags := GetFlaggedSubmissions()
+		if len(flags) != 1 || flags[0].PlayerId != "alice" || flags[0].Score != 101 || flags[0].SubmissionId != "s1" ||
+			flags[0].Validator != "max_points" || flags[0].LeaderboardId != comp.Id() {
+			t.Errorf("expected the submission of alice to be flagged by max_points, got %+v", flags)
+		}
+	})
+
+	t.Run("LevelCeiling", func(t *testing.T) {
+		_, restore := setupValidation(t)
+		defer restore()
+		_, err := AddScore("alice", 60, "")
+		assertRejected(t, err, ErrPointsAboveLevelCeiling)
+		if _, err := AddScore("alice", 50, ""); err != nil {
+			t.Errorf("expected the ceiling to be accepted, got %v", err)
+		}
+	})
+
+	t.Run("Rate", func(t *testing.T) {
+		_, restore := setupValidation(t)
+		defer restore()
+		_, _ = AddScore("alice", 10, "s1")
+		_, _ = AddScore("alice", 10, "s2")
+		_, err := AddScore("alice", 10, "s3")
+		assertRejected(t, err, ErrSubmissionRateExceeded)
+
+		// Retries are replayed rather than rejected
+		if response, err := AddScore("alice", 10, "s2"); err != nil || response.Status != SubmissionReplayed {
+			t.Errorf("expected the retry to be replayed, got %+v (%v)", response, err)
+		}
+		timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: timeprovider.Current.Now().Add(config.ScoreRateInterval)}
+		if _, err := AddScore("alice", 10, "s3"); err != nil {
+			t.Errorf("expected the submission to be accepted after the interval, got %v", err)
+		}
+	})
+
+	t.Run("Outlier", func(t *testing.T) {
+		comp, restore := setupValidation(t)
+		defer restore()
+		for _, points := range []int{10, 12, 8} {
+			_ = comp.AddScore("bob", points)
+		}
+		_, err := AddScore("alice", 45, "")
+		assertRejected(t, err, ErrPointsOutlier)
+		if _, err := AddScore("alice", 15, ""); err != nil {
+			t.Errorf("expected a submission close to the others to be accepted, got %v", err)
+		}
+	})
+
+	t.Run("OutlierIgnoresModerations", func(t *testing.T) {
+		comp, restore := setupValidation(t)
+		defer restore()
+		for _, points := range []int{10, 12, 8} {
+			_ = comp.AddScore("bob", points)
+		}
+		_, _ = comp.SubmitScore("bob", 40, "cheat")
+		_, _ = comp.VoidSubmission("bob", "cheat", "cheat")
+		_, _ = comp.AdjustScore("bob", 40, "bonus")
+		if _, err := AddScore("alice", 45, ""); err == nil {
+			t.Errorf("expected the voided submission and the adjustment not to raise the mean")
+		}
+	})
+}
+
+func TestAddScore_RateCheckedAtomically(t *testing.T) {
+	defer func(maxScores int) { config.MaxScoresPerInterval = maxScores }(config.MaxScoresPerInterval)
+	config.MaxScoresPerInterval = 5
+	comp, restore := setupValidation(t)
+	defer restore()
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = AddScore("alice", 1, "")
+		}()
+	}
+	wg.Wait()
+	if score := comp.PlayersMap()["alice"].Score(); score != config.MaxScoresPerInterval {
+		t.Errorf("expected %d submissions to be applied, got %d", config.MaxScoresPerInterval, score)
+	}
+}
+
+func TestAddScores_RateCountsEarlierEntries(t *testing.T) {
+	defer func(maxScores int) { config.MaxScoresPerInterval = maxScores }(config.MaxScoresPerInterval)
+	config.MaxScoresPerInterval = 2
+	comp, restore := setupValidation(t)
+	defer restore()
+
+	results, _ := AddScores([]ScoreEntry{
+		{PlayerId: "alice", Score: 10},
+		{PlayerId: "alice", Score: 10},
+		{PlayerId: "bob", Score: 10},
+		{PlayerId: "alice", Score: 10},
+	})
+	for i, expected := range []string{SubmissionApplied, SubmissionApplied, SubmissionApplied, SubmissionRejected} {
+		if results[i].Response.Status != expected {
+			t.Errorf("entry %d: expected %s, got %s (%v)", i, expected, results[i].Response.Status, results[i].Err)
+		}
+	}
+	assertRejected(t, results[3].Err, ErrSubmissionRateExceeded)
+	if score := comp.PlayersMap()["alice"].Score(); score != 20 {
+		t.Errorf("expected alice to have 20 points, got %d", score)
+	}
+}
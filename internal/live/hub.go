@@ -0,0 +1,259 @@
+package live
+
+import (
+	"errors"
+	"io"
+	"leaderboard/internal/config"
+	"leaderboard/internal/events"
+	"leaderboard/internal/leaderboard"
+	"leaderboard/internal/storage"
+	"leaderboard/internal/timeprovider"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+var (
+	ErrCompetitionNotFound = errors.New("competition not found")
+	ErrTooManySubscribers  = errors.New("too many subscribers for this competition")
+)
+
+// Types of the events pushed to the subscribers of a competition
+const (
+	// EventSnapshot has the leaderboard when a client connects, or after it missed events
+	EventSnapshot = "snapshot"
+	// EventRankChanged has the new score and rank of a player
+	EventRankChanged = "rank_changed"
+	// EventCompetitionStarted has the leaderboard when the competition starts
+	EventCompetitionStarted = "competition_started"
+	// EventCompetitionEnded has the final leaderboard, it is the last event sent to a client
+	EventCompetitionEnded = "competition_ended"
+)
+
+type Event struct {
+	Type          string    `json:"type"`
+	LeaderboardId string    `json:"leaderboard_id"`
+	At            time.Time `json:"at"`
+	// Leaderboard of the snapshot, competition_started and competition_ended events
+	Leaderboard *leaderboard.LeaderboardResponse `json:"leaderboard,omitempty"`
+	// Change of the rank_changed events
+	Change *RankChange `json:"change,omitempty"`
+}
+
+// RankChange is the score and rank of a player after a change of their score or of the ranking.
+// The rank is 0 when the player is removed from the ranking.
+type RankChange struct {
+	PlayerId     string `json:"player_id"`
+	Score        int    `json:"score"`
+	Rank         int    `json:"rank"`
+	PreviousRank int    `json:"previous_rank"`
+}
+
+// Subscriber receives the events of a competition
+type Subscriber struct {
+	leaderboardId string
+	events        chan Event
+	// Set when an event was dropped because the queue was full, the client is sent a new snapshot instead
+	lagging atomic.Bool
+	// Set when the competition_started event was dropped, the new snapshot is sent as a competition_started event
+	startMissed atomic.Bool
+	// Closed when the competition ends
+	ended chan struct{}
+}
+
+type topic struct {
+	leaderboardId string
+	subscribers   map[*Subscriber]bool
+}
+
+// Subscribers of each competition
+var hub = struct {
+	sync.Mutex
+	topics map[string]*topic
+}{topics: map[string]*topic{}}
+
+// Attach subscribes to the domain events to push the changes of the competitions to the subscribers.
+// Events are handed to the queues of the subscribers in the goroutine of the publisher, so the queue of a subscriber
+// is the only place where an event can be dropped. Returns a function that unsubscribes.
+func Attach() (detach func()) {
+	return events.Subscribe(push)
+}
+
+// Subscribe follows the events of a competition, up to config.MaxSubscribersPerCompetition subscribers per competition.
+// The subscriber must be served or unsubscribed.
+var Subscribe = func(leaderboardId string) (*Subscriber, error) {
+	comp, found := storage.Current.GetCompetition(leaderboardId)
+	if !found {
+		return nil, ErrCompetitionNotFound
+	}
+
+	hub.Lock()
+	t, found := hub.topics[leaderboardId]
+	if !found {
+		t = &topic{leaderboardId: leaderboardId, subscribers: map[*Subscriber]bool{}}
+		hub.topics[leaderboardId] = t
+	}
+	if len(t.subscribers) >= config.MaxSubscribersPerCompetition {
+		hub.Unlock()
+		return nil, ErrTooManySubscribers
+	}
+	subscriber := &Subscriber{
+		leaderboardId: leaderboardId,
+		events:        make(chan Event, config.SubscriberBufferSize),
+		ended:         make(chan struct{}),
+	}
+	t.subscribers[subscriber] = true
+	hub.Unlock()
+
+	// The competition is ended before the CompetitionEnded event is published, so either the event finds
+	// the subscriber or the subscriber finds the competition ended. The hub is not locked to check it,
+	// the event is published while the competition is locked.
+	if comp.Ended() {
+		hub.Lock()
+		if t, found := hub.topics[leaderboardId]; found && t.subscribers[subscriber] {
+			// The subscriber is sent the final leaderboard
+			t.end()
+		}
+		hub.Unlock()
+	}
+	return subscriber, nil
+}
+
+// Unsubscribe stops following the competition
+func (s *Subscriber) Unsubscribe() {
+	hub.Lock()
+	defer hub.Unlock()
+	t, found := hub.topics[s.leaderboardId]
+	if !found || !t.subscribers[s] {
+		return
+	}
+	delete(t.subscribers, s)
+	if len(t.subscribers) == 0 {
+		delete(hub.topics, s.leaderboardId)
+	}
+}
+
+// Serve sends a snapshot of the leaderboard to a client, then the events of the competition until it ends
+// or the client disconnects. A client that does not keep up with the events is sent a new snapshot instead.
+func (s *Subscriber) Serve(conn *websocket.Conn) {
+	defer s.Unsubscribe()
+	disconnected := make(chan struct{})
+	go func() {
+		// Clients don't send anything, reading detects when they disconnect
+		_, _ = io.Copy(io.Discard, conn)
+		close(disconnected)
+	}()
+
+	if !send(conn, newSnapshot(EventSnapshot, s.leaderboardId)) {
+		return
+	}
+	for {
+		select {
+		case event := <-s.events:
+			if s.lagging.Swap(false) {
+				started := s.drain() || event.Type == EventCompetitionStarted
+				if s.startMissed.Swap(false) || started {
+					event = newSnapshot(EventCompetitionStarted, s.leaderboardId)
+				} else {
+					event = newSnapshot(EventSnapshot, s.leaderboardId)
+				}
+			} else if event.Type == EventCompetitionStarted {
+				// The leaderboard is read here, the event is published while the competition is locked
+				event = newSnapshot(EventCompetitionStarted, s.leaderboardId)
+			}
+			if !send(conn, event) {
+				return
+			}
+		case <-s.ended:
+			s.drain()
+			send(conn, newSnapshot(EventCompetitionEnded, s.leaderboardId))
+			return
+		case <-disconnected:
+			return
+		}
+	}
+}
+
+// drain drops the queued events, and returns true if a competition_started event was among them
+func (s *Subscriber) drain() (started bool) {
+	for {
+		select {
+		case event := <-s.events:
+			started = started || event.Type == EventCompetitionStarted
+		default:
+			return started
+		}
+	}
+}
+
+func send(conn *websocket.Conn, event Event) bool {
+	if err := conn.SetWriteDeadline(time.Now().Add(config.SubscriberWriteTimeout)); err != nil {
+		return false
+	}
+	return websocket.JSON.Send(conn, event) == nil
+}
+
+// newSnapshot returns an event with the leaderboard of a competition
+func newSnapshot(eventType, leaderboardId string) Event {
+	event := Event{Type: eventType, LeaderboardId: leaderboardId, At: timeprovider.Current.Now()}
+	event.Leaderboard, _ = leaderboard.GetLeaderboard(leaderboardId, 0, config.MaxLeaderboardPageSize)
+	return event
+}
+
+// publish queues an event for the subscribers of a competition without blocking.
+// The subscribers that miss a competition_started event are sent one with their new snapshot.
+func publish(leaderboardId string, event Event) {
+	hub.Lock()
+	defer hub.Unlock()
+	t, found := hub.topics[leaderboardId]
+	if !found {
+		return
+	}
+	for subscriber := range t.subscribers {
+		select {
+		case subscriber.events <- event:
+		default:
+			if event.Type == EventCompetitionStarted {
+				subscriber.startMissed.Store(true)
+			}
+			subscriber.lagging.Store(true)
+		}
+	}
+}
+
+// push queues the events of a competition for its subscribers. It is called in the goroutine of the publisher,
+// which may hold the lock of the competition, so it must not read the competition.
+func push(event events.Event) {
+	switch e := event.(type) {
+	case events.CompetitionStarted:
+		// The leaderboard is added by the subscriber when it sends the event
+		publish(e.LeaderboardId, Event{Type: EventCompetitionStarted, LeaderboardId: e.LeaderboardId, At: e.StartedAt})
+	case events.ScoreAdded:
+		if e.Delta != 0 || e.Rank != e.PreviousRank {
+			publishChange(e.LeaderboardId, e.At, RankChange{PlayerId: e.PlayerId, Score: e.Score, Rank: e.Rank, PreviousRank: e.PreviousRank})
+		}
+	case events.PlayerDisqualified:
+		publishChange(e.LeaderboardId, e.At, RankChange{PlayerId: e.PlayerId, Score: e.Score, PreviousRank: e.PreviousRank})
+	case events.CompetitionEnded:
+		hub.Lock()
+		defer hub.Unlock()
+		if t, found := hub.topics[e.LeaderboardId]; found {
+			t.end()
+		}
+	}
+}
+
+func publishChange(leaderboardId string, at time.Time, change RankChange) {
+	publish(leaderboardId, Event{Type: EventRankChanged, LeaderboardId: leaderboardId, At: at, Change: &change})
+}
+
+// end closes the subscribers once the competition has ended, they are sent the final leaderboard.
+// The hub must be locked.
+func (t *topic) end() {
+	for subscriber := range t.subscribers {
+		close(subscriber.ended)
+	}
+	delete(hub.topics, t.leaderboardId)
+}
@@ -0,0 +1,207 @@
+package live
+
+import (
+	"leaderboard/internal/config"
+	"leaderboard/internal/events"
+	"leaderboard/internal/model"
+	"leaderboard/internal/storage"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// newCompetition stores alice and bob in a competition that has not started
+func newCompetition(t *testing.T) model.ICompetition {
+	t.Helper()
+	storage.Current = storage.NewMemoryStore()
+	comp := model.NewCompetition(1)
+	for _, id := range []string{"alice", "bob"} {
+		player := model.NewPlayer(id, 1, "US")
+		_ = storage.Current.PutPlayer(player)
+		_ = comp.AddPlayer(player)
+	}
+	_ = storage.Current.PutCompetition(comp)
+	return comp
+}
+
+// attach subscribes to the domain events for a test
+func attach(t *testing.T) {
+	detach := Attach()
+	t.Cleanup(func() {
+		detach()
+		storage.Current = storage.NewMemoryStore()
+	})
+}
+
+// dial connects a client served by the subscriber
+func dial(t *testing.T, subscriber *Subscriber) *websocket.Conn {
+	t.Helper()
+	server := httptest.NewServer(websocket.Server{Handler: subscriber.Serve})
+	t.Cleanup(server.Close)
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http"), "", server.URL)
+	if err != nil {
+		t.Fatalf("Dial() returned error %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func receive(t *testing.T, conn *websocket.Conn, eventType string) Event {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var event Event
+	if err := websocket.JSON.Receive(conn, &event); err != nil {
+		t.Fatalf("expected a %s event, got error %v", eventType, err)
+	}
+	if event.Type != eventType {
+		t.Fatalf("expected a %s event, got %+v", eventType, event)
+	}
+	return event
+}
+
+func TestSubscriber_PushesCompetitionEvents(t *testing.T) {
+	attach(t)
+	comp := newCompetition(t)
+
+	subscriber, err := Subscribe(comp.Id())
+	if err != nil {
+		t.Fatalf("Subscribe() returned error %v", err)
+	}
+	conn := dial(t, subscriber)
+	receive(t, conn, EventSnapshot)
+
+	if err := comp.Start(); err != nil {
+		t.Fatalf("Start() returned error %v", err)
+	}
+	if event := receive(t, conn, EventCompetitionStarted); event.Leaderboard == nil || event.Leaderboard.Total != 2 {
+		t.Errorf("expected the leaderboard of the 2 players, got %+v", event.Leaderboard)
+	}
+
+	_, _ = comp.SubmitScore("bob", 50, "s1")
+	event := receive(t, conn, EventRankChanged)
+	if *event.Change != (RankChange{PlayerId: "bob", Score: 50, Rank: 1, PreviousRank: 2}) {
+		t.Errorf("expected bob to move from rank 2 to 1, got %+v", event.Change)
+	}
+
+	events.Publish(events.CompetitionEnded{LeaderboardId: comp.Id()})
+	if event := receive(t, conn, EventCompetitionEnded); event.Leaderboard.Leaderboard[0].PlayerId != "bob" {
+		t.Errorf("expected bob to win, got %+v", event.Leaderboard)
+	}
+	var next Event
+	if err := websocket.JSON.Receive(conn, &next); err == nil {
+		t.Errorf("expected the connection to be closed, got %+v", next)
+	}
+}
+
+func TestSubscribe_CapsSubscribers(t *testing.T) {
+	attach(t)
+	defer func(max int) { config.MaxSubscribersPerCompetition = max }(config.MaxSubscribersPerCompetition)
+	config.MaxSubscribersPerCompetition = 1
+	comp := newCompetition(t)
+
+	if _, err := Subscribe("unknown"); err != ErrCompetitionNotFound {
+		t.Errorf("expected %v, got %v", ErrCompetitionNotFound, err)
+	}
+	subscriber, err := Subscribe(comp.Id())
+	if err != nil {
+		t.Fatalf("Subscribe() returned error %v", err)
+	}
+	if _, err := Subscribe(comp.Id()); err != ErrTooManySubscribers {
+		t.Errorf("expected %v, got %v", ErrTooManySubscribers, err)
+	}
+	subscriber.Unsubscribe()
+	if subscriber, err := Subscribe(comp.Id()); err != nil {
+		t.Errorf("expected a subscriber to fit after the first left, got %v", err)
+	} else {
+		subscriber.Unsubscribe()
+	}
+}
+
+func TestSubscriber_SendsSnapshotWhenLagging(t *testing.T) {
+	attach(t)
+	defer func(size int) { config.SubscriberBufferSize = size }(config.SubscriberBufferSize)
+	config.SubscriberBufferSize = 1
+	comp := newCompetition(t)
+	_ = comp.Start()
+
+	subscriber, err := Subscribe(comp.Id())
+	if err != nil {
+		t.Fatalf("Subscribe() returned error %v", err)
+	}
+	for i := range 3 {
+		_, _ = comp.SubmitScore("alice", 10, "s"+string(rune('1'+i)))
+	}
+	if !subscriber.lagging.Load() || len(subscriber.events) != 1 {
+		t.Fatalf("expected the events to be dropped once the queue is full, got %d queued", len(subscriber.events))
+	}
+
+	conn := dial(t, subscriber)
+	receive(t, conn, EventSnapshot)
+	if event := receive(t, conn, EventSnapshot); event.Leaderboard.Leaderboard[0].Score != 30 {
+		t.Errorf("expected a new snapshot with the score of alice, got %+v", event.Leaderboard)
+	}
+}
+
+func TestSubscriber_SendsCompetitionStartedWhenLagging(t *testing.T) {
+	attach(t)
+	defer func(size int) { config.SubscriberBufferSize = size }(config.SubscriberBufferSize)
+	config.SubscriberBufferSize = 1
+	comp := newCompetition(t)
+
+	subscriber, err := Subscribe(comp.Id())
+	if err != nil {
+		t.Fatalf("Subscribe() returned error %v", err)
+	}
+	// Fills the queue, so the competition_started event is dropped
+	publishChange(comp.Id(), time.Now(), RankChange{PlayerId: "alice"})
+	_ = comp.Start()
+	if !subscriber.startMissed.Load() {
+		t.Fatalf("expected the competition_started event to be dropped")
+	}
+
+	conn := dial(t, subscriber)
+	receive(t, conn, EventSnapshot)
+	if event := receive(t, conn, EventCompetitionStarted); event.Leaderboard == nil || event.Leaderboard.Total != 2 {
+		t.Errorf("expected the leaderboard of the 2 players, got %+v", event.Leaderboard)
+	}
+}
+
+func TestSubscriber_EndedCompetition(t *testing.T) {
+	attach(t)
+	comp := newCompetition(t)
+	_ = comp.Start()
+	_ = comp.End()
+
+	subscriber, err := Subscribe(comp.Id())
+	if err != nil {
+		t.Fatalf("Subscribe() returned error %v", err)
+	}
+	conn := dial(t, subscriber)
+	receive(t, conn, EventSnapshot)
+	receive(t, conn, EventCompetitionEnded)
+}
+
+func TestSubscribe_CompetitionPastEndsAt_WaitsForTheEnd(t *testing.T) {
+	attach(t)
+	comp := newCompetition(t)
+	_ = comp.Start()
+	comp.(*model.Competition).SetEndsAt(time.Now().Add(-time.Second))
+
+	subscriber, err := Subscribe(comp.Id())
+	if err != nil {
+		t.Fatalf("Subscribe() returned error %v", err)
+	}
+	select {
+	case <-subscriber.ended:
+		t.Fatalf("expected the subscriber to wait for the competition to be ended")
+	default:
+	}
+
+	conn := dial(t, subscriber)
+	receive(t, conn, EventSnapshot)
+	_ = comp.End()
+	receive(t, conn, EventCompetitionEnded)
+}
@@ -0,0 +1,136 @@
+package matchmaking
+
+import (
+	"leaderboard/internal/config"
+	"leaderboard/internal/storage"
+	"testing"
+	"time"
+)
+
+func setupCountryMode() {
+	SetStrategy(ModeCountry)
+	// Keep the wait timers from firing, tryStartCompetition is called directly
+	config.MatchWaitDuration = 1 * time.Hour
+	storage.AddPlayers([]storage.NewPlayer{
+		{Id: "us_2", CountryCode: "US", Level: 2},
+		{Id: "us_2_b", CountryCode: "US", Level: 2},
+		{Id: "ca_2", CountryCode: "CA", Level: 2},
+		{Id: "gb_2", CountryCode: "GB", Level: 2},
+		{Id: "us_3", CountryCode: "US", Level: 3},
+		{Id: "gb_3", CountryCode: "GB", Level: 3},
+		{Id: "gb_1", CountryCode: "GB", Level: 1},
+	})
+}
+
+func tearDownCountryMode() {
+	SetStrategy(ModeLevel)
+	config.MatchFallbackLadder = []string{FallbackRegion, FallbackLevel}
+	tearDown()
+}
+
+func joinForTest(t *testing.T, playerIds ...string) {
+	for _, playerId := range playerIds {
+		if _, err := JoinCompetition(playerId); err != nil {
+			t.Fatalf("unexpected error joining %s: %v", playerId, err)
+		}
+	}
+}
+
+func TestJoinCompetition_CountryMode_LobbiesGroupedByLevelAndCountry(t *testing.T) {
+	setupCountryMode()
+	defer tearDownCountryMode()
+
+	joinForTest(t, "us_2", "gb_2", "us_2_b")
+
+	us := waitingLobbies()[lobbyKey{level: 2, countryCode: "US"}]
+	gb := waitingLobbies()[lobbyKey{level: 2, countryCode: "GB"}]
+	if us == nil || gb == nil || us == gb {
+		t.Fatalf("expected separate US and GB lobbies at level 2, got %v", waitingLobbies())
+	}
+	if len(us.PlayersMap()) != 2 || us.PlayersMap()["us_2"] == nil || us.PlayersMap()["us_2_b"] == nil {
+		t.Errorf("expected US lobby to have us_2 and us_2_b, got %v", us.PlayersMap())
+	}
+	if len(gb.PlayersMap()) != 1 {
+		t.Errorf("expected GB lobby to have 1 player, got %d", len(gb.PlayersMap()))
+	}
+}
+
+func TestTryStartCompetition_CountryMode_FallsBackToSameRegion(t *testing.T) {
+	setupCountryMode()
+	defer tearDownCountryMode()
+
+	joinForTest(t, "gb_2", "ca_2", "us_2")
+	us2, _ := storage.Current.GetPlayer("us_2")
+	ca2, _ := storage.Current.GetPlayer("ca_2")
+	ownComp := us2.Competition()
+
+	if err := tryStartCompetition(us2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	comp := us2.Competition()
+	if comp == nil || comp != ca2.Competition() {
+		t.Fatalf("expected us_2 to be matched with ca_2 from the same region")
+	}
+	if comp.StartedAt().IsZero() {
+		t.Errorf("expected the matched competition to start")
+	}
+	if _, found := storage.Current.GetCompetition(ownComp.Id()); found {
+		t.Errorf("expected the empty US lobby to be discarded")
+	}
+	if waitingLobbies()[lobbyKey{level: 2, countryCode: "GB"}] == nil {
+		t.Errorf("expected the GB lobby to keep waiting")
+	}
+}
+
+func TestTryStartCompetition_CountryMode_FallsBackToAdjacentLevelPreferringSameCountry(t *testing.T) {
+	setupCountryMode()
+	defer tearDownCountryMode()
+
+	joinForTest(t, "gb_3", "us_3", "us_2")
+	us2, _ := storage.Current.GetPlayer("us_2")
+	us3, _ := storage.Current.GetPlayer("us_3")
+
+	if err := tryStartCompetition(us2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if us2.Competition() == nil || us2.Competition() != us3.Competition() {
+		t.Errorf("expected us_2 to be matched with us_3 at the adjacent level")
+	}
+	if waitingLobbies()[lobbyKey{level: 3, countryCode: "GB"}] == nil {
+		t.Errorf("expected the GB lobby at level 3 to keep waiting")
+	}
+}
+
+func TestTryStartCompetition_CountryMode_LadderWithoutRegionStep(t *testing.T) {
+	setupCountryMode()
+	defer tearDownCountryMode()
+	config.MatchFallbackLadder = []string{FallbackLevel}
+
+	joinForTest(t, "ca_2", "gb_1", "us_2")
+	us2, _ := storage.Current.GetPlayer("us_2")
+	gb1, _ := storage.Current.GetPlayer("gb_1")
+
+	if err := tryStartCompetition(us2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if us2.Competition() == nil || us2.Competition() != gb1.Competition() {
+		t.Errorf("expected us_2 to skip the same level region step and match gb_1 at the adjacent level")
+	}
+}
+
+func TestTryStartCompetition_LevelMode_IgnoresCountry(t *testing.T) {
+	setupCountryMode()
+	defer tearDownCountryMode()
+	SetStrategy(ModeLevel)
+
+	joinForTest(t, "us_2", "gb_2")
+	us2, _ := storage.Current.GetPlayer("us_2")
+	gb2, _ := storage.Current.GetPlayer("gb_2")
+
+	if us2.Competition() != gb2.Competition() {
+		t.Errorf("expected us_2 and gb_2 to share the level 2 lobby")
+	}
+}
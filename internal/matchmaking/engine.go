@@ -0,0 +1,148 @@
+package matchmaking
+
+import (
+	"errors"
+	"fmt"
+	"leaderboard/internal/config"
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Matchmaking engines, selected with config.MatchmakingEngine
+const (
+	// EngineMutex runs operations on the calling goroutine under a mutex and attempts on a supervised worker
+	EngineMutex = "mutex"
+	// EngineLoop runs operations and attempts as messages of a single event loop with a timing wheel
+	EngineLoop = "loop"
+)
+
+var ErrUnknownEngine = errors.New("unknown matchmaking engine")
+
+var (
+	matchmakingErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "leaderboard_matchmaking_errors_total",
+		Help: "The total number of failed attempts to start a competition",
+	})
+
+	// Engine running the matchmaking operations, the loop engine until SetEngine is called
+	engine matchmakingEngine
+)
+
+// The loop engine refers to the attempts, which refer to the engine, so it is created once the package is initialized
+func init() {
+	engine = newLoopEngine(config.MatchWheelTick, config.MatchWheelSlots)
+}
+
+// matchmakingEngine gives operations exclusive access to the matchmaking state and schedules the attempts
+// to start the competitions of waiting players
+type matchmakingEngine interface {
+	// exec runs an operation with exclusive access to the matchmaking state and waits for it to finish
+	exec(op func())
+	// scheduleAttempt runs the attempt of a waiting player with runAttempt after the delay.
+	// It is only called by operations run with exec.
+	scheduleAttempt(entry *queueEntry, delay time.Duration)
+	// cancelAttempt cancels the scheduled attempt of a player who is not waiting anymore.
+	// It is only called by operations run with exec.
+	cancelAttempt(entry *queueEntry)
+	// stop stops the goroutines of the engine
+	stop()
+}
+
+var engines = map[string]func() matchmakingEngine{
+	EngineMutex: func() matchmakingEngine { return newMutexEngine() },
+	EngineLoop:  func() matchmakingEngine { return newLoopEngine(config.MatchWheelTick, config.MatchWheelSlots) },
+}
+
+// SetEngine replaces the matchmaking engine and stops the previous one.
+// Attempts scheduled by the previous engine are dropped, so this should be called before players join.
+func SetEngine(name string) error {
+	newEngine, found := engines[name]
+	if !found {
+		return ErrUnknownEngine
+	}
+	previous := engine
+	engine = newEngine()
+	previous.stop()
+	return nil
+}
+
+// AttemptError reports a failed attempt to start the competition of a waiting player
+type AttemptError struct {
+	PlayerId      string
+	CompetitionId string
+	Attempt       int
+	Err           error
+}
+
+func (e *AttemptError) Error() string {
+	return fmt.Sprintf("attempt %d to start competition %q for player %s failed: %v", e.Attempt, e.CompetitionId, e.PlayerId, e.Err)
+}
+
+func (e *AttemptError) Unwrap() error {
+	return e.Err
+}
+
+// runAttempt tries to start the competition of a waiting player and records the result.
+// It is run by the engines with exclusive access to the matchmaking state.
+func runAttempt(entry *queueEntry) {
+	err := runRecovered(func() error {
+		return attemptStart(entry.player)
+	})
+	completeAttempt(entry, err)
+}
+
+// completeAttempt records the result of an attempt and schedules the next one if the player is still waiting.
+// A player is moved to the failed state after config.MatchMaxFailures consecutive failures,
+// or if a failed attempt has left them outside of a waiting competition.
+func completeAttempt(entry *queueEntry, err error) {
+	if entry.state != QueueStateWaiting {
+		if err != nil {
+			reportAttemptError(entry, err)
+		}
+		return
+	}
+	if err == nil {
+		entry.failures = 0
+		entry.lastError = ""
+		entry.scheduleAttempt(config.MatchRetryInterval)
+		return
+	}
+
+	reportAttemptError(entry, err)
+	entry.failures++
+	entry.lastError = err.Error()
+	comp := entry.player.Competition()
+	if entry.failures < config.MatchMaxFailures && comp != nil && comp.StartedAt().IsZero() {
+		entry.scheduleAttempt(config.MatchRetryInterval)
+		return
+	}
+	if err := leaveQueue(entry.player, QueueStateFailed); err != nil {
+		log.Printf("Failed to remove player %s from the queue: %v", entry.player.Id(), err)
+	}
+}
+
+func reportAttemptError(entry *queueEntry, err error) {
+	matchmakingErrors.Inc()
+	attemptErr := &AttemptError{
+		PlayerId: entry.player.Id(),
+		Attempt:  entry.attempts,
+		Err:      err,
+	}
+	if comp := entry.player.Competition(); comp != nil {
+		attemptErr.CompetitionId = comp.Id()
+	}
+	log.Printf("Matchmaking error: %v", attemptErr)
+}
+
+// runRecovered runs an attempt and turns a panic into an error
+func runRecovered(attempt func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return attempt()
+}
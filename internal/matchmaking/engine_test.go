@@ -0,0 +1,293 @@
+package matchmaking
+
+import (
+	"errors"
+	"fmt"
+	"leaderboard/internal/config"
+	"leaderboard/internal/model"
+	"leaderboard/internal/storage"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// panickingStrategy panics when a waiting player is not matched within the wait duration
+type panickingStrategy struct {
+	*levelStrategy
+}
+
+func (s *panickingStrategy) Tick(player *model.Player) []model.ICompetition {
+	panic("tick failed")
+}
+
+// failingDeleteStore fails to delete competitions
+type failingDeleteStore struct {
+	*storage.MemoryStore
+}
+
+func (s *failingDeleteStore) DeleteCompetition(id string) error {
+	return errors.New("delete failed")
+}
+
+func matchmakingErrorCount(t *testing.T) float64 {
+	t.Helper()
+	var metric dto.Metric
+	if err := matchmakingErrors.Write(&metric); err != nil {
+		t.Fatalf("failed to read the error counter: %v", err)
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func TestWorker_AttemptPanics_WorkerRecoversAndPlayerFails(t *testing.T) {
+	setup()
+	defer tearDown()
+	config.MatchWaitDuration = 10 * time.Millisecond
+	config.MatchRetryInterval = 10 * time.Millisecond
+	config.MatchMaxFailures = 2
+	engine.exec(func() {
+		strategy = &panickingStrategy{newLevelStrategy(false)}
+	})
+	errorsBefore := matchmakingErrorCount(t)
+
+	comp, err := JoinCompetition("alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	alice, _ := storage.Current.GetPlayer("alice")
+	time.Sleep(200 * time.Millisecond)
+
+	engine.exec(func() {
+		entry := queue["alice"]
+		if entry.state != QueueStateFailed || entry.failures != 2 || !strings.Contains(entry.lastError, "tick failed") {
+			t.Errorf("expected alice to fail after 2 panics, got %s after %d failures: %s", entry.state, entry.failures, entry.lastError)
+		}
+		if alice.Competition() != nil {
+			t.Errorf("expected alice to be removed from her competition")
+		}
+		if _, found := storage.Current.GetCompetition(comp.Id()); found {
+			t.Errorf("expected the empty competition to be discarded")
+		}
+		strategy = newLevelStrategy(false)
+	})
+	if errors := matchmakingErrorCount(t) - errorsBefore; errors != 2 {
+		t.Errorf("expected 2 matchmaking errors, got %v", errors)
+	}
+
+	// The worker keeps processing attempts after the panics
+	joinForTest(t, "bob", "bob_1")
+	bob, _ := storage.Current.GetPlayer("bob")
+	time.Sleep(100 * time.Millisecond)
+	engine.exec(func() {
+		if bob.Competition() == nil || bob.Competition().StartedAt().IsZero() {
+			t.Errorf("expected bob's competition to be started by the worker")
+		}
+	})
+}
+
+func TestRunAttempt_ErrorLeavesPlayerOutsideCompetition_PlayerFails(t *testing.T) {
+	setup()
+	defer tearDown()
+	config.MatchWaitDuration = 1 * time.Hour
+	storage.Current = &failingDeleteStore{storage.Current.(*storage.MemoryStore)}
+
+	joinForTest(t, "alice", "bob")
+
+	// Moving alice to bob's competition fails when her empty competition cannot be deleted
+	engine.exec(func() {
+		entry := queue["alice"]
+		runAttempt(entry)
+
+		if entry.state != QueueStateFailed || entry.failures != 1 || !strings.Contains(entry.lastError, "delete failed") {
+			t.Errorf("expected alice to fail after the first failure, got %s after %d failures: %s", entry.state, entry.failures, entry.lastError)
+		}
+		if queue["bob"].state != QueueStateWaiting {
+			t.Errorf("expected bob to keep waiting, got %s", queue["bob"].state)
+		}
+	})
+}
+
+func TestRunAttempt_ErrorBelowMaxFailures_PlayerKeepsWaiting(t *testing.T) {
+	setup()
+	defer tearDown()
+	config.MatchWaitDuration = 1 * time.Hour
+
+	joinForTest(t, "alice")
+	engine.exec(func() {
+		entry := queue["alice"]
+		strategy = &panickingStrategy{newLevelStrategy(false)}
+		runAttempt(entry)
+
+		if entry.state != QueueStateWaiting || entry.failures != 1 || !strings.Contains(entry.lastError, "tick failed") {
+			t.Errorf("expected alice to keep waiting after 1 failure, got %s after %d failures: %s", entry.state, entry.failures, entry.lastError)
+		}
+		if time.Until(entry.nextAttemptAt) > config.MatchRetryInterval {
+			t.Errorf("expected the next attempt to be scheduled after the retry interval")
+		}
+
+		strategy = newLevelStrategy(false)
+		runAttempt(entry)
+		if entry.failures != 0 || entry.lastError != "" {
+			t.Errorf("expected the failures to be reset after a successful attempt, got %d: %s", entry.failures, entry.lastError)
+		}
+	})
+}
+
+func TestLoopEngine_PlayersWaiting_CompetitionStartedAfterWaitDuration(t *testing.T) {
+	setup()
+	defer tearDown()
+	config.MatchWaitDuration = 20 * time.Millisecond
+	if err := SetEngine(EngineLoop); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	joinForTest(t, "alice", "bob")
+	alice, _ := storage.Current.GetPlayer("alice")
+	time.Sleep(200 * time.Millisecond)
+
+	var state string
+	var started bool
+	engine.exec(func() {
+		state = queue["alice"].state
+		started = alice.Competition() != nil && !alice.Competition().StartedAt().IsZero()
+	})
+	if state != QueueStateMatched || !started {
+		t.Errorf("expected alice to be matched by the event loop, got %s", state)
+	}
+}
+
+func TestLoopEngine_AttemptPanics_LoopKeepsRunning(t *testing.T) {
+	setup()
+	defer tearDown()
+	config.MatchWaitDuration = 10 * time.Millisecond
+	config.MatchRetryInterval = 10 * time.Millisecond
+	config.MatchMaxFailures = 2
+	if err := SetEngine(EngineLoop); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	engine.exec(func() {
+		strategy = &panickingStrategy{newLevelStrategy(false)}
+	})
+
+	joinForTest(t, "alice")
+	time.Sleep(200 * time.Millisecond)
+
+	var state string
+	engine.exec(func() {
+		state = queue["alice"].state
+		strategy = newLevelStrategy(false)
+	})
+	if state != QueueStateFailed {
+		t.Errorf("expected alice to fail after the panics, got %s", state)
+	}
+
+	// A panicking operation is raised in the caller and the loop keeps running
+	func() {
+		defer func() {
+			if r := recover(); r != "operation failed" {
+				t.Errorf("expected the panic of the operation, got %v", r)
+			}
+		}()
+		engine.exec(func() { panic("operation failed") })
+	}()
+	joinForTest(t, "bob")
+}
+
+func TestSetEngine_UnknownEngine_ReturnsError(t *testing.T) {
+	defer tearDown()
+	if err := SetEngine("unknown"); err != ErrUnknownEngine {
+		t.Errorf("expected %v, got %v", ErrUnknownEngine, err)
+	}
+}
+
+// goroutineEngine is the design before the engines: every waiting player has a goroutine sleeping until
+// the next attempt, which is run under the global mutex. It is only used as the baseline of the benchmarks.
+type goroutineEngine struct {
+	mutex sync.Mutex
+	quit  chan struct{}
+}
+
+func newGoroutineEngine() matchmakingEngine {
+	return &goroutineEngine{quit: make(chan struct{})}
+}
+
+func (e *goroutineEngine) exec(op func()) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	op()
+}
+
+func (e *goroutineEngine) scheduleAttempt(entry *queueEntry, delay time.Duration) {
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			e.exec(func() {
+				runAttempt(entry)
+			})
+		case <-entry.stop:
+		case <-e.quit:
+		}
+	}()
+}
+
+// cancelAttempt does nothing, the goroutine of the player returns when the entry is finished
+func (e *goroutineEngine) cancelAttempt(entry *queueEntry) {}
+
+func (e *goroutineEngine) stop() {
+	close(e.quit)
+}
+
+func BenchmarkJoinCompetition_Baseline(b *testing.B) {
+	benchmarkConcurrentJoins(b, newGoroutineEngine)
+}
+
+func BenchmarkJoinCompetition_Mutex(b *testing.B) {
+	benchmarkConcurrentJoins(b, engines[EngineMutex])
+}
+
+func BenchmarkJoinCompetition_Loop(b *testing.B) {
+	benchmarkConcurrentJoins(b, engines[EngineLoop])
+}
+
+// benchmarkConcurrentJoins measures the throughput of 100k players joining at the same time
+func benchmarkConcurrentJoins(b *testing.B, newEngine func() matchmakingEngine) {
+	const players = 100_000
+	defer tearDown()
+	config.MatchWaitDuration = 1 * time.Hour
+	newPlayers := make([]storage.NewPlayer, players)
+	for i := range newPlayers {
+		newPlayers[i] = storage.NewPlayer{Id: fmt.Sprintf("player_%d", i), CountryCode: "US", Level: i%config.MaxLevel + 1}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		resetQueue()
+		orderedCompetitions = make([]model.ICompetition, 0)
+		SetStrategy(ModeLevel)
+		storage.Current = storage.NewMemoryStore()
+		storage.AddPlayers(newPlayers)
+		previous := engine
+		engine = newEngine()
+		previous.stop()
+		var wg sync.WaitGroup
+		wg.Add(players)
+		b.StartTimer()
+
+		for _, player := range newPlayers {
+			go func() {
+				defer wg.Done()
+				if _, err := JoinCompetition(player.Id); err != nil {
+					b.Errorf("unexpected error: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(b.N*players)/b.Elapsed().Seconds(), "joins/s")
+}
@@ -10,12 +10,12 @@ import (
 
 func tearDownEnsureMaxCompetitionsInMemory() {
 	config.MaxCompetitionsInMemory = 100
+	resetQueue()
 	orderedCompetitions = make([]model.ICompetition, 0)
 
-	clear(waitingCompetitions)
+	SetStrategy(ModeLevel)
 	clear(orderedCompetitions)
-	clear(storage.Players)
-	clear(storage.Competitions)
+	storage.Current = storage.NewMemoryStore()
 }
 
 func TestEnsureMaxCompetitionsInMemory_RemovesOldEndedCompetitions(t *testing.T) {
@@ -27,7 +27,7 @@ func TestEnsureMaxCompetitionsInMemory_RemovesOldEndedCompetitions(t *testing.T)
 	now := time.Now()
 	for i := 0; i < 4; i++ {
 		comp := model.NewCompetition(1).(*model.Competition)
-		storage.Competitions[comp.Id()] = comp
+		storage.Current.PutCompetition(comp)
 		orderedCompetitions = append(orderedCompetitions, comp)
 		// Simulate started and ended competitions for first 3
 		if i < 3 {
@@ -44,7 +44,7 @@ func TestEnsureMaxCompetitionsInMemory_RemovesOldEndedCompetitions(t *testing.T)
 	}
 	// The competitions in storage should match the ones in orderedCompetitions
 	for _, comp := range orderedCompetitions {
-		if _, ok := storage.Competitions[comp.Id()]; !ok {
+		if _, ok := storage.Current.GetCompetition(comp.Id()); !ok {
 			t.Errorf("competition %s should remain in storage", comp.Id())
 		}
 	}
@@ -60,28 +60,28 @@ func TestEnsureMaxCompetitionsInMemory_DoesNotRemoveOngoingCompetitions(t *testi
 	compEnded := model.NewCompetition(1).(*model.Competition)
 	compEnded.SetStartedAt(time.Now().Add(-2 * time.Minute))
 	compEnded.SetEndsAt(time.Now().Add(-1 * time.Minute))
-	storage.Competitions[compEnded.Id()] = compEnded
+	storage.Current.PutCompetition(compEnded)
 
 	compOngoing := model.NewCompetition(1).(*model.Competition)
 	compEnded.SetStartedAt(time.Now().Add(-2 * time.Minute))
 	compOngoing.SetEndsAt(time.Now().Add(10 * time.Minute))
-	storage.Competitions[compOngoing.Id()] = compOngoing
+	storage.Current.PutCompetition(compOngoing)
 
 	compOngoing2 := model.NewCompetition(1).(*model.Competition)
 	compEnded.SetStartedAt(time.Now().Add(-2 * time.Minute))
 	compOngoing2.SetEndsAt(time.Now().Add(5 * time.Minute))
-	storage.Competitions[compOngoing2.Id()] = compOngoing2
+	storage.Current.PutCompetition(compOngoing2)
 
 	orderedCompetitions = append(orderedCompetitions, compEnded, compOngoing, compOngoing2)
 
 	ensureMaxCompetitionsInMemory()
 
 	// Ongoing competition should not be removed
-	if _, ok := storage.Competitions[compOngoing.Id()]; !ok {
+	if _, ok := storage.Current.GetCompetition(compOngoing.Id()); !ok {
 		t.Errorf("ongoing competition should not be removed from storage")
 	}
 	// Ended competition should be removed
-	if _, ok := storage.Competitions[compEnded.Id()]; ok {
+	if _, ok := storage.Current.GetCompetition(compEnded.Id()); ok {
 		t.Errorf("ended competition should be removed from storage")
 	}
 	// Only ongoing competition should remain in orderedCompetitions
@@ -103,7 +103,7 @@ func TestEnsureMaxCompetitionsInMemory_NoRemovalIfBelowLimit(t *testing.T) {
 		comp := model.NewCompetition(1).(*model.Competition)
 		comp.SetEndsAt(time.Now().Add(-time.Duration(i+2) * time.Minute))
 		comp.SetEndsAt(time.Now().Add(-time.Duration(i+1) * time.Minute))
-		storage.Competitions[comp.Id()] = comp
+		storage.Current.PutCompetition(comp)
 		orderedCompetitions = append(orderedCompetitions, comp)
 	}
 
@@ -114,7 +114,7 @@ func TestEnsureMaxCompetitionsInMemory_NoRemovalIfBelowLimit(t *testing.T) {
 		t.Errorf("expected 3 competitions in memory, got %d", len(orderedCompetitions))
 	}
 	for _, comp := range orderedCompetitions {
-		if _, ok := storage.Competitions[comp.Id()]; !ok {
+		if _, ok := storage.Current.GetCompetition(comp.Id()); !ok {
 			t.Errorf("competition %s should remain in storage", comp.Id())
 		}
 	}
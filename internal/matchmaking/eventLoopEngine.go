@@ -0,0 +1,82 @@
+package matchmaking
+
+import (
+	"errors"
+	"time"
+)
+
+var errEngineStopped = errors.New("matchmaking engine stopped")
+
+// loopEngine runs every operation and attempt on a single goroutine, so the matchmaking state needs no lock.
+// Operations are sent as messages and attempts are scheduled on a timing wheel advanced by a ticker.
+type loopEngine struct {
+	ops   chan loopOp
+	wheel *timingWheel
+	quit  chan struct{}
+}
+
+// loopOp is an operation sent to the event loop. The loop sends the panic value, or nil, to done when it finishes.
+type loopOp struct {
+	op   func()
+	done chan any
+}
+
+func newLoopEngine(tick time.Duration, slots int) *loopEngine {
+	e := &loopEngine{
+		ops:   make(chan loopOp),
+		wheel: newTimingWheel(tick, slots, time.Now()),
+		quit:  make(chan struct{}),
+	}
+	go e.run(tick)
+	return e
+}
+
+// exec sends an operation to the event loop and waits for it. A panic of the operation is raised again in the caller.
+// It must not be called by an operation, which would wait for itself.
+func (e *loopEngine) exec(op func()) {
+	done := make(chan any, 1)
+	select {
+	case e.ops <- loopOp{op: op, done: done}:
+	case <-e.quit:
+		panic(errEngineStopped)
+	}
+	if r := <-done; r != nil {
+		panic(r)
+	}
+}
+
+func (e *loopEngine) scheduleAttempt(entry *queueEntry, delay time.Duration) {
+	e.wheel.add(entry, time.Now(), delay)
+}
+
+func (e *loopEngine) cancelAttempt(entry *queueEntry) {
+	e.wheel.remove(entry)
+}
+
+func (e *loopEngine) stop() {
+	close(e.quit)
+}
+
+func (e *loopEngine) run(tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case op := <-e.ops:
+			runOp(op)
+		case now := <-ticker.C:
+			for _, entry := range e.wheel.advance(now) {
+				runAttempt(entry)
+			}
+		case <-e.quit:
+			return
+		}
+	}
+}
+
+func runOp(op loopOp) {
+	defer func() {
+		op.done <- recover()
+	}()
+	op.op()
+}
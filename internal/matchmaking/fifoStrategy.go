@@ -0,0 +1,52 @@
+package matchmaking
+
+import (
+	"leaderboard/internal/config"
+	"leaderboard/internal/model"
+)
+
+// fifoStrategy pools all players in a single waiting competition in arrival order, regardless of level or country.
+// The competition starts when it is full, or after the wait duration if it has enough players.
+type fifoStrategy struct {
+	lobby model.ICompetition
+}
+
+func (s *fifoStrategy) Enqueue(player *model.Player) (model.ICompetition, error) {
+	if s.lobby != nil {
+		if err := s.lobby.AddPlayer(player); err != nil {
+			return nil, err
+		}
+		return s.lobby, nil
+	}
+
+	comp, err := createNewCompetition(player)
+	if err != nil {
+		return nil, err
+	}
+	s.lobby = comp
+	return comp, nil
+}
+
+// Tick never matches, the player's own competition is the only one waiting
+func (s *fifoStrategy) Tick(player *model.Player) []model.ICompetition {
+	return nil
+}
+
+func (s *fifoStrategy) Dequeue(comp model.ICompetition) {
+	if s.lobby == comp {
+		s.lobby = nil
+	}
+}
+
+func (s *fifoStrategy) Cancel(player *model.Player) {}
+
+// LevelWindow is all levels, players are not grouped by level
+func (s *fifoStrategy) LevelWindow(player *model.Player, attempts int) (int, int) {
+	return config.MinLevel, config.MaxLevel
+}
+
+func (s *fifoStrategy) Requeue(comp model.ICompetition) {
+	if s.lobby == nil {
+		s.lobby = comp
+	}
+}
@@ -0,0 +1,75 @@
+package matchmaking
+
+import (
+	"leaderboard/internal/config"
+	"leaderboard/internal/leaderboard"
+	"leaderboard/internal/model"
+	"leaderboard/internal/storage"
+	"leaderboard/internal/timeprovider"
+	"log"
+	"slices"
+	"time"
+)
+
+// Started competitions waiting to be finalized, ordered by end time
+var pendingFinalization = make([]model.ICompetition, 0, config.MaxCompetitionsInMemory)
+
+// StartFinalizer finalizes the competitions past their end time every interval until it is stopped.
+// The end times are compared with timeprovider.Current. It is called once on startup, after Restore.
+func StartFinalizer(interval time.Duration) (stop func()) {
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				FinalizeEndedCompetitions()
+			case <-quit:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(quit)
+		<-done
+	}
+}
+
+// FinalizeEndedCompetitions ends the started competitions whose end time has passed: their scores are frozen,
+// their players can join another competition and their results are recorded for the aggregate leaderboards
+func FinalizeEndedCompetitions() {
+	engine.exec(finalizeEndedCompetitions)
+}
+
+func finalizeEndedCompetitions() {
+	now := timeprovider.Current.Now()
+	for len(pendingFinalization) > 0 && pendingFinalization[0].EndsAt().Before(now) {
+		comp := pendingFinalization[0]
+		pendingFinalization = pendingFinalization[1:]
+		finalize(comp)
+	}
+}
+
+// scheduleFinalization adds a started competition to the competitions finalized at their end time
+func scheduleFinalization(comp model.ICompetition) {
+	index, _ := slices.BinarySearchFunc(pendingFinalization, comp.EndsAt(), func(pending model.ICompetition, endsAt time.Time) int {
+		return pending.EndsAt().Compare(endsAt)
+	})
+	pendingFinalization = slices.Insert(pendingFinalization, index, comp)
+}
+
+func finalize(comp model.ICompetition) {
+	if err := comp.End(); err != nil {
+		log.Printf("Failed to end competition %s: %v", comp.Id(), err)
+		return
+	}
+	if err := leaderboard.RecordResults(comp); err != nil {
+		log.Printf("Failed to record the results of competition %s: %v", comp.Id(), err)
+	}
+	if err := storage.Current.PutCompetition(comp); err != nil {
+		log.Printf("Failed to store the ended competition %s: %v", comp.Id(), err)
+	}
+}
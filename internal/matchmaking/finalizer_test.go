@@ -0,0 +1,94 @@
+package matchmaking
+
+import (
+	"leaderboard/internal/config"
+	"leaderboard/internal/model"
+	"leaderboard/internal/storage"
+	"leaderboard/internal/timeprovider"
+	"testing"
+	"time"
+)
+
+// startForTest starts a competition with alice and alice_1 at the given time
+func startForTest(t *testing.T, now time.Time) model.ICompetition {
+	config.MaxPlayersForCompetition = 2
+	timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: now}
+	joinForTest(t, "alice")
+	comp, err := JoinCompetition("alice_1")
+	if err != nil || comp.StartedAt().IsZero() {
+		t.Fatalf("expected the competition to start")
+	}
+	return comp
+}
+
+func TestFinalizeEndedCompetitions_AtEndTime(t *testing.T) {
+	setup()
+	defer tearDown()
+	defer func() { timeprovider.Current = timeprovider.RealTimeProvider{} }()
+	now := time.Now()
+	comp := startForTest(t, now)
+	_ = comp.AddScore("alice_1", 10)
+
+	timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: comp.EndsAt()}
+	FinalizeEndedCompetitions()
+	if comp.Ended() {
+		t.Fatalf("expected the competition not to be finalized before its end time")
+	}
+
+	timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: comp.EndsAt().Add(time.Second)}
+	FinalizeEndedCompetitions()
+
+	if !comp.Ended() {
+		t.Fatalf("expected the competition to be finalized after its end time")
+	}
+	if err := comp.AddScore("alice", 5); err != model.ErrCompetitionEnded {
+		t.Errorf("expected the scores to be frozen, got %v", err)
+	}
+	for _, playerId := range []string{"alice", "alice_1"} {
+		if player, _ := storage.Current.GetPlayer(playerId); player.Competition() != nil {
+			t.Errorf("expected %s to be unlinked from the ended competition", playerId)
+		}
+	}
+	results := storage.Current.ListResults()
+	if len(results) != 2 || results[0].PlayerId != "alice_1" || results[0].Rank != 1 || results[0].Score != 10 {
+		t.Errorf("expected the results to be recorded with alice_1 first, got %+v", results)
+	}
+	if stored, _ := storage.Current.GetCompetition(comp.Id()); !stored.Ended() {
+		t.Errorf("expected the ended competition to be stored")
+	}
+}
+
+func TestRestore_FinalizesCompetitionsEndedWhileDown(t *testing.T) {
+	setup()
+	defer tearDown()
+	defer func() { timeprovider.Current = timeprovider.RealTimeProvider{} }()
+	now := time.Now()
+	comp := startForTest(t, now)
+	timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: comp.EndsAt().Add(time.Hour)}
+	pendingFinalization = pendingFinalization[:0]
+
+	Restore()
+	FinalizeEndedCompetitions()
+
+	if !comp.Ended() {
+		t.Errorf("expected the restored competition to be finalized")
+	}
+}
+
+func TestStartFinalizer(t *testing.T) {
+	setup()
+	defer tearDown()
+	defer func() { timeprovider.Current = timeprovider.RealTimeProvider{} }()
+	comp := startForTest(t, time.Now())
+	// Fast-forward to the end of the competition
+	timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: comp.EndsAt().Add(time.Second)}
+
+	stop := StartFinalizer(10 * time.Millisecond)
+	defer stop()
+	for deadline := time.Now().Add(5 * time.Second); !comp.Ended() && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !comp.Ended() {
+		t.Errorf("expected the finalizer to end the competition")
+	}
+}
@@ -0,0 +1,202 @@
+package matchmaking
+
+import (
+	"leaderboard/internal/config"
+	"leaderboard/internal/model"
+	"slices"
+	"strings"
+)
+
+// Fallback ladder steps, tried in the configured order when no match is found within the wait duration
+const (
+	// FallbackRegion matches with players of the same level from other countries of the same region
+	FallbackRegion = "region"
+	// FallbackLevel matches with players at the closest levels, preferring the same country and region
+	FallbackLevel = "level"
+)
+
+// levelStrategy keeps a single waiting competition per level, or per level and country code.
+// Players who are not matched within the wait duration follow config.MatchFallbackLadder.
+type levelStrategy struct {
+	byCountry bool
+	lobbies   map[lobbyKey]model.ICompetition
+}
+
+// lobbyKey identifies a waiting competition
+type lobbyKey struct {
+	level       int
+	countryCode string // Empty unless matchmaking by country
+}
+
+func newLevelStrategy(byCountry bool) *levelStrategy {
+	return &levelStrategy{
+		byCountry: byCountry,
+		lobbies:   make(map[lobbyKey]model.ICompetition),
+	}
+}
+
+func (s *levelStrategy) Enqueue(player *model.Player) (model.ICompetition, error) {
+	key := s.keyFor(player)
+	if comp, found := s.lobbies[key]; found {
+		if err := comp.AddPlayer(player); err != nil {
+			return nil, err
+		}
+		return comp, nil
+	}
+
+	comp, err := createNewCompetition(player)
+	if err != nil {
+		return nil, err
+	}
+	s.lobbies[key] = comp
+	return comp, nil
+}
+
+func (s *levelStrategy) Tick(player *model.Player) []model.ICompetition {
+	return s.findFallbackCompetitions(player, player.Competition())
+}
+
+func (s *levelStrategy) Dequeue(comp model.ICompetition) {
+	for key, waitingComp := range s.lobbies {
+		if waitingComp == comp {
+			delete(s.lobbies, key)
+			return
+		}
+	}
+}
+
+func (s *levelStrategy) Cancel(player *model.Player) {}
+
+func (s *levelStrategy) Requeue(comp model.ICompetition) {
+	// All players of a waiting competition share the same lobby key
+	for _, compPlayer := range comp.PlayersMap() {
+		s.lobbies[s.keyFor(compPlayer.Player())] = comp
+		return
+	}
+}
+
+// LevelWindow is the level of the player until the first attempt, then all levels if the ladder has the level step
+func (s *levelStrategy) LevelWindow(player *model.Player, attempts int) (int, int) {
+	if attempts > 0 && slices.Contains(config.MatchFallbackLadder, FallbackLevel) {
+		return config.MinLevel, config.MaxLevel
+	}
+	return player.Level(), player.Level()
+}
+
+func (s *levelStrategy) keyFor(player *model.Player) lobbyKey {
+	if s.byCountry {
+		return lobbyKey{level: player.Level(), countryCode: player.CountryCode()}
+	}
+	return lobbyKey{level: player.Level()}
+}
+
+func regionOf(countryCode string) string {
+	return config.CountryRegions[countryCode]
+}
+
+// findFallbackCompetitions finds the waiting competitions for a player who was not matched within the wait duration,
+// following the steps of the fallback ladder
+func (s *levelStrategy) findFallbackCompetitions(player *model.Player, own model.ICompetition) []model.ICompetition {
+	var comps []model.ICompetition
+	for _, step := range config.MatchFallbackLadder {
+		var stepComps []model.ICompetition
+		switch step {
+		case FallbackRegion:
+			stepComps = s.findRegionCompetitions(player, own)
+		case FallbackLevel:
+			stepComps = s.findAdjacentLevelCompetitions(player, own)
+		}
+		for _, comp := range stepComps {
+			if !slices.Contains(comps, comp) {
+				comps = append(comps, comp)
+			}
+		}
+	}
+	return comps
+}
+
+func (s *levelStrategy) findRegionCompetitions(player *model.Player, own model.ICompetition) []model.ICompetition {
+	region := regionOf(player.CountryCode())
+	if !s.byCountry || region == "" {
+		return nil
+	}
+
+	var lobbies []rankedLobby
+	for key, comp := range s.lobbies {
+		if comp == own || key.level != player.Level() || regionOf(key.countryCode) != region {
+			continue
+		}
+		lobbies = append(lobbies, rankedLobby{key: key, comp: comp})
+	}
+	return sortLobbies(lobbies)
+}
+
+func (s *levelStrategy) findAdjacentLevelCompetitions(player *model.Player, own model.ICompetition) []model.ICompetition {
+	var comps []model.ICompetition
+	for i := 1; ; i++ {
+		// Try finding matching competitions at closest levels
+		higherLevel := player.Level() + i
+		lowerLevel := player.Level() - i
+
+		// Check if we have competitions waiting for a player at the higher or lower level
+		if higherLevel <= config.MaxLevel {
+			comps = append(comps, s.findCompetitionsAtLevel(player, own, higherLevel)...)
+		}
+		if lowerLevel >= config.MinLevel {
+			comps = append(comps, s.findCompetitionsAtLevel(player, own, lowerLevel)...)
+		}
+		if higherLevel >= config.MaxLevel && lowerLevel <= config.MinLevel {
+			return comps
+		}
+	}
+}
+
+// findCompetitionsAtLevel prefers competitions of the same country, then of the same region, then any other
+func (s *levelStrategy) findCompetitionsAtLevel(player *model.Player, own model.ICompetition, level int) []model.ICompetition {
+	if !s.byCountry {
+		if comp, found := s.lobbies[lobbyKey{level: level}]; found && comp != own {
+			return []model.ICompetition{comp}
+		}
+		return nil
+	}
+
+	region := regionOf(player.CountryCode())
+	var lobbies []rankedLobby
+	for key, comp := range s.lobbies {
+		if comp == own || key.level != level {
+			continue
+		}
+		rank := 2
+		if key.countryCode == player.CountryCode() {
+			rank = 0
+		} else if region != "" && regionOf(key.countryCode) == region {
+			rank = 1
+		}
+		lobbies = append(lobbies, rankedLobby{key: key, comp: comp, rank: rank})
+	}
+	return sortLobbies(lobbies)
+}
+
+type rankedLobby struct {
+	key  lobbyKey
+	comp model.ICompetition
+	rank int // Lower is better
+}
+
+// sortLobbies orders lobbies by rank, then by the number of players, then by country code so the order is deterministic
+func sortLobbies(lobbies []rankedLobby) []model.ICompetition {
+	slices.SortFunc(lobbies, func(a, b rankedLobby) int {
+		if a.rank != b.rank {
+			return a.rank - b.rank
+		}
+		if len(a.comp.PlayersMap()) != len(b.comp.PlayersMap()) {
+			return len(b.comp.PlayersMap()) - len(a.comp.PlayersMap())
+		}
+		return strings.Compare(a.key.countryCode, b.key.countryCode)
+	})
+	comps := make([]model.ICompetition, 0, len(lobbies))
+	for _, lobby := range lobbies {
+		comps = append(comps, lobby.comp)
+	}
+	return comps
+}
@@ -3,188 +3,334 @@ package matchmaking
 import (
 	"errors"
 	"leaderboard/internal/config"
+	"leaderboard/internal/events"
+	"leaderboard/internal/leaderboard"
 	"leaderboard/internal/model"
 	"leaderboard/internal/storage"
 	"leaderboard/internal/timeprovider"
-	"sync"
-	"time"
+	"log"
+	"slices"
 )
 
 var (
 	ErrPlayerIdEmpty              = errors.New("player ID cannot be empty")
 	ErrPlayerNotFound             = errors.New("player not found")
 	ErrPlayerAlreadyInCompetition = errors.New("player is already in a competition")
+	ErrPlayerWaitingForMatch      = errors.New("player is waiting for a match")
+	ErrPlayerNotWaiting           = errors.New("player is not waiting for a match")
+	ErrPlayerNil                  = errors.New("player must be provided")
+	ErrPlayerBanned               = errors.New("player is banned from competitions")
 )
 var (
-	// This mutex synchronizes the access to the waiting players and competitions maps
-	// Also start competition is accessed only by one goroutine at a time using this
-	mutex = &sync.Mutex{}
-
-	// Maps to hold players and competitions waiting for a match
-	waitingCompetitions = make(map[int]model.ICompetition)
+	// Strategy holding the competitions waiting for a match
+	strategy MatchmakingStrategy = newLevelStrategy(false)
+	// Mode of the strategy, selecting the tie-breaker and scoring mode of new competitions
+	strategyMode = ModeLevel
 	// Slice to hold the competitions in the order they are created
 	orderedCompetitions = make([]model.ICompetition, 0, config.MaxCompetitionsInMemory)
 )
 
-var JoinCompetition = func(playerID string) (model.ICompetition, error) {
+var JoinCompetition = func(playerID string) (comp model.ICompetition, err error) {
 	if playerID == "" {
 		return nil, ErrPlayerIdEmpty
 	}
-	mutex.Lock()
-	defer mutex.Unlock()
+	engine.exec(func() {
+		comp, err = joinCompetition(playerID)
+	})
+	return comp, err
+}
 
-	player, playerFound := storage.Players[playerID]
+func joinCompetition(playerID string) (model.ICompetition, error) {
+	player, playerFound := storage.Current.GetPlayer(playerID)
 	if !playerFound {
 		return nil, ErrPlayerNotFound
 	}
+	if player.Banned() {
+		return nil, ErrPlayerBanned
+	}
 	comp := player.Competition()
 	if comp != nil {
 		// TODO: This is domain logic. Move to the model
 		if !comp.StartedAt().IsZero() && comp.EndsAt().Before(timeprovider.Current.Now()) {
-			// If the competition has ended, reset the player's competition
+			// If the competition has ended but is not finalized yet, reset the player's competition
 			player.SetCompetition(nil)
 		} else {
 			return nil, ErrPlayerAlreadyInCompetition
 		}
 	}
 
-	comp, compFound := waitingCompetitions[player.Level()]
-	if compFound {
-		comp.AddPlayer(player)
-
-		// Competition may start immediately if it has enough players
-		if !comp.StartedAt().IsZero() {
-			delete(waitingCompetitions, player.Level())
-		}
-		return comp, nil
-	} else {
-		comp, err := createNewCompetition(player)
-		if err != nil {
+	comp, err := strategy.Enqueue(player)
+	if err != nil {
+		return nil, err
+	}
+	enqueuePlayer(player)
+	// Competition may start immediately if it has enough players
+	if !comp.StartedAt().IsZero() {
+		if err := startCompetition(comp); err != nil {
 			return nil, err
 		}
-		// Start a timer to try starting a competition after the wait duration
-		go func() {
-			timer := time.NewTimer(config.MatchWaitDuration)
-			<-timer.C
-			err1 := tryStartCompetition(player)
-			if err1 != nil {
-				panic(err1) // Handle error appropriately in production code
-			}
-		}()
-
-		return comp, nil // Player is now waiting for a match
+		return comp, nil
+	}
+	events.Publish(events.PlayerQueued{PlayerId: playerID, LeaderboardId: comp.Id(), At: timeprovider.Current.Now()})
+	if err := storage.Current.PutCompetition(comp); err != nil {
+		return nil, err
 	}
+	return comp, nil // Player is now waiting for a match
 }
 
-// TODO: This logic currently supports MinPlayersForCompetition = 2 only
-// Needs some updates to support higher values of MinPlayersForCompetition
-func tryStartCompetition(player *model.Player) error {
+func tryStartCompetition(player *model.Player) (err error) {
 	if player == nil {
-		panic("player cannot be nil")
+		return ErrPlayerNil
 	}
+	engine.exec(func() {
+		err = attemptStart(player)
+	})
+	return err
+}
 
-	mutex.Lock()
-	defer mutex.Unlock()
-
+// attemptStart starts the competition of a waiting player if it has enough players,
+// or merges it with the competitions suggested by the strategy
+func attemptStart(player *model.Player) error {
+	entry := waitingEntry(player)
 	comp := player.Competition()
-	if comp != nil && len(comp.PlayersMap()) >= config.MinPlayersForCompetition {
+	if entry == nil || comp == nil {
+		// Player has left the queue, timed out or is already matched
+		return nil
+	}
+	entry.attempts++
+	if !entry.isOldestInCompetition(comp) {
+		// The oldest player of the competition tries to start it, this player only waits until the maximum wait duration
+		return expireIfWaitedTooLong(entry)
+	}
+	if len(comp.PlayersMap()) >= config.MinPlayersForCompetition {
 		// Player is already in a competition. Start it if not already started
 		if comp.StartedAt().IsZero() {
-			err := comp.Start()
-			delete(waitingCompetitions, player.Level())
-			if err != nil {
-				return err
-			}
+			return startCompetition(comp)
 		}
 		return nil
 	}
 
-	matched := false
-	for i := 1; ; i++ {
-		// Try finding a matching competioion at closest levels
-		higherLevel := player.Level() + i
-		lowerLevel := player.Level() - i
-
-		// Check if we have a competition waiting for a player at the higher or lower level
-		var waitingComp model.ICompetition
-		if higherLevel <= config.MaxLevel {
-			if waitingComp = waitingCompetitions[higherLevel]; waitingComp != nil {
-				err := waitingComp.AddPlayer(player)
-				if err != nil {
-					return err
-				}
-				matched = true
-			}
-		}
-		if !matched && lowerLevel >= config.MinLevel {
-			if waitingComp = waitingCompetitions[lowerLevel]; waitingComp != nil {
-				err := waitingComp.AddPlayer(player)
-				if err != nil {
-					return err
-				}
-				delete(waitingCompetitions, lowerLevel)
-				matched = true
-			}
+	// Let the strategy find matching competitions and merge them until there are enough players
+	mergedComp, err := mergeCompetitions(comp, strategy.Tick(player))
+	if err != nil {
+		return err
+	}
+	if mergedComp == nil {
+		// If still no matching player is found, the player keeps waiting until the maximum wait duration
+		return expireIfWaitedTooLong(entry)
+	}
+	return startCompetition(mergedComp)
+}
+
+// expireIfWaitedTooLong removes a waiting player from the queue after the maximum wait duration
+func expireIfWaitedTooLong(entry *queueEntry) error {
+	if timeprovider.Current.Now().Sub(entry.joinedAt) < config.MatchMaxWait {
+		return nil
+	}
+	return leaveQueue(entry.player, QueueStateTimeout)
+}
+
+// mergeCompetitions merges a waiting competition with the candidates in order until it has enough players to start.
+// Candidates that would exceed the maximum number of players are skipped.
+// All players are moved to the first selected candidate and the emptied competitions are discarded.
+// Returns nil if the candidates don't have enough players.
+func mergeCompetitions(comp model.ICompetition, candidates []model.ICompetition) (model.ICompetition, error) {
+	count := len(comp.PlayersMap())
+	var selected []model.ICompetition
+	for _, candidate := range candidates {
+		if count >= config.MinPlayersForCompetition {
+			break
 		}
-		if waitingComp != nil {
-			comp = waitingComp
-			player.SetCompetition(comp)
-			delete(waitingCompetitions, comp.InitialLevel())
+		if count+len(candidate.PlayersMap()) > config.MaxPlayersForCompetition {
+			continue
 		}
+		selected = append(selected, candidate)
+		count += len(candidate.PlayersMap())
+	}
+	if count < config.MinPlayersForCompetition {
+		return nil, nil
+	}
 
-		if matched {
-			err := comp.Start()
-
-			if err != nil {
-				return err
+	mergedComp := selected[0]
+	for _, from := range append([]model.ICompetition{comp}, selected[1:]...) {
+		players := make([]*model.Player, 0, len(from.PlayersMap()))
+		for _, compPlayer := range from.PlayersMap() {
+			players = append(players, compPlayer.Player())
+		}
+		for _, player := range players {
+			if err := movePlayer(player, from, mergedComp); err != nil {
+				return nil, err
 			}
-			delete(waitingCompetitions, player.Level())
-			break
 		}
-		if higherLevel >= config.MaxLevel && lowerLevel <= config.MinLevel {
-			break
+	}
+	return mergedComp, nil
+}
+
+// startCompetition removes a competition from the waiting pool and starts it
+func startCompetition(comp model.ICompetition) error {
+	strategy.Dequeue(comp)
+	// Competition may have started already if it became full
+	if comp.StartedAt().IsZero() {
+		if err := comp.Start(); err != nil {
+			return err
 		}
 	}
+	markMatched(comp)
+	scheduleFinalization(comp)
+	return storage.Current.PutCompetition(comp)
+}
 
-	// If still no matching player is found, we can start a ticker to keep checking
-	if !matched {
-		go scheduleTickerForPlayer(player)
+// movePlayer moves a waiting player to another competition and discards their own one if it is empty
+func movePlayer(player *model.Player, from model.ICompetition, to model.ICompetition) error {
+	if err := from.RemovePlayer(player.Id()); err != nil {
+		return err
 	}
+	if err := discardIfEmpty(from); err != nil {
+		return err
+	}
+	return to.AddPlayer(player)
+}
 
-	// If we reach here, it means no competition was started but player is still in the waiting list
-	// Ticker will keep trying to find a match
-	return nil
+// CancelMatchmaking removes a waiting player from the queue
+var CancelMatchmaking = func(playerID string) (err error) {
+	if playerID == "" {
+		return ErrPlayerIdEmpty
+	}
+	engine.exec(func() {
+		err = cancelMatchmaking(playerID)
+	})
+	return err
+}
+
+func cancelMatchmaking(playerID string) error {
+	player, playerFound := storage.Current.GetPlayer(playerID)
+	if !playerFound {
+		return ErrPlayerNotFound
+	}
+	if waitingEntry(player) == nil {
+		return ErrPlayerNotWaiting
+	}
+	return leaveQueue(player, QueueStateCancelled)
 }
 
-func scheduleTickerForPlayer(player *model.Player) {
-	ticker := time.NewTicker(config.MatchRetryInterval)
-	// TODO: Stop retry after a certain number of attempts or time limit
-	for range ticker.C {
-		err := tryStartCompetition(player)
-		if err != nil {
-			panic(err) // Handle error appropriately in production code
+// LeaveCompetition removes a deleted player from the competition they are waiting in.
+// Players in a started competition keep their entry in its leaderboard.
+func LeaveCompetition(player *model.Player) (err error) {
+	engine.exec(func() {
+		err = leaveQueue(player, QueueStateCancelled)
+		if entry, found := queue[player.Id()]; found && entry.player == player {
+			delete(queue, player.Id())
 		}
-		if player.Competition() != nil {
-			ticker.Stop()
+	})
+	return err
+}
+
+// leaveQueue removes a player from the competition they are waiting in and moves their queue entry to a terminal state
+func leaveQueue(player *model.Player, state string) error {
+	if entry := waitingEntry(player); entry != nil {
+		entry.finish(state)
+	}
+	comp := player.Competition()
+	if comp == nil || !comp.StartedAt().IsZero() {
+		return nil
+	}
+	strategy.Cancel(player)
+	if err := comp.RemovePlayer(player.Id()); err != nil {
+		return err
+	}
+	return discardIfEmpty(comp)
+}
+
+// discardIfEmpty removes a waiting competition without players, or stores the remaining players otherwise
+func discardIfEmpty(comp model.ICompetition) error {
+	if len(comp.PlayersMap()) > 0 {
+		return storage.Current.PutCompetition(comp)
+	}
+	strategy.Dequeue(comp)
+	orderedCompetitions = slices.DeleteFunc(orderedCompetitions, func(c model.ICompetition) bool {
+		return c == comp
+	})
+	return storage.Current.DeleteCompetition(comp.Id())
+}
+
+// UpdatePlayer changes the level and country code of a player.
+// Waiting competitions are grouped by level, so players waiting for a match cannot be updated.
+// A started competition keeps the level and country code the player joined with, the new ones are used
+// the next time the player joins.
+func UpdatePlayer(player *model.Player, level int, countryCode string) (err error) {
+	if err := model.ValidateLevel(level); err != nil {
+		return err
+	}
+	if err := model.ValidateCountryCode(countryCode); err != nil {
+		return err
+	}
+
+	engine.exec(func() {
+		if comp := player.Competition(); comp != nil && comp.StartedAt().IsZero() {
+			err = ErrPlayerWaitingForMatch
 			return
 		}
+		if err = player.SetLevel(level); err != nil {
+			return
+		}
+		err = player.SetCountryCode(countryCode)
+	})
+	return err
+}
+
+// Restore rebuilds the matchmaking state from the competitions in storage.
+// Competitions that have not started are waiting for players again and are retried after the wait duration.
+// Started competitions are finalized at their end time.
+// It is called once on startup, after SetStrategy and SetEngine.
+func Restore() {
+	engine.exec(restore)
+}
+
+func restore() {
+	orderedCompetitions = orderedCompetitions[:0]
+	pendingFinalization = pendingFinalization[:0]
+	for _, comp := range storage.Current.ListCompetitions() {
+		orderedCompetitions = append(orderedCompetitions, comp)
+		if !comp.StartedAt().IsZero() {
+			// Competitions that ended while the server was down are finalized by the next run of the finalizer
+			if !comp.Ended() {
+				scheduleFinalization(comp)
+			}
+			continue
+		}
+		strategy.Requeue(comp)
+		for _, compPlayer := range comp.PlayersMap() {
+			enqueuePlayer(compPlayer.Player())
+		}
 	}
 }
 
 func createNewCompetition(player *model.Player) (model.ICompetition, error) {
 	if player == nil {
-		return nil, errors.New("player must be provided")
+		return nil, ErrPlayerNil
 	}
 
 	comp := model.NewCompetition(player.Level())
-	storage.Competitions[comp.Id()] = comp
+	if tieBreaker, found := config.TieBreakers[strategyMode]; found {
+		if err := comp.SetTieBreaker(tieBreaker); err != nil {
+			return nil, err
+		}
+	}
+	if scoringMode, found := config.ScoringModes[strategyMode]; found {
+		if err := comp.SetScoringMode(scoringMode); err != nil {
+			return nil, err
+		}
+	}
 	err := comp.AddPlayer(player)
 	if err != nil {
 		return nil, err
 	}
-	waitingCompetitions[player.Level()] = comp
+	if err := storage.Current.PutCompetition(comp); err != nil {
+		return nil, err
+	}
 
 	orderedCompetitions = append(orderedCompetitions, comp)
+	events.Publish(events.CompetitionCreated{LeaderboardId: comp.Id(), InitialLevel: comp.InitialLevel(), At: timeprovider.Current.Now()})
 
 	ensureMaxCompetitionsInMemory()
 
@@ -192,14 +338,30 @@ func createNewCompetition(player *model.Player) (model.ICompetition, error) {
 }
 
 func ensureMaxCompetitionsInMemory() {
+	// Ended competitions are finalized before they are evicted
+	finalizeEndedCompetitions()
 	index := 0
+	count := len(storage.Current.ListCompetitions())
 	// Usually this will only delete the oldest competition that has started and ended
 	// But ensure that we don't delete competitions that are still ongoing
-	for len(storage.Competitions) > config.MaxCompetitionsInMemory &&
+	for count > config.MaxCompetitionsInMemory &&
 		!orderedCompetitions[index].StartedAt().IsZero() &&
 		orderedCompetitions[index].EndsAt().Before(timeprovider.Current.Now()) {
-		// Remove the oldest competition that has started and ended
-		delete(storage.Competitions, orderedCompetitions[index].Id())
+		// Record the results of the oldest competition that has started and ended, then remove it.
+		// Competitions that were not scheduled for finalization are finalized first
+		if !orderedCompetitions[index].Ended() {
+			finalize(orderedCompetitions[index])
+		}
+		if err := leaderboard.RecordResults(orderedCompetitions[index]); err != nil {
+			log.Printf("Failed to record the results of competition %s: %v", orderedCompetitions[index].Id(), err)
+			break
+		}
+		if err := storage.Current.DeleteCompetition(orderedCompetitions[index].Id()); err != nil {
+			log.Printf("Failed to delete competition %s: %v", orderedCompetitions[index].Id(), err)
+			break
+		}
+		events.Publish(events.CompetitionEvicted{LeaderboardId: orderedCompetitions[index].Id(), At: timeprovider.Current.Now()})
+		count -= 1
 		index += 1
 	}
 	if index > 0 {
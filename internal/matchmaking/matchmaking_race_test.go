@@ -1,12 +1,15 @@
 package matchmaking
 
 import (
+	"fmt"
+	"leaderboard/internal/model"
 	"leaderboard/internal/storage"
 	"sync"
 	"testing"
 )
 
 func TestJoinCompetition_RaceCondition(t *testing.T) {
+	defer tearDown()
 	var wg sync.WaitGroup
 	playerCount := 1000
 
@@ -46,3 +49,51 @@ func TestJoinCompetition_RaceCondition(t *testing.T) {
 		t.Errorf("expected %d players joined, got %d", playerCount, len(joined))
 	}
 }
+
+func TestJoinCompetition_CountryMode_RaceCondition(t *testing.T) {
+	SetStrategy(ModeCountry)
+	defer func() {
+		SetStrategy(ModeLevel)
+		tearDown()
+	}()
+
+	var wg sync.WaitGroup
+	playerCount := 1000
+	countries := []string{"US", "CA", "GB", "DE", "JP"}
+
+	for i := 0; i < playerCount; i++ {
+		storage.AddPlayers([]storage.NewPlayer{
+			{Id: fmt.Sprintf("country_player%d", i), CountryCode: countries[i%len(countries)], Level: i%3 + 1},
+		})
+	}
+
+	for i := 0; i < playerCount; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			playerID := fmt.Sprintf("country_player%d", i)
+			if _, err := JoinCompetition(playerID); err != nil {
+				t.Errorf("unexpected error for %s: %v", playerID, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Every competition created by a join only has players of one level and country
+	joined := 0
+	for _, comp := range storage.Current.ListCompetitions() {
+		var first *model.Player
+		for _, compPlayer := range comp.PlayersMap() {
+			player := compPlayer.Player()
+			if first == nil {
+				first = player
+			} else if player.Level() != first.Level() || player.CountryCode() != first.CountryCode() {
+				t.Errorf("competition %s mixes %s/%d and %s/%d", comp.Id(), first.CountryCode(), first.Level(), player.CountryCode(), player.Level())
+			}
+			joined++
+		}
+	}
+	if joined != playerCount {
+		t.Errorf("expected %d players in competitions, got %d", playerCount, joined)
+	}
+}
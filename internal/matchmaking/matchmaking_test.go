@@ -4,8 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"leaderboard/internal/config"
+	"leaderboard/internal/events"
 	"leaderboard/internal/model"
 	"leaderboard/internal/storage"
+	"leaderboard/internal/timeprovider"
 	"testing"
 	"time"
 )
@@ -26,14 +28,33 @@ func setup() {
 }
 
 func tearDown() {
+	// The attempts are stopped before the configuration is reset, so they don't read it concurrently
+	resetQueue()
+	orderedCompetitions = make([]model.ICompetition, 0)
+	pendingFinalization = make([]model.ICompetition, 0)
+	SetStrategy(ModeLevel)
+	SetEngine(EngineLoop)
+	storage.Current = storage.NewMemoryStore()
+
 	config.MatchWaitDuration = 30 * time.Second
 	config.CompetitionDuration = 1 * time.Hour
 	config.MaxCompetitionsInMemory = 100
+	config.MinPlayersForCompetition = 2
+	config.MaxPlayersForCompetition = 10
 
-	orderedCompetitions = make([]model.ICompetition, 0)
-	clear(waitingCompetitions)
-	clear(storage.Players)
-	clear(storage.Competitions)
+	config.MatchRetryInterval = 1 * time.Second
+	config.MatchMaxWait = 2 * time.Minute
+	config.MatchMaxFailures = 3
+}
+
+// resetQueue stops the goroutines of all waiting players so they don't affect the next test
+func resetQueue() {
+	engine.exec(func() {
+		for _, entry := range queue {
+			entry.finish(QueueStateCancelled)
+		}
+		clear(queue)
+	})
 }
 
 func TestJoinCompetitionBasic(t *testing.T) {
@@ -75,8 +96,8 @@ func TestJoinCompetition_Matchmaking(t *testing.T) {
 	if comp1 == nil {
 		t.Fatalf("expected competition to be created for bob")
 	}
-	if waitingCompetitions[2] == nil || waitingCompetitions[2].PlayersMap()["bob"] == nil {
-		t.Errorf("waitingCompetitions at level 2 should have bob, got %v", waitingCompetitions[2])
+	if waitingLobbies()[lobbyKey{level: 2}] == nil || waitingLobbies()[lobbyKey{level: 2}].PlayersMap()["bob"] == nil {
+		t.Errorf("waiting lobby at level 2 should have bob, got %v", waitingLobbies()[lobbyKey{level: 2}])
 	}
 
 	// Second player joins, should create a competition
@@ -97,8 +118,8 @@ func TestJoinCompetition_Matchmaking(t *testing.T) {
 	if !comp1.StartedAt().IsZero() {
 		t.Errorf("competition should not have started yet, got started at %v", comp1.StartedAt())
 	}
-	if waitingCompetitions[2] == nil {
-		t.Errorf("waitingCompetitions at level 2 should not be nil, got %v", waitingCompetitions)
+	if waitingLobbies()[lobbyKey{level: 2}] == nil {
+		t.Errorf("waiting lobby at level 2 should not be nil, got %v", waitingLobbies())
 	}
 	tearDown()
 }
@@ -110,7 +131,7 @@ func TestJoinCompetition_AlreadyInCompetition(t *testing.T) {
 		{Id: "player3", CountryCode: "US", Level: 7},
 	})
 
-	player := storage.Players["player3"]
+	player, _ := storage.Current.GetPlayer("player3")
 	// Simulate player already in a competition
 	fakeComp := model.NewCompetition(1)
 	player.SetCompetition(fakeComp)
@@ -126,6 +147,37 @@ func TestJoinCompetition_AlreadyInCompetition(t *testing.T) {
 	tearDown()
 }
 
+func TestJoinCompetition_PlayerBanned(t *testing.T) {
+	setup()
+	defer tearDown()
+
+	player, _ := storage.Current.GetPlayer("alice")
+	player.SetBanned(true)
+	if _, err := JoinCompetition("alice"); err != ErrPlayerBanned {
+		t.Errorf("expected ErrPlayerBanned, got %v", err)
+	}
+	if player.Competition() != nil {
+		t.Errorf("expected the banned player not to join a competition")
+	}
+}
+
+func TestJoinCompetition_TieBreakerOfMatchmakingMode(t *testing.T) {
+	setup()
+	config.TieBreakers = map[string]string{ModeFifo: model.TieBreakerEarliest}
+	defer func() { config.TieBreakers = map[string]string{} }()
+
+	comp, _ := JoinCompetition("alice")
+	if comp.TieBreaker() != config.TieBreaker {
+		t.Errorf("expected the default tie-breaker in the level mode, got %s", comp.TieBreaker())
+	}
+	SetStrategy(ModeFifo)
+	comp, _ = JoinCompetition("bob")
+	if comp.TieBreaker() != model.TieBreakerEarliest {
+		t.Errorf("expected the earliest tie-breaker in the fifo mode, got %s", comp.TieBreaker())
+	}
+	tearDown()
+}
+
 func TestJoinCompetition_JoinMaxplayers_CompetitionStarts(t *testing.T) {
 	setup()
 	var previousComp model.ICompetition
@@ -151,7 +203,7 @@ func TestJoinCompetition_JoinMaxplayers_CompetitionStarts(t *testing.T) {
 		}
 		previousComp = comp
 
-		player := storage.Players[playerId]
+		player, _ := storage.Current.GetPlayer(playerId)
 		if player.Competition() == nil || player.Competition().Id() != comp.Id() {
 			t.Errorf("player %s should be in competition %s, got %v", playerId, comp.Id(), player.Competition())
 		}
@@ -160,13 +212,13 @@ func TestJoinCompetition_JoinMaxplayers_CompetitionStarts(t *testing.T) {
 			if comp.StartedAt().IsZero() {
 				t.Errorf("competition should have started after adding %d players, got started at %v", config.MaxPlayersForCompetition, comp.StartedAt())
 			}
-			if len(waitingCompetitions) != 0 {
-				t.Errorf("waitingCompetitions should be empty after competition started, got %v", waitingCompetitions)
+			if len(waitingLobbies()) != 0 {
+				t.Errorf("waiting lobbies should be empty after competition started, got %v", waitingLobbies())
 			}
 
 			// Test player1 only one time
 			player1Id := fmt.Sprintf("player%v", 1)
-			player1 := storage.Players[player1Id]
+			player1, _ := storage.Current.GetPlayer(player1Id)
 			if player1.Competition() == nil || player1.Competition().Id() != comp.Id() {
 				t.Errorf("player %s should be in competition %s, got %v", player1Id, comp.Id(), player1.Competition())
 			}
@@ -174,8 +226,8 @@ func TestJoinCompetition_JoinMaxplayers_CompetitionStarts(t *testing.T) {
 			if !comp.StartedAt().IsZero() {
 				t.Errorf("competition should not have started yet, got started at %v", comp.StartedAt())
 			}
-			if len(waitingCompetitions) != 1 {
-				t.Errorf("waitingCompetitions should have 1 competition, got %d", len(waitingCompetitions))
+			if len(waitingLobbies()) != 1 {
+				t.Errorf("waiting lobbies should have 1 competition, got %d", len(waitingLobbies()))
 			}
 		}
 	}
@@ -222,20 +274,24 @@ func TestJoinCompetition_MatchedwithTwoPlayers_CompetitionStartsAfterMatchWaitDu
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if !comp.StartedAt().IsZero() {
-		t.Errorf("competition should not have started immediately, got started at %v", comp.StartedAt())
-	}
+	engine.exec(func() {
+		if !comp.StartedAt().IsZero() {
+			t.Errorf("competition should not have started immediately, got started at %v", comp.StartedAt())
+		}
+	})
 
 	time.Sleep(1 * time.Second) // Wait for starting competition after MatchWaitDuration
-	if comp.StartedAt().IsZero() {
-		t.Errorf("competition should have started after %v, got started at %v", config.MatchWaitDuration, comp.StartedAt())
-	}
-	if len(comp.PlayersMap()) != 2 {
-		t.Errorf("competition should have 2 players, got %d", len(comp.PlayersMap()))
-	}
-	if len(waitingCompetitions) != 0 {
-		t.Errorf("waitingCompetitions should be empty after competition started, got %v", waitingCompetitions)
-	}
+	engine.exec(func() {
+		if comp.StartedAt().IsZero() {
+			t.Errorf("competition should have started after %v, got started at %v", config.MatchWaitDuration, comp.StartedAt())
+		}
+		if len(comp.PlayersMap()) != 2 {
+			t.Errorf("competition should have 2 players, got %d", len(comp.PlayersMap()))
+		}
+		if len(waitingLobbies()) != 0 {
+			t.Errorf("waiting lobbies should be empty after competition started, got %v", waitingLobbies())
+		}
+	})
 	tearDown()
 }
 
@@ -251,31 +307,33 @@ func TestJoinCompetition_MatchedWithTwoPlayersInTwoLevels_CompetitionStartsAfter
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	alice := storage.Players["alice"]
-	bob := storage.Players["bob"]
+	alice, _ := storage.Current.GetPlayer("alice")
+	bob, _ := storage.Current.GetPlayer("bob")
 
 	time.Sleep(2 * time.Second) // Wait for starting competition after MatchWaitDuration
 
-	if alice.Competition() == nil {
-		t.Errorf("alice should be in a competition, got nil")
-	}
-	if bob.Competition() == nil {
-		t.Errorf("bob should be in a competition, got nil")
-	}
-	if alice.Competition().Id() != bob.Competition().Id() {
-		t.Errorf("alice and bob should be in the same competition, got %s and %s", alice.Competition().Id(), bob.Competition().Id())
-	}
-	comp := alice.Competition()
+	engine.exec(func() {
+		if alice.Competition() == nil {
+			t.Errorf("alice should be in a competition, got nil")
+		}
+		if bob.Competition() == nil {
+			t.Errorf("bob should be in a competition, got nil")
+		}
+		if alice.Competition().Id() != bob.Competition().Id() {
+			t.Errorf("alice and bob should be in the same competition, got %s and %s", alice.Competition().Id(), bob.Competition().Id())
+		}
+		comp := alice.Competition()
 
-	if comp.StartedAt().IsZero() {
-		t.Errorf("competition should have started after %v, got started at %v", config.MatchWaitDuration, comp.StartedAt())
-	}
-	if len(comp.PlayersMap()) != 2 {
-		t.Errorf("competition should have 2 players, got %d", len(comp.PlayersMap()))
-	}
-	if len(waitingCompetitions) != 0 {
-		t.Errorf("waitingCompetitions should be empty after competition started, got %v", waitingCompetitions)
-	}
+		if comp.StartedAt().IsZero() {
+			t.Errorf("competition should have started after %v, got started at %v", config.MatchWaitDuration, comp.StartedAt())
+		}
+		if len(comp.PlayersMap()) != 2 {
+			t.Errorf("competition should have 2 players, got %d", len(comp.PlayersMap()))
+		}
+		if len(waitingLobbies()) != 0 {
+			t.Errorf("waiting lobbies should be empty after competition started, got %v", waitingLobbies())
+		}
+	})
 	tearDown()
 }
 
@@ -291,31 +349,33 @@ func TestJoinCompetition_MatchedwithTwoPlayersInMinAndMaxLevels_CompetitionStart
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	alice := storage.Players["alice"]
-	ian := storage.Players["ian"]
+	alice, _ := storage.Current.GetPlayer("alice")
+	ian, _ := storage.Current.GetPlayer("ian")
 
 	time.Sleep(2 * time.Second) // Wait for starting competition after MatchWaitDuration
 
-	if alice.Competition() == nil {
-		t.Errorf("alice should be in a competition, got nil")
-	}
-	if ian.Competition() == nil {
-		t.Errorf("ian should be in a competition, got nil")
-	}
-	if alice.Competition().Id() != ian.Competition().Id() {
-		t.Errorf("alice and bob should be in the same competition, got %s and %s", alice.Competition().Id(), ian.Competition().Id())
-	}
-	comp := alice.Competition()
+	engine.exec(func() {
+		if alice.Competition() == nil {
+			t.Errorf("alice should be in a competition, got nil")
+		}
+		if ian.Competition() == nil {
+			t.Errorf("ian should be in a competition, got nil")
+		}
+		if alice.Competition().Id() != ian.Competition().Id() {
+			t.Errorf("alice and bob should be in the same competition, got %s and %s", alice.Competition().Id(), ian.Competition().Id())
+		}
+		comp := alice.Competition()
 
-	if comp.StartedAt().IsZero() {
-		t.Errorf("competition should have started after %v, got started at %v", config.MatchWaitDuration, comp.StartedAt())
-	}
-	if len(comp.PlayersMap()) != 2 {
-		t.Errorf("competition should have 2 players, got %d", len(comp.PlayersMap()))
-	}
-	if len(waitingCompetitions) != 0 {
-		t.Errorf("waitingCompetitions should be empty after competition started, got %v", waitingCompetitions)
-	}
+		if comp.StartedAt().IsZero() {
+			t.Errorf("competition should have started after %v, got started at %v", config.MatchWaitDuration, comp.StartedAt())
+		}
+		if len(comp.PlayersMap()) != 2 {
+			t.Errorf("competition should have 2 players, got %d", len(comp.PlayersMap()))
+		}
+		if len(waitingLobbies()) != 0 {
+			t.Errorf("waiting lobbies should be empty after competition started, got %v", waitingLobbies())
+		}
+	})
 	tearDown()
 }
 
@@ -331,10 +391,13 @@ func TestJoinCompetition_CompetitionStartAfterWait_NewJoineesAddedToNewCompetiti
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	alice := storage.Players["alice"]
+	alice, _ := storage.Current.GetPlayer("alice")
 
 	time.Sleep(2 * time.Second) // Wait for starting competition after MatchWaitDuration
-	comp1 := alice.Competition()
+	var comp1 model.ICompetition
+	engine.exec(func() {
+		comp1 = alice.Competition()
+	})
 
 	alice1comp, err := JoinCompetition("alice_1")
 	if err != nil {
@@ -351,9 +414,11 @@ func TestJoinCompetition_CompetitionStartAfterWait_NewJoineesAddedToNewCompetiti
 	if bob1comp == nil {
 		t.Fatalf("expected bob_1 to be added to competition, got nil")
 	}
-	if waitingCompetitions[2] == nil {
-		t.Errorf("waitingCompetitions at level 2 should not be nil, got %v", waitingCompetitions)
-	}
+	engine.exec(func() {
+		if waitingLobbies()[lobbyKey{level: 2}] == nil {
+			t.Errorf("waiting lobby at level 2 should not be nil, got %v", waitingLobbies())
+		}
+	})
 
 	alice2comp, err := JoinCompetition("alice_2")
 	if err != nil {
@@ -386,18 +451,20 @@ func TestJoinCompetition_NotMatchedWithinWait_CompetitionStartsAfterNewUserJoin(
 
 	time.Sleep(1 * time.Second) // Wait for starting competition after MatchWaitDuration
 
-	if comp.StartedAt().IsZero() {
-		t.Errorf("competition should have started after %v, got started at %v", config.MatchWaitDuration, comp.StartedAt())
-	}
-	if len(comp.PlayersMap()) != 2 {
-		t.Errorf("competition should have 2 players, got %d", len(comp.PlayersMap()))
-	}
-	if len(comp.Leaderboard()) != 2 {
-		t.Errorf("competition should have 2 players in leaderboard, got %d", len(comp.Leaderboard()))
-	}
-	if len(waitingCompetitions) != 0 {
-		t.Errorf("waitingCompetitions should be empty after competition started, got %v", waitingCompetitions)
-	}
+	engine.exec(func() {
+		if comp.StartedAt().IsZero() {
+			t.Errorf("competition should have started after %v, got started at %v", config.MatchWaitDuration, comp.StartedAt())
+		}
+		if len(comp.PlayersMap()) != 2 {
+			t.Errorf("competition should have 2 players, got %d", len(comp.PlayersMap()))
+		}
+		if len(comp.Leaderboard()) != 2 {
+			t.Errorf("competition should have 2 players in leaderboard, got %d", len(comp.Leaderboard()))
+		}
+		if len(waitingLobbies()) != 0 {
+			t.Errorf("waiting lobbies should be empty after competition started, got %v", waitingLobbies())
+		}
+	})
 	tearDown()
 }
 
@@ -435,6 +502,42 @@ func TestJoinCompetition_CompetetionStartAndEnd_UserCanJoinNewCompetition(t *tes
 	tearDown()
 }
 
+func TestJoinCompetition_PublishesEvents(t *testing.T) {
+	setup()
+	defer tearDown()
+	config.MaxPlayersForCompetition = 2
+	published := make(chan events.Event, 10)
+	unsubscribe := events.SubscribeAsync(func(event events.Event) { published <- event }, 10)
+	defer unsubscribe()
+
+	_, _ = JoinCompetition("alice")
+	comp, err := JoinCompetition("alice_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = comp.AddScore("alice", 10)
+	timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: comp.EndsAt().Add(time.Second)}
+	defer func() { timeprovider.Current = timeprovider.RealTimeProvider{} }()
+	FinalizeEndedCompetitions()
+
+	// alice_1 is not queued, the competition starts when they join
+	expected := []string{"competition_created", "player_queued", "competition_started", "score_added", "competition_ended"}
+	for _, name := range expected {
+		select {
+		case event := <-published:
+			if event.Name() != name {
+				t.Fatalf("expected a %s event, got %+v", name, event)
+			}
+			if ended, ok := event.(events.CompetitionEnded); ok && (ended.LeaderboardId != comp.Id() ||
+				len(ended.Standings) != 2 || ended.Standings[0] != (events.Standing{PlayerId: "alice", Score: 10, Rank: 1})) {
+				t.Errorf("expected alice to end first with 10 points, got %+v", ended)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected a %s event", name)
+		}
+	}
+}
+
 func TestJoinCompetition_CompetitionCreatedAtAdjacentLevel_CompetitionStartsAfterMatchWaitDuration(t *testing.T) {
 	setup()
 	config.MatchWaitDuration = 1 * time.Second // Set a short wait duration for testing
@@ -443,6 +546,8 @@ func TestJoinCompetition_CompetitionCreatedAtAdjacentLevel_CompetitionStartsAfte
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
+	// alice's attempt runs first, so her competition is merged before bob's is started
+	time.Sleep(200 * time.Millisecond)
 	bobComp, err := JoinCompetition("bob")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -458,40 +563,126 @@ func TestJoinCompetition_CompetitionCreatedAtAdjacentLevel_CompetitionStartsAfte
 		t.Errorf("expected bob and bob_1 to be in the same competition, got different competitions %s and %s", bobComp.Id(), bob1Comp.Id())
 	}
 
-	alice := storage.Players["alice"]
-	bob := storage.Players["bob"]
-	bob1 := storage.Players["bob_1"]
+	alice, _ := storage.Current.GetPlayer("alice")
+	bob, _ := storage.Current.GetPlayer("bob")
+	bob1, _ := storage.Current.GetPlayer("bob_1")
 
 	time.Sleep(2 * time.Second) // Wait for starting competition after MatchWaitDuration
 
-	if alice.Competition() == nil {
-		t.Errorf("alice should be in a competition, got nil")
+	engine.exec(func() {
+		if alice.Competition() == nil {
+			t.Errorf("alice should be in a competition, got nil")
+		}
+		if bob.Competition() == nil {
+			t.Errorf("bob should be in a competition, got nil")
+		}
+		if bob1.Competition() == nil {
+			t.Errorf("bob_1 should be in a competition, got nil")
+		}
+		if bob.Competition().Id() != bob1.Competition().Id() {
+			t.Errorf("bob and bob_1 should be in the same competition, got %s and %s", bob.Competition().Id(), bob1.Competition().Id())
+		}
+		if alice.Competition().Id() != bob.Competition().Id() {
+			t.Errorf("alice and bob should be in the same competition, got %s and %s", alice.Competition().Id(), bob.Competition().Id())
+		}
+		comp := alice.Competition()
+		if comp != bobComp {
+			t.Errorf("expected alice's competition to be the same as bob's result from JoinCompetetion, got %s and %s", comp.Id(), bobComp.Id())
+		}
+
+		if comp.StartedAt().IsZero() {
+			t.Errorf("competition should have started after %v, got started at %v", config.MatchWaitDuration, comp.StartedAt())
+		}
+		if len(comp.PlayersMap()) != 3 {
+			t.Errorf("competition should have 3 players, got %v", comp.PlayersMap())
+		}
+		if len(waitingLobbies()) != 0 {
+			t.Errorf("waiting lobbies should be empty after competition started, got %v", waitingLobbies())
+		}
+	})
+	tearDown()
+}
+
+func TestLeaveCompetition_LastPlayerLeaves_CompetitionDiscarded(t *testing.T) {
+	setup()
+	comp, err := JoinCompetition("bob")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if bob.Competition() == nil {
-		t.Errorf("bob should be in a competition, got nil")
+	bob, _ := storage.Current.GetPlayer("bob")
+
+	err = LeaveCompetition(bob)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if bob1.Competition() == nil {
-		t.Errorf("bob_1 should be in a competition, got nil")
+	if bob.Competition() != nil {
+		t.Errorf("bob should not be in a competition, got %v", bob.Competition())
 	}
-	if bob.Competition().Id() != bob1.Competition().Id() {
-		t.Errorf("bob and bob_1 should be in the same competition, got %s and %s", bob.Competition().Id(), bob1.Competition().Id())
+	if len(waitingLobbies()) != 0 {
+		t.Errorf("waiting lobbies should be empty, got %v", waitingLobbies())
 	}
-	if alice.Competition().Id() != bob.Competition().Id() {
-		t.Errorf("alice and bob should be in the same competition, got %s and %s", alice.Competition().Id(), bob.Competition().Id())
+	if _, found := storage.Current.GetCompetition(comp.Id()); found {
+		t.Errorf("empty competition %s should be removed from storage", comp.Id())
 	}
-	comp := alice.Competition()
-	if comp != bobComp {
-		t.Errorf("expected alice's competition to be the same as bob's result from JoinCompetetion, got %s and %s", comp.Id(), bobComp.Id())
+	if len(orderedCompetitions) != 0 {
+		t.Errorf("orderedCompetitions should be empty, got %d", len(orderedCompetitions))
 	}
+	tearDown()
+}
+
+func TestLeaveCompetition_OtherPlayersKeepWaiting(t *testing.T) {
+	setup()
+	_, _ = JoinCompetition("bob")
+	comp, _ := JoinCompetition("bob_1")
+	bob, _ := storage.Current.GetPlayer("bob")
 
-	if comp.StartedAt().IsZero() {
-		t.Errorf("competition should have started after %v, got started at %v", config.MatchWaitDuration, comp.StartedAt())
+	err := LeaveCompetition(bob)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(comp.PlayersMap()) != 3 {
-		t.Errorf("competition should have 3 players, got %v", comp.PlayersMap())
+	if len(comp.PlayersMap()) != 1 || comp.PlayersMap()["bob_1"] == nil {
+		t.Errorf("competition should only have bob_1, got %v", comp.PlayersMap())
 	}
-	if len(waitingCompetitions) != 0 {
-		t.Errorf("waitingCompetitions should be empty after competition started, got %v", waitingCompetitions)
+	if waitingLobbies()[lobbyKey{level: 2}] != comp {
+		t.Errorf("competition should still be waiting at level 2")
 	}
 	tearDown()
 }
+
+func TestUpdatePlayer_WaitingPlayer_ReturnsError(t *testing.T) {
+	setup()
+	_, _ = JoinCompetition("bob")
+	bob, _ := storage.Current.GetPlayer("bob")
+
+	err := UpdatePlayer(bob, 5, "GB")
+
+	if !errors.Is(err, ErrPlayerWaitingForMatch) {
+		t.Errorf("expected ErrPlayerWaitingForMatch, got %v", err)
+	}
+	if bob.Level() != 2 {
+		t.Errorf("bob's level should not change, got %d", bob.Level())
+	}
+	tearDown()
+}
+
+func TestUpdatePlayer_NotWaiting_LevelChanged(t *testing.T) {
+	setup()
+	bob, _ := storage.Current.GetPlayer("bob")
+
+	err := UpdatePlayer(bob, 5, "DE")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bob.Level() != 5 || bob.CountryCode() != "DE" {
+		t.Errorf("expected level 5 and country DE, got %d and %s", bob.Level(), bob.CountryCode())
+	}
+	tearDown()
+}
+
+// waitingLobbies returns the competitions waiting for a match with the default strategy
+func waitingLobbies() map[lobbyKey]model.ICompetition {
+	return strategy.(*levelStrategy).lobbies
+}
@@ -0,0 +1,106 @@
+package matchmaking
+
+import (
+	"leaderboard/internal/config"
+	"leaderboard/internal/model"
+	"leaderboard/internal/storage"
+	"slices"
+	"testing"
+	"time"
+)
+
+func setupMerge(minPlayers, maxPlayers int) {
+	setup()
+	config.MinPlayersForCompetition = minPlayers
+	config.MaxPlayersForCompetition = maxPlayers
+	// Keep the wait timers from firing, tryStartCompetition is called directly
+	config.MatchWaitDuration = 1 * time.Hour
+}
+
+func TestTryStartCompetition_MinThreePlayers_MergesAdjacentLevels(t *testing.T) {
+	setupMerge(3, 10)
+	defer tearDown()
+
+	joinForTest(t, "alice", "bob", "carlos")
+	alice, _ := storage.Current.GetPlayer("alice")
+	bob, _ := storage.Current.GetPlayer("bob")
+	carlos, _ := storage.Current.GetPlayer("carlos")
+	emptiedComps := []model.ICompetition{alice.Competition(), bob.Competition()}
+
+	if err := tryStartCompetition(bob); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	comp := carlos.Competition()
+	if bob.Competition() != comp || alice.Competition() != comp {
+		t.Fatalf("expected alice and bob to be merged into carlos's competition at the higher level")
+	}
+	if len(comp.PlayersMap()) != 3 {
+		t.Errorf("expected 3 players, got %d", len(comp.PlayersMap()))
+	}
+	if comp.StartedAt().IsZero() {
+		t.Errorf("expected the merged competition to start")
+	}
+	for _, emptied := range emptiedComps {
+		if _, found := storage.Current.GetCompetition(emptied.Id()); found {
+			t.Errorf("expected the emptied competition %s to be removed from storage", emptied.Id())
+		}
+		if slices.Contains(orderedCompetitions, emptied) {
+			t.Errorf("expected the emptied competition %s to be removed from orderedCompetitions", emptied.Id())
+		}
+	}
+	if len(waitingLobbies()) != 0 {
+		t.Errorf("waiting lobbies should be empty after competition started, got %v", waitingLobbies())
+	}
+}
+
+func TestTryStartCompetition_MinThreePlayers_NotEnoughWaitingPlayers(t *testing.T) {
+	setupMerge(3, 10)
+	defer tearDown()
+
+	joinForTest(t, "alice", "bob")
+	alice, _ := storage.Current.GetPlayer("alice")
+	bob, _ := storage.Current.GetPlayer("bob")
+
+	if err := tryStartCompetition(bob); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if alice.Competition() == bob.Competition() {
+		t.Errorf("expected alice and bob to keep waiting in their own competitions")
+	}
+	if !bob.Competition().StartedAt().IsZero() || !alice.Competition().StartedAt().IsZero() {
+		t.Errorf("expected no competition to start")
+	}
+	if len(waitingLobbies()) != 2 {
+		t.Errorf("expected 2 waiting lobbies, got %d", len(waitingLobbies()))
+	}
+}
+
+func TestTryStartCompetition_MergeSkipsCompetitionsExceedingMaxPlayers(t *testing.T) {
+	setupMerge(4, 4)
+	defer tearDown()
+
+	joinForTest(t, "bob", "bob_1", "carlos", "carlos_1", "carlos_2", "alice", "alice_1")
+	alice, _ := storage.Current.GetPlayer("alice")
+	bob, _ := storage.Current.GetPlayer("bob")
+	carlos, _ := storage.Current.GetPlayer("carlos")
+
+	if err := tryStartCompetition(bob); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	comp := alice.Competition()
+	if bob.Competition() != comp {
+		t.Fatalf("expected bob to be merged with alice's competition, skipping the one at level 3")
+	}
+	if len(comp.PlayersMap()) != 4 || comp.StartedAt().IsZero() {
+		t.Errorf("expected the merged competition to start with 4 players, got %d", len(comp.PlayersMap()))
+	}
+	if carlos.Competition() == comp || len(carlos.Competition().PlayersMap()) != 3 {
+		t.Errorf("expected carlos's competition to keep waiting with 3 players")
+	}
+	if waitingLobbies()[lobbyKey{level: 3}] != carlos.Competition() {
+		t.Errorf("expected carlos's competition to be the only waiting lobby, got %v", waitingLobbies())
+	}
+}
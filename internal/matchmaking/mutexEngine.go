@@ -0,0 +1,85 @@
+package matchmaking
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// mutexEngine runs operations on the calling goroutine while holding a mutex.
+// Attempts are scheduled with a timer per waiting player and processed one at a time by a supervised worker,
+// since each attempt needs exclusive access to the matchmaking state.
+type mutexEngine struct {
+	// This mutex synchronizes the access to the strategy and the competitions waiting for a match
+	// Also start competition is accessed only by one goroutine at a time using this
+	mutex sync.Mutex
+
+	// Due attempts to start the competition of a waiting player, processed by the worker
+	attempts chan *queueEntry
+	// The worker is started when the first attempt is scheduled
+	startWorker sync.Once
+	quit        chan struct{}
+}
+
+func newMutexEngine() *mutexEngine {
+	return &mutexEngine{
+		attempts: make(chan *queueEntry, 1024),
+		quit:     make(chan struct{}),
+	}
+}
+
+func (e *mutexEngine) exec(op func()) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	op()
+}
+
+func (e *mutexEngine) scheduleAttempt(entry *queueEntry, delay time.Duration) {
+	e.startWorker.Do(func() {
+		go e.superviseWorker()
+	})
+	entry.timer = time.AfterFunc(delay, func() {
+		select {
+		case e.attempts <- entry:
+		case <-entry.stop:
+		case <-e.quit:
+		}
+	})
+}
+
+func (e *mutexEngine) cancelAttempt(entry *queueEntry) {
+	if entry.timer != nil {
+		entry.timer.Stop()
+	}
+}
+
+func (e *mutexEngine) stop() {
+	close(e.quit)
+}
+
+// superviseWorker runs the worker and replaces it when an attempt panics
+func (e *mutexEngine) superviseWorker() {
+	for !e.runWorker() {
+		log.Printf("Matchmaking worker restarted after a panic")
+	}
+}
+
+// runWorker processes attempts until the engine is stopped. Returns false if an attempt panicked
+func (e *mutexEngine) runWorker() (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Matchmaking worker recovered from a panic: %v", r)
+			ok = false
+		}
+	}()
+	for {
+		select {
+		case entry := <-e.attempts:
+			e.exec(func() {
+				runAttempt(entry)
+			})
+		case <-e.quit:
+			return true
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package matchmaking
+
+import (
+	"leaderboard/internal/config"
+	"leaderboard/internal/model"
+	"slices"
+)
+
+// oldestLobbyStrategy fills the oldest waiting competition first so competitions start in the order they are created.
+// A player joins the oldest competition within config.SkillWindow levels, and after the wait duration
+// is merged with the oldest competitions of any level.
+type oldestLobbyStrategy struct {
+	// Waiting competitions in the order they are created
+	lobbies []model.ICompetition
+}
+
+func (s *oldestLobbyStrategy) Enqueue(player *model.Player) (model.ICompetition, error) {
+	for _, comp := range s.lobbies {
+		if levelDistance(comp, player) <= config.SkillWindow {
+			if err := comp.AddPlayer(player); err != nil {
+				return nil, err
+			}
+			return comp, nil
+		}
+	}
+
+	comp, err := createNewCompetition(player)
+	if err != nil {
+		return nil, err
+	}
+	s.lobbies = append(s.lobbies, comp)
+	return comp, nil
+}
+
+func (s *oldestLobbyStrategy) Tick(player *model.Player) []model.ICompetition {
+	own := player.Competition()
+	return slices.DeleteFunc(slices.Clone(s.lobbies), func(comp model.ICompetition) bool {
+		return comp == own
+	})
+}
+
+func (s *oldestLobbyStrategy) Dequeue(comp model.ICompetition) {
+	s.lobbies = removeLobby(s.lobbies, comp)
+}
+
+func (s *oldestLobbyStrategy) Cancel(player *model.Player) {}
+
+func (s *oldestLobbyStrategy) Requeue(comp model.ICompetition) {
+	s.lobbies = append(s.lobbies, comp)
+}
+
+// LevelWindow is the skill window until the first attempt, then all levels
+func (s *oldestLobbyStrategy) LevelWindow(player *model.Player, attempts int) (int, int) {
+	if attempts > 0 {
+		return config.MinLevel, config.MaxLevel
+	}
+	return levelWindow(player.Level(), config.SkillWindow)
+}
@@ -0,0 +1,199 @@
+package matchmaking
+
+import (
+	"errors"
+	"leaderboard/internal/config"
+	"leaderboard/internal/model"
+	"leaderboard/internal/storage"
+	"leaderboard/internal/timeprovider"
+	"math"
+	"time"
+)
+
+var ErrPlayerNotQueued = errors.New("player has not joined matchmaking")
+
+// Queue states of a player who joined matchmaking
+const (
+	// QueueStateWaiting is the state of a player waiting in a competition that has not started
+	QueueStateWaiting = "waiting"
+	// QueueStateMatched is the terminal state of a player whose competition has started
+	QueueStateMatched = "matched"
+	// QueueStateTimeout is the terminal state of a player who was not matched within config.MatchMaxWait
+	QueueStateTimeout = "match_timeout"
+	// QueueStateCancelled is the terminal state of a player who left the queue
+	QueueStateCancelled = "cancelled"
+	// QueueStateFailed is the terminal state of a player whose attempts to start a competition kept failing
+	QueueStateFailed = "match_failed"
+)
+
+// queueEntry tracks a player from joining matchmaking until a terminal state.
+// Entries are only accessed by operations run by the matchmaking engine.
+type queueEntry struct {
+	player     *model.Player
+	state      string
+	joinedAt   time.Time
+	finishedAt time.Time // When the entry reached a terminal state
+	// Number of attempts to start the competition of the player
+	attempts int
+	// Number of consecutive failed attempts and the last error
+	failures  int
+	lastError string
+	// Order of joining, the oldest waiting player of a competition tries to start it
+	seq uint64
+	// Next attempt to start the competition
+	nextAttemptAt time.Time
+	// Closed when the entry reaches a terminal state so a due attempt is not run
+	stop chan struct{}
+	// Scheduled attempt of the mutex engine
+	timer *time.Timer
+	// Slot of the timing wheel of the event loop engine
+	wheelSlot int
+}
+
+var (
+	// Queue entries by player ID. Entries in a terminal state are kept until the player joins again or is deleted
+	queue = make(map[string]*queueEntry)
+	// Sequence number of the last queue entry
+	queueSeq uint64
+)
+
+func enqueuePlayer(player *model.Player) *queueEntry {
+	if entry, found := queue[player.Id()]; found {
+		entry.finish(QueueStateCancelled)
+	}
+	queueSeq++
+	entry := &queueEntry{
+		player:   player,
+		state:    QueueStateWaiting,
+		joinedAt: timeprovider.Current.Now(),
+		seq:      queueSeq,
+		stop:     make(chan struct{}),
+	}
+	queue[player.Id()] = entry
+	// Try starting a competition after the wait duration
+	entry.scheduleAttempt(config.MatchWaitDuration)
+	return entry
+}
+
+// waitingEntry returns the queue entry of a player waiting for a match, or nil
+func waitingEntry(player *model.Player) *queueEntry {
+	entry, found := queue[player.Id()]
+	if !found || entry.player != player || entry.state != QueueStateWaiting {
+		return nil
+	}
+	return entry
+}
+
+// finish moves a waiting entry to a terminal state and stops its timer
+func (e *queueEntry) finish(state string) {
+	if e.state != QueueStateWaiting {
+		return
+	}
+	e.state = state
+	e.finishedAt = timeprovider.Current.Now()
+	engine.cancelAttempt(e)
+	close(e.stop)
+}
+
+// scheduleAttempt runs an attempt to start the competition of the player after the delay
+func (e *queueEntry) scheduleAttempt(delay time.Duration) {
+	e.nextAttemptAt = time.Now().Add(delay)
+	engine.scheduleAttempt(e, delay)
+}
+
+// oldestInCompetition returns the entry of the player waiting the longest in a competition
+func oldestInCompetition(comp model.ICompetition) *queueEntry {
+	var oldest *queueEntry
+	for _, compPlayer := range comp.PlayersMap() {
+		if entry := waitingEntry(compPlayer.Player()); entry != nil && (oldest == nil || entry.seq < oldest.seq) {
+			oldest = entry
+		}
+	}
+	return oldest
+}
+
+// isOldestInCompetition reports whether no other player waiting in the competition joined before this entry.
+// Only the oldest player tries to start or merge a competition, so competitions are matched in the order they were joined.
+func (e *queueEntry) isOldestInCompetition(comp model.ICompetition) bool {
+	oldest := oldestInCompetition(comp)
+	return oldest == nil || oldest == e
+}
+
+// markMatched moves the entries of all players of a started competition to the matched state
+func markMatched(comp model.ICompetition) {
+	for _, compPlayer := range comp.PlayersMap() {
+		if entry := waitingEntry(compPlayer.Player()); entry != nil {
+			entry.finish(QueueStateMatched)
+		}
+	}
+}
+
+// QueueStatus describes the matchmaking progress of a player
+type QueueStatus struct {
+	PlayerId      string `json:"player_id"`
+	State         string `json:"state"`
+	WaitedSeconds int64  `json:"waited_seconds"`
+	CompetitionId string `json:"competition_id,omitempty"`
+	// Number of players in the waiting competition
+	LobbySize int `json:"lobby_size,omitempty"`
+	// Levels currently searched for matching competitions
+	MinLevel int `json:"min_level,omitempty"`
+	MaxLevel int `json:"max_level,omitempty"`
+	// Estimated time until the next attempt to start the waiting competition
+	EstimatedStartSeconds int64 `json:"estimated_start_seconds"`
+	// Number of consecutive failed attempts and the last error
+	Failures  int    `json:"failures,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// GetQueueStatus returns the queue state of a player who has joined matchmaking
+var GetQueueStatus = func(playerID string) (status *QueueStatus, err error) {
+	if playerID == "" {
+		return nil, ErrPlayerIdEmpty
+	}
+	engine.exec(func() {
+		status, err = getQueueStatus(playerID)
+	})
+	return status, err
+}
+
+func getQueueStatus(playerID string) (*QueueStatus, error) {
+	player, playerFound := storage.Current.GetPlayer(playerID)
+	if !playerFound {
+		return nil, ErrPlayerNotFound
+	}
+	entry, entryFound := queue[playerID]
+	if !entryFound || entry.player != player {
+		return nil, ErrPlayerNotQueued
+	}
+
+	status := &QueueStatus{
+		PlayerId:  playerID,
+		State:     entry.state,
+		Failures:  entry.failures,
+		LastError: entry.lastError,
+	}
+	waitedUntil := timeprovider.Current.Now()
+	if entry.state != QueueStateWaiting {
+		waitedUntil = entry.finishedAt
+	}
+	status.WaitedSeconds = int64(waitedUntil.Sub(entry.joinedAt).Seconds())
+
+	comp := player.Competition()
+	if comp == nil {
+		// Player has timed out or left the queue
+		return status, nil
+	}
+	status.CompetitionId = comp.Id()
+	if entry.state != QueueStateWaiting {
+		return status, nil
+	}
+
+	status.LobbySize = len(comp.PlayersMap())
+	status.MinLevel, status.MaxLevel = strategy.LevelWindow(player, entry.attempts)
+	// The competition is started or merged by its oldest player
+	if oldest := oldestInCompetition(comp); oldest != nil {
+		status.EstimatedStartSeconds = int64(math.Ceil(max(time.Until(oldest.nextAttemptAt), 0).Seconds()))
+	}
+	return status, nil
+}
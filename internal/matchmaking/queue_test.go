@@ -0,0 +1,269 @@
+package matchmaking
+
+import (
+	"leaderboard/internal/config"
+	"leaderboard/internal/storage"
+	"leaderboard/internal/timeprovider"
+	"testing"
+	"time"
+)
+
+func assertStopped(t *testing.T, entry *queueEntry) {
+	t.Helper()
+	select {
+	case <-entry.stop:
+	default:
+		t.Errorf("expected the wait of %s to be stopped", entry.player.Id())
+	}
+}
+
+func TestJoinCompetition_NoMatchWithinMaxWait_PlayerTimesOut(t *testing.T) {
+	setup()
+	defer tearDown()
+	config.MatchWaitDuration = 10 * time.Millisecond
+	config.MatchRetryInterval = 10 * time.Millisecond
+	config.MatchMaxWait = 50 * time.Millisecond
+
+	comp, err := JoinCompetition("alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	alice, _ := storage.Current.GetPlayer("alice")
+
+	time.Sleep(200 * time.Millisecond)
+
+	engine.exec(func() {
+		entry := queue["alice"]
+		if entry == nil || entry.state != QueueStateTimeout {
+			t.Errorf("expected alice to be in the %s state, got %v", QueueStateTimeout, entry)
+			return
+		}
+		assertStopped(t, entry)
+		if alice.Competition() != nil {
+			t.Errorf("expected alice to be removed from her competition")
+		}
+		if _, found := storage.Current.GetCompetition(comp.Id()); found {
+			t.Errorf("expected the empty competition to be discarded")
+		}
+		if len(waitingLobbies()) != 0 {
+			t.Errorf("waiting lobbies should be empty, got %v", waitingLobbies())
+		}
+	})
+}
+
+func TestTryStartCompetition_BeforeMaxWait_PlayerKeepsWaiting(t *testing.T) {
+	setup()
+	defer tearDown()
+	config.MatchWaitDuration = 1 * time.Hour
+	now := time.Now()
+	timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: now}
+	defer func() { timeprovider.Current = timeprovider.RealTimeProvider{} }()
+
+	joinForTest(t, "alice")
+	alice, _ := storage.Current.GetPlayer("alice")
+
+	timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: now.Add(config.MatchMaxWait - time.Second)}
+	if err := tryStartCompetition(alice); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if queue["alice"].state != QueueStateWaiting || alice.Competition() == nil {
+		t.Fatalf("expected alice to keep waiting before the maximum wait duration")
+	}
+
+	timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: now.Add(config.MatchMaxWait)}
+	if err := tryStartCompetition(alice); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if queue["alice"].state != QueueStateTimeout || alice.Competition() != nil {
+		t.Errorf("expected alice to time out after the maximum wait duration")
+	}
+}
+
+func TestTryStartCompetition_CompetitionStarted_AllPlayersMatched(t *testing.T) {
+	setup()
+	defer tearDown()
+	config.MatchWaitDuration = 1 * time.Hour
+
+	joinForTest(t, "bob", "bob_1")
+	bob, _ := storage.Current.GetPlayer("bob")
+
+	if err := tryStartCompetition(bob); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, playerId := range []string{"bob", "bob_1"} {
+		entry := queue[playerId]
+		if entry.state != QueueStateMatched {
+			t.Errorf("expected %s to be in the %s state, got %s", playerId, QueueStateMatched, entry.state)
+		}
+		assertStopped(t, entry)
+	}
+}
+
+func TestCancelMatchmaking_WaitingPlayer_RemovedFromQueue(t *testing.T) {
+	setup()
+	defer tearDown()
+	config.MatchWaitDuration = 1 * time.Hour
+
+	joinForTest(t, "bob", "bob_1")
+	bob, _ := storage.Current.GetPlayer("bob")
+	bob1, _ := storage.Current.GetPlayer("bob_1")
+	comp := bob.Competition()
+
+	if err := CancelMatchmaking("bob"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entry := queue["bob"]
+	if entry.state != QueueStateCancelled {
+		t.Errorf("expected bob to be in the %s state, got %s", QueueStateCancelled, entry.state)
+	}
+	assertStopped(t, entry)
+	if bob.Competition() != nil {
+		t.Errorf("expected bob to be removed from his competition")
+	}
+	if bob1.Competition() != comp || len(comp.PlayersMap()) != 1 {
+		t.Errorf("expected bob_1 to keep waiting in the competition")
+	}
+	if queue["bob_1"].state != QueueStateWaiting {
+		t.Errorf("expected bob_1 to keep waiting, got %s", queue["bob_1"].state)
+	}
+
+	// bob_1 is now the oldest waiting player and starts the competition once another player joins
+	joinForTest(t, "bob_2")
+	if err := tryStartCompetition(bob1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if comp.StartedAt().IsZero() {
+		t.Errorf("expected the competition to start without bob")
+	}
+}
+
+func TestCancelMatchmaking_Errors(t *testing.T) {
+	setup()
+	defer tearDown()
+	config.MatchWaitDuration = 1 * time.Hour
+
+	tests := []struct {
+		name          string
+		playerId      string
+		expectedError error
+	}{
+		{"Empty player Id", "", ErrPlayerIdEmpty},
+		{"Unknown player Id", "unknown", ErrPlayerNotFound},
+		{"Player not waiting", "alice", ErrPlayerNotWaiting},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CancelMatchmaking(tt.playerId); err != tt.expectedError {
+				t.Errorf("CancelMatchmaking() error = %v, expectedError %v", err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestGetQueueStatus_WaitingPlayer(t *testing.T) {
+	setup()
+	defer tearDown()
+	config.MatchWaitDuration = 1 * time.Hour
+	now := time.Now()
+	timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: now}
+	defer func() { timeprovider.Current = timeprovider.RealTimeProvider{} }()
+
+	joinForTest(t, "bob", "bob_1")
+	bob, _ := storage.Current.GetPlayer("bob")
+	timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: now.Add(10 * time.Second)}
+
+	status, err := GetQueueStatus("bob_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.State != QueueStateWaiting || status.WaitedSeconds != 10 {
+		t.Errorf("expected bob_1 waiting for 10s, got %s for %ds", status.State, status.WaitedSeconds)
+	}
+	if status.CompetitionId != bob.Competition().Id() || status.LobbySize != 2 {
+		t.Errorf("expected bob_1 in bob's competition with 2 players, got %s with %d", status.CompetitionId, status.LobbySize)
+	}
+	if status.MinLevel != 2 || status.MaxLevel != 2 {
+		t.Errorf("expected level window 2-2 before the first attempt, got %d-%d", status.MinLevel, status.MaxLevel)
+	}
+	// The competition is started by bob after the wait duration
+	if status.EstimatedStartSeconds < 3500 || status.EstimatedStartSeconds > 3600 {
+		t.Errorf("expected the start to be estimated after the wait duration, got %ds", status.EstimatedStartSeconds)
+	}
+}
+
+func TestGetQueueStatus_AfterFirstAttempt_LevelWindowWidened(t *testing.T) {
+	setup()
+	defer tearDown()
+	config.MatchWaitDuration = 1 * time.Hour
+
+	joinForTest(t, "bob")
+	// Process the attempt as the engine does, so the next one is scheduled
+	engine.exec(func() {
+		runAttempt(queue["bob"])
+	})
+
+	status, err := GetQueueStatus("bob")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.MinLevel != config.MinLevel || status.MaxLevel != config.MaxLevel {
+		t.Errorf("expected all levels to be searched after the first attempt, got %d-%d", status.MinLevel, status.MaxLevel)
+	}
+	if status.EstimatedStartSeconds > 1 {
+		t.Errorf("expected the next attempt within the retry interval, got %ds", status.EstimatedStartSeconds)
+	}
+}
+
+func TestGetQueueStatus_TerminalStates(t *testing.T) {
+	setup()
+	defer tearDown()
+	config.MatchWaitDuration = 1 * time.Hour
+
+	joinForTest(t, "bob", "bob_1", "alice")
+	bob, _ := storage.Current.GetPlayer("bob")
+	if err := tryStartCompetition(bob); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := CancelMatchmaking("alice"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	matched, err := GetQueueStatus("bob_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if matched.State != QueueStateMatched || matched.CompetitionId != bob.Competition().Id() || matched.LobbySize != 0 {
+		t.Errorf("expected bob_1 matched in bob's competition, got %+v", matched)
+	}
+	cancelled, err := GetQueueStatus("alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cancelled.State != QueueStateCancelled || cancelled.CompetitionId != "" {
+		t.Errorf("expected alice cancelled without a competition, got %+v", cancelled)
+	}
+}
+
+func TestGetQueueStatus_Errors(t *testing.T) {
+	setup()
+	defer tearDown()
+
+	tests := []struct {
+		name          string
+		playerId      string
+		expectedError error
+	}{
+		{"Empty player Id", "", ErrPlayerIdEmpty},
+		{"Unknown player Id", "unknown", ErrPlayerNotFound},
+		{"Player not queued", "alice", ErrPlayerNotQueued},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := GetQueueStatus(tt.playerId); err != tt.expectedError {
+				t.Errorf("GetQueueStatus() error = %v, expectedError %v", err, tt.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package matchmaking
+
+import (
+	"leaderboard/internal/config"
+	"leaderboard/internal/model"
+	"slices"
+)
+
+// skillStrategy uses the player level as skill rating. A player joins the waiting competition with the closest
+// level within config.SkillWindow levels. The window widens by one level on every retry after the wait duration.
+type skillStrategy struct {
+	// Waiting competitions in the order they are created
+	lobbies []model.ICompetition
+	// Number of retries for each waiting player, used to widen the window
+	retries map[string]int
+}
+
+func newSkillStrategy() *skillStrategy {
+	return &skillStrategy{retries: make(map[string]int)}
+}
+
+func (s *skillStrategy) Enqueue(player *model.Player) (model.ICompetition, error) {
+	if comp := s.closestLobby(player, nil, config.SkillWindow); comp != nil {
+		if err := comp.AddPlayer(player); err != nil {
+			return nil, err
+		}
+		return comp, nil
+	}
+
+	comp, err := createNewCompetition(player)
+	if err != nil {
+		return nil, err
+	}
+	s.lobbies = append(s.lobbies, comp)
+	return comp, nil
+}
+
+func (s *skillStrategy) Tick(player *model.Player) []model.ICompetition {
+	s.retries[player.Id()]++
+	return s.lobbiesWithin(player, player.Competition(), config.SkillWindow+s.retries[player.Id()])
+}
+
+func (s *skillStrategy) Dequeue(comp model.ICompetition) {
+	s.lobbies = removeLobby(s.lobbies, comp)
+	for playerId := range comp.PlayersMap() {
+		delete(s.retries, playerId)
+	}
+}
+
+func (s *skillStrategy) Cancel(player *model.Player) {
+	delete(s.retries, player.Id())
+}
+
+func (s *skillStrategy) Requeue(comp model.ICompetition) {
+	s.lobbies = append(s.lobbies, comp)
+}
+
+func (s *skillStrategy) LevelWindow(player *model.Player, attempts int) (int, int) {
+	return levelWindow(player.Level(), config.SkillWindow+s.retries[player.Id()])
+}
+
+// closestLobby finds the waiting competition with the closest level within the window
+func (s *skillStrategy) closestLobby(player *model.Player, own model.ICompetition, window int) model.ICompetition {
+	if lobbies := s.lobbiesWithin(player, own, window); len(lobbies) > 0 {
+		return lobbies[0]
+	}
+	return nil
+}
+
+// lobbiesWithin returns the waiting competitions within the window, the closest level first.
+// Ties prefer the competition with more players, then the oldest one.
+func (s *skillStrategy) lobbiesWithin(player *model.Player, own model.ICompetition, window int) []model.ICompetition {
+	var lobbies []model.ICompetition
+	for _, comp := range s.lobbies {
+		if comp != own && levelDistance(comp, player) <= window {
+			lobbies = append(lobbies, comp)
+		}
+	}
+	slices.SortStableFunc(lobbies, func(a, b model.ICompetition) int {
+		if distanceA, distanceB := levelDistance(a, player), levelDistance(b, player); distanceA != distanceB {
+			return distanceA - distanceB
+		}
+		return len(b.PlayersMap()) - len(a.PlayersMap())
+	})
+	return lobbies
+}
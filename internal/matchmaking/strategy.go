@@ -0,0 +1,95 @@
+package matchmaking
+
+import (
+	"errors"
+	"leaderboard/internal/config"
+	"leaderboard/internal/model"
+	"slices"
+)
+
+// Matchmaking modes, each selecting a strategy
+const (
+	// ModeLevel groups waiting players by level only
+	ModeLevel = "level"
+	// ModeCountry groups waiting players by level and country code
+	ModeCountry = "country"
+	// ModeSkill matches players whose level is within a window that widens while they wait
+	ModeSkill = "skill"
+	// ModeFifo pools all players in a single waiting competition in arrival order
+	ModeFifo = "fifo"
+	// ModeOldest fills the oldest waiting competition first
+	ModeOldest = "oldest"
+)
+
+var ErrUnknownStrategy = errors.New("unknown matchmaking strategy")
+
+// MatchmakingStrategy decides which waiting competition a player joins and which competitions are merged
+// when a player is not matched within the wait duration.
+// Strategies are only called by operations run by the matchmaking engine, so they don't need their own locking.
+type MatchmakingStrategy interface {
+	// Enqueue adds a player to a waiting competition, creating one with createNewCompetition if needed
+	Enqueue(player *model.Player) (model.ICompetition, error)
+	// Tick is called when the competition of a waiting player doesn't have enough players after the wait duration,
+	// and again on every retry. It returns the other waiting competitions that can be merged with it, best match first.
+	Tick(player *model.Player) []model.ICompetition
+	// Dequeue removes a competition from the waiting pool because it has started or has no players left
+	Dequeue(comp model.ICompetition)
+	// Cancel is called before a waiting player is removed from their competition
+	Cancel(player *model.Player)
+	// Requeue adds a waiting competition restored from storage back to the pool
+	Requeue(comp model.ICompetition)
+	// LevelWindow returns the levels currently searched for a waiting player after the given number of start attempts
+	LevelWindow(player *model.Player, attempts int) (minLevel int, maxLevel int)
+}
+
+var strategies = map[string]func() MatchmakingStrategy{
+	ModeLevel:   func() MatchmakingStrategy { return newLevelStrategy(false) },
+	ModeCountry: func() MatchmakingStrategy { return newLevelStrategy(true) },
+	ModeSkill:   func() MatchmakingStrategy { return newSkillStrategy() },
+	ModeFifo:    func() MatchmakingStrategy { return &fifoStrategy{} },
+	ModeOldest:  func() MatchmakingStrategy { return &oldestLobbyStrategy{} },
+}
+
+// SetStrategy replaces the matchmaking strategy with a new one for the given mode.
+// Competitions already waiting are not moved to the new strategy, so this should be called before players join.
+func SetStrategy(mode string) error {
+	newStrategy, found := strategies[mode]
+	if !found {
+		return ErrUnknownStrategy
+	}
+
+	engine.exec(func() {
+		strategy = newStrategy()
+		strategyMode = mode
+	})
+	return nil
+}
+
+// Modes returns the available matchmaking modes in alphabetical order
+func Modes() []string {
+	modes := make([]string, 0, len(strategies))
+	for mode := range strategies {
+		modes = append(modes, mode)
+	}
+	slices.Sort(modes)
+	return modes
+}
+
+// removeLobby removes a competition from a slice of waiting competitions, keeping their order
+func removeLobby(lobbies []model.ICompetition, comp model.ICompetition) []model.ICompetition {
+	return slices.DeleteFunc(lobbies, func(c model.ICompetition) bool {
+		return c == comp
+	})
+}
+
+// levelWindow returns the levels within width of a level, limited to the valid levels
+func levelWindow(level int, width int) (int, int) {
+	return max(level-width, config.MinLevel), min(level+width, config.MaxLevel)
+}
+
+func levelDistance(comp model.ICompetition, player *model.Player) int {
+	if comp.InitialLevel() > player.Level() {
+		return comp.InitialLevel() - player.Level()
+	}
+	return player.Level() - comp.InitialLevel()
+}
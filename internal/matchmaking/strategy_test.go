@@ -0,0 +1,178 @@
+package matchmaking
+
+import (
+	"leaderboard/internal/config"
+	"leaderboard/internal/storage"
+	"testing"
+	"time"
+)
+
+func setupStrategy(t *testing.T, mode string) {
+	if err := SetStrategy(mode); err != nil {
+		t.Fatalf("unexpected error setting strategy %s: %v", mode, err)
+	}
+	// Keep the wait timers from firing, tryStartCompetition is called directly
+	config.MatchWaitDuration = 1 * time.Hour
+	storage.AddPlayers([]storage.NewPlayer{
+		{Id: "level_1", CountryCode: "US", Level: 1},
+		{Id: "level_2", CountryCode: "GB", Level: 2},
+		{Id: "level_4", CountryCode: "DE", Level: 4},
+		{Id: "level_5", CountryCode: "JP", Level: 5},
+		{Id: "level_9", CountryCode: "IN", Level: 9},
+	})
+}
+
+func tearDownStrategy() {
+	SetStrategy(ModeLevel)
+	tearDown()
+}
+
+func TestSetStrategy_UnknownMode(t *testing.T) {
+	if err := SetStrategy("unknown"); err != ErrUnknownStrategy {
+		t.Errorf("expected ErrUnknownStrategy, got %v", err)
+	}
+	if _, ok := strategy.(*levelStrategy); !ok {
+		t.Errorf("expected the strategy to stay unchanged, got %T", strategy)
+	}
+}
+
+func TestModes(t *testing.T) {
+	modes := Modes()
+	expected := []string{ModeCountry, ModeFifo, ModeLevel, ModeOldest, ModeSkill}
+	if len(modes) != len(expected) {
+		t.Fatalf("expected modes %v, got %v", expected, modes)
+	}
+	for i := range expected {
+		if modes[i] != expected[i] {
+			t.Errorf("expected modes %v, got %v", expected, modes)
+		}
+	}
+}
+
+func TestSkillStrategy_JoinsWithinWindow(t *testing.T) {
+	setupStrategy(t, ModeSkill)
+	defer tearDownStrategy()
+
+	joinForTest(t, "level_1", "level_2", "level_4")
+	level1, _ := storage.Current.GetPlayer("level_1")
+	level2, _ := storage.Current.GetPlayer("level_2")
+	level4, _ := storage.Current.GetPlayer("level_4")
+
+	if level1.Competition() != level2.Competition() {
+		t.Errorf("expected level_1 and level_2 to share a competition within the skill window")
+	}
+	if level4.Competition() == level2.Competition() {
+		t.Errorf("expected level_4 to wait in its own competition outside the skill window")
+	}
+}
+
+func TestSkillStrategy_WindowWidensOnEveryRetry(t *testing.T) {
+	setupStrategy(t, ModeSkill)
+	defer tearDownStrategy()
+
+	joinForTest(t, "level_5", "level_2")
+	level2, _ := storage.Current.GetPlayer("level_2")
+	level5, _ := storage.Current.GetPlayer("level_5")
+
+	// Window of 2 levels on the first retry, level_5 is 3 levels away
+	if err := tryStartCompetition(level2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if level2.Competition() == level5.Competition() {
+		t.Fatalf("expected level_2 to keep waiting on the first retry")
+	}
+
+	// Window of 3 levels on the second retry
+	if err := tryStartCompetition(level2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	comp := level2.Competition()
+	if comp != level5.Competition() {
+		t.Fatalf("expected level_2 to be matched with level_5 on the second retry")
+	}
+	if comp.StartedAt().IsZero() {
+		t.Errorf("expected the matched competition to start")
+	}
+	if len(strategy.(*skillStrategy).lobbies) != 0 {
+		t.Errorf("expected no waiting competitions, got %v", strategy.(*skillStrategy).lobbies)
+	}
+}
+
+func TestFifoStrategy_PoolsAllLevels(t *testing.T) {
+	setupStrategy(t, ModeFifo)
+	defer tearDownStrategy()
+
+	joinForTest(t, "level_1", "level_9")
+	level1, _ := storage.Current.GetPlayer("level_1")
+	level9, _ := storage.Current.GetPlayer("level_9")
+
+	if level1.Competition() != level9.Competition() {
+		t.Fatalf("expected level_1 and level_9 to share the single waiting competition")
+	}
+	if err := tryStartCompetition(level1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if level1.Competition().StartedAt().IsZero() {
+		t.Errorf("expected the competition to start after the wait duration")
+	}
+	if strategy.(*fifoStrategy).lobby != nil {
+		t.Errorf("expected no waiting competition after start")
+	}
+}
+
+func TestFifoStrategy_SinglePlayerKeepsWaiting(t *testing.T) {
+	setupStrategy(t, ModeFifo)
+	defer tearDownStrategy()
+
+	joinForTest(t, "level_1")
+	level1, _ := storage.Current.GetPlayer("level_1")
+
+	if err := tryStartCompetition(level1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !level1.Competition().StartedAt().IsZero() {
+		t.Errorf("expected the competition to keep waiting with a single player")
+	}
+}
+
+func TestOldestLobbyStrategy_FillsOldestLobbyFirst(t *testing.T) {
+	setupStrategy(t, ModeOldest)
+	defer tearDownStrategy()
+
+	joinForTest(t, "level_4", "level_1", "level_5", "level_2")
+	level1, _ := storage.Current.GetPlayer("level_1")
+	level2, _ := storage.Current.GetPlayer("level_2")
+	level4, _ := storage.Current.GetPlayer("level_4")
+	level5, _ := storage.Current.GetPlayer("level_5")
+
+	if level5.Competition() != level4.Competition() {
+		t.Errorf("expected level_5 to join the oldest lobby of level_4")
+	}
+	if level2.Competition() != level1.Competition() {
+		t.Errorf("expected level_2 to join the lobby of level_1")
+	}
+}
+
+func TestOldestLobbyStrategy_MovesToOldestLobbyAfterWait(t *testing.T) {
+	setupStrategy(t, ModeOldest)
+	defer tearDownStrategy()
+
+	joinForTest(t, "level_4", "level_9", "level_1")
+	level1, _ := storage.Current.GetPlayer("level_1")
+	level4, _ := storage.Current.GetPlayer("level_4")
+	level9, _ := storage.Current.GetPlayer("level_9")
+
+	if err := tryStartCompetition(level1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if level1.Competition() != level4.Competition() {
+		t.Fatalf("expected level_1 to move to the oldest lobby of level_4")
+	}
+	if level1.Competition().StartedAt().IsZero() {
+		t.Errorf("expected the oldest lobby to start")
+	}
+	lobbies := strategy.(*oldestLobbyStrategy).lobbies
+	if len(lobbies) != 1 || lobbies[0] != level9.Competition() {
+		t.Errorf("expected only the lobby of level_9 to keep waiting, got %v", lobbies)
+	}
+}
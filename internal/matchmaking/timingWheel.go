@@ -0,0 +1,63 @@
+package matchmaking
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// timingWheel schedules the attempts of the loop engine without a timer per waiting player.
+// Each slot holds the entries due when the wheel reaches it, with the number of full rounds left before they are due.
+type timingWheel struct {
+	tick    time.Duration
+	slots   []map[*queueEntry]int
+	current int
+	// Time of the current slot
+	now time.Time
+}
+
+func newTimingWheel(tick time.Duration, size int, now time.Time) *timingWheel {
+	slots := make([]map[*queueEntry]int, size)
+	for i := range slots {
+		slots[i] = make(map[*queueEntry]int)
+	}
+	return &timingWheel{tick: tick, slots: slots, now: now}
+}
+
+// add schedules an entry after the delay, rounded up to the next tick
+func (w *timingWheel) add(entry *queueEntry, now time.Time, delay time.Duration) {
+	ticks := int((now.Sub(w.now) + delay + w.tick - 1) / w.tick)
+	if ticks < 1 {
+		ticks = 1
+	}
+	slot := (w.current + ticks) % len(w.slots)
+	w.slots[slot][entry] = (ticks - 1) / len(w.slots)
+	entry.wheelSlot = slot
+}
+
+// remove cancels the scheduled attempt of an entry
+func (w *timingWheel) remove(entry *queueEntry) {
+	delete(w.slots[entry.wheelSlot], entry)
+}
+
+// advance moves the wheel to the given time and returns the due entries in the order the players joined
+func (w *timingWheel) advance(now time.Time) []*queueEntry {
+	var due []*queueEntry
+	for !now.Before(w.now.Add(w.tick)) {
+		w.now = w.now.Add(w.tick)
+		w.current = (w.current + 1) % len(w.slots)
+		slot := w.slots[w.current]
+		for entry, rounds := range slot {
+			if rounds == 0 {
+				due = append(due, entry)
+				delete(slot, entry)
+			} else {
+				slot[entry] = rounds - 1
+			}
+		}
+	}
+	slices.SortFunc(due, func(a, b *queueEntry) int {
+		return cmp.Compare(a.seq, b.seq)
+	})
+	return due
+}
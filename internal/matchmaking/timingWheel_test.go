@@ -0,0 +1,44 @@
+package matchmaking
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimingWheel_Advance_ReturnsDueEntriesInJoinOrder(t *testing.T) {
+	start := time.Now()
+	wheel := newTimingWheel(10*time.Millisecond, 4, start)
+	first := &queueEntry{seq: 1}
+	second := &queueEntry{seq: 2}
+	later := &queueEntry{seq: 3}
+	wheel.add(second, start, 15*time.Millisecond)
+	wheel.add(first, start, 20*time.Millisecond)
+	// Further than the size of the wheel, due after a full round
+	wheel.add(later, start, 60*time.Millisecond)
+
+	if due := wheel.advance(start.Add(10 * time.Millisecond)); len(due) != 0 {
+		t.Errorf("expected no due entries after the first tick, got %d", len(due))
+	}
+	due := wheel.advance(start.Add(25 * time.Millisecond))
+	if len(due) != 2 || due[0] != first || due[1] != second {
+		t.Errorf("expected the first and second entries in join order, got %v", due)
+	}
+	if due := wheel.advance(start.Add(50 * time.Millisecond)); len(due) != 0 {
+		t.Errorf("expected the later entry to wait for the next round, got %d due entries", len(due))
+	}
+	if due := wheel.advance(start.Add(60 * time.Millisecond)); len(due) != 1 || due[0] != later {
+		t.Errorf("expected the later entry after a full round, got %v", due)
+	}
+}
+
+func TestTimingWheel_Remove_EntryIsNotDue(t *testing.T) {
+	start := time.Now()
+	wheel := newTimingWheel(10*time.Millisecond, 4, start)
+	entry := &queueEntry{seq: 1}
+	wheel.add(entry, start, 10*time.Millisecond)
+	wheel.remove(entry)
+
+	if due := wheel.advance(start.Add(100 * time.Millisecond)); len(due) != 0 {
+		t.Errorf("expected the removed entry not to be due, got %v", due)
+	}
+}
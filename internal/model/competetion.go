@@ -3,16 +3,13 @@ package model
 import (
 	"errors"
 	"leaderboard/internal/config"
+	"leaderboard/internal/events"
 	"leaderboard/internal/timeprovider"
-	"maps"
 	"slices"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 // ICompetition defines the contract for a competition
@@ -22,20 +19,67 @@ type ICompetition interface {
 	EndsAt() time.Time
 	PlayersMap() map[string]*CompetingPlayer
 	Leaderboard() []*CompetingPlayer
+	// Standings returns at most count players of the leaderboard from the 0-based offset, ranked with config.RankTiePolicy
+	Standings(offset, count int) []RankedPlayer
+	// Position returns the 0-based position of a player in the leaderboard
+	Position(playerId string) (int, bool)
+	// RankedCount returns the number of players in the leaderboard, disqualified players excluded
+	RankedCount() int
 	AddPlayer(player *Player) error
+	RemovePlayer(playerId string) error
 	Start() error
 	AddScore(playerId string, points int) error
+	// SubmitScore applies a score like AddScore unless a submission with the same ID was applied for the player
+	// less than config.SubmissionIdTTL ago. Returns false for such replays. An empty submission ID is always applied.
+	SubmitScore(playerId string, points int, submissionId string) (bool, error)
+	// SubmitScores applies submissions in order like SubmitScore, locking the scores once for all of them
+	SubmitScores(submissions []ScoreSubmission) []SubmissionResult
+	// History returns the accepted submissions of a player in the order they were applied
+	History(playerId string) ([]ScoreChange, bool)
+	// AppliedSubmissions returns the submissions with an ID applied less than config.SubmissionIdTTL ago
+	AppliedSubmissions() []AppliedSubmission
+	// VoidSubmission cancels a submission applied for a player and recomputes their score without it
+	VoidSubmission(playerId, submissionId, reason string) (ScoreChange, error)
+	// VoidChange cancels the submission with the sequence number of the history of a player, like VoidSubmission.
+	// Submissions sent without an ID can only be voided this way.
+	VoidChange(playerId string, seq int, reason string) (ScoreChange, error)
+	// AdjustScore adds points to the score of a player regardless of the scoring mode
+	AdjustScore(playerId string, points int, reason string) (ScoreChange, error)
+	// Disqualify removes a player from the ranking of a started competition. The player keeps their score and history
+	Disqualify(playerId string) error
+	// End freezes the scores of a started competition, unlinks its players and publishes their final standings
+	End() error
+	// Ended returns true once the competition has been ended with End
+	Ended() bool
+	// State returns a copy of the state of the competition to persist it
+	State() CompetitionState
+	// Submitted returns true if a submission with the ID was applied for the player less than config.SubmissionIdTTL ago
+	Submitted(playerId, submissionId string) bool
 	InitialLevel() int
+	// TieBreaker returns how players with the same score are ranked
+	TieBreaker() string
+	// SetTieBreaker changes the tie-breaker of a competition that has not started yet
+	SetTieBreaker(tieBreaker string) error
+	// ScoringMode returns how submitted scores change the score of a player
+	ScoringMode() string
+	// SetScoringMode changes the scoring mode of a competition that has not started yet
+	SetScoringMode(mode string) error
 }
 
 type Competition struct {
-	id            string
-	startedAt     time.Time
-	endsAt        time.Time
-	players       map[string]*CompetingPlayer
-	sortedPlayers []*CompetingPlayer
-	scoreMutex    *sync.Mutex
-	initialLevel  int
+	id           string
+	startedAt    time.Time
+	endsAt       time.Time
+	players      map[string]*CompetingPlayer
+	ranking      *RankIndex  // Players ordered by score, created when the competition starts
+	scoreMutex   *sync.Mutex // Locks the players, the ranking and the scores
+	initialLevel int
+	tieBreaker   string
+	scoringMode  string
+	submissions  *submissionLog
+	seq          int        // Sequence number of the last change of a score
+	points       pointStats // Points submitted by all the players
+	ended        bool       // Set by End, scores are not accepted anymore
 }
 
 var (
@@ -48,96 +92,444 @@ var (
 	ErrPlayerIdEmpty  = errors.New("player ID cannot be empty")
 	ErrPlayerNotFound = errors.New("player not found in competition")
 	ErrPointsNegative = errors.New("points cannot be negative")
-)
 
-var (
-	competetionsStarted = promauto.NewCounter(prometheus.CounterOpts{
-		Name: "leaderboard_competitions_started_total",
-		Help: "The total number of competitions started",
-	})
+	ErrPlayerDisqualified = errors.New("player is disqualified from this competition")
+	ErrSubmissionNotFound = errors.New("submission not found for player")
+
+	ErrCompetitionEnded = errors.New("competition has ended")
 )
 
 func NewCompetition(initialLevel int) ICompetition {
 	var comp = &Competition{
 		id:           uuid.New().String(),
 		initialLevel: initialLevel,
+		tieBreaker:   config.TieBreaker,
+		scoringMode:  config.ScoringMode,
 		startedAt:    time.Time{},
 		endsAt:       time.Time{},
 		players:      make(map[string]*CompetingPlayer, config.MaxPlayersForCompetition),
+		scoreMutex:   &sync.Mutex{},
+		submissions:  newSubmissionLog(),
+	}
+	return comp
+}
+
+// RestoreCompetition recreates a competition from persisted state.
+// Players are linked to the restored competition and the leaderboard is sorted if it has started.
+// Competitions persisted without a tie-breaker order tied players by player ID, and without a scoring mode add scores.
+func RestoreCompetition(id string, initialLevel int, tieBreaker, scoringMode string, startedAt, endsAt time.Time, players []*CompetingPlayer) *Competition {
+	if tieBreaker == "" {
+		tieBreaker = TieBreakerPlayerId
+	}
+	if scoringMode == "" {
+		scoringMode = ScoringIncrement
+	}
+	comp := &Competition{
+		id:           id,
+		initialLevel: initialLevel,
+		tieBreaker:   tieBreaker,
+		scoringMode:  scoringMode,
+		startedAt:    startedAt,
+		endsAt:       endsAt,
+		players:      make(map[string]*CompetingPlayer, config.MaxPlayersForCompetition),
+		scoreMutex:   &sync.Mutex{},
+		submissions:  newSubmissionLog(),
+	}
+	for _, compPlayer := range players {
+		comp.players[compPlayer.Player().Id()] = compPlayer
+		compPlayer.Player().SetCompetition(comp)
+		for _, change := range compPlayer.history {
+			comp.seq = max(comp.seq, change.Seq)
+		}
+		comp.points = comp.points.plus(compPlayer.points)
+	}
+	if !startedAt.IsZero() {
+		comp.rankPlayers()
 	}
 	return comp
 }
 
 func (c *Competition) AddPlayer(player *Player) error {
+	full, err := c.addPlayer(player)
+	if err != nil {
+		return err
+	}
+	if full {
+		if err := c.Start(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addPlayer adds a player while the players are locked and returns true if the competition is full
+func (c *Competition) addPlayer(player *Player) (bool, error) {
 	if player == nil {
-		return ErrPlayerIdEmpty
+		return false, ErrPlayerIdEmpty
 	}
+	c.scoreMutex.Lock()
+	defer c.scoreMutex.Unlock()
 	if len(c.players) >= config.MaxPlayersForCompetition {
-		return ErrCompetitionFull
+		return false, ErrCompetitionFull
 	}
 	if !c.startedAt.IsZero() {
-		return ErrCompetitionStarted
+		return false, ErrCompetitionStarted
 	}
 	if c.players[player.Id()] != nil {
-		return ErrPlayerAlreadyInCompetition
-	}
-	c.players[player.Id()] = &CompetingPlayer{
-		player: player,
-		score:  0,
+		return false, ErrPlayerAlreadyInCompetition
 	}
+	c.players[player.Id()] = NewCompetingPlayer(player)
 	player.SetCompetition(c)
+	return len(c.players) == config.MaxPlayersForCompetition, nil
+}
 
-	if len(c.players) == config.MaxPlayersForCompetition {
-		if err := c.Start(); err != nil {
-			return err
-		}
+// RemovePlayer removes a player from a competition that has not started yet
+func (c *Competition) RemovePlayer(playerId string) error {
+	if playerId == "" {
+		return ErrPlayerIdEmpty
+	}
+	c.scoreMutex.Lock()
+	defer c.scoreMutex.Unlock()
+	if !c.startedAt.IsZero() {
+		return ErrCompetitionStarted
+	}
+	compPlayer, found := c.players[playerId]
+	if !found {
+		return ErrPlayerNotFound
+	}
+	delete(c.players, playerId)
+	if compPlayer.Player().Competition() == c {
+		compPlayer.Player().SetCompetition(nil)
 	}
-
 	return nil
 }
 
 func (c *Competition) Start() error {
+	started, err := c.start()
+	if err != nil {
+		return err
+	}
+	events.Publish(started)
+	return nil
+}
+
+// start ranks the players and sets the start and end times while the players are locked
+func (c *Competition) start() (events.CompetitionStarted, error) {
+	c.scoreMutex.Lock()
+	defer c.scoreMutex.Unlock()
 	if !c.startedAt.IsZero() {
-		return ErrCompetitionStarted
+		return events.CompetitionStarted{}, ErrCompetitionStarted
 	}
 	if len(c.players) < config.MinPlayersForCompetition {
-		return ErrNotEnoughPlayers
+		return events.CompetitionStarted{}, ErrNotEnoughPlayers
 	}
-	c.sortedPlayers = slices.Collect(maps.Values(c.players))
-	c.scoreMutex = &sync.Mutex{}
+	c.rankPlayers()
 
 	c.startedAt = timeprovider.Current.Now()
 	c.endsAt = c.startedAt.Add(config.CompetitionDuration)
-	competetionsStarted.Inc()
-	return nil
+	playerIds := make([]string, 0, len(c.players))
+	for playerId := range c.players {
+		playerIds = append(playerIds, playerId)
+	}
+	slices.Sort(playerIds)
+	return events.CompetitionStarted{LeaderboardId: c.id, PlayerIds: playerIds, StartedAt: c.startedAt, EndsAt: c.endsAt}, nil
 }
 
+// AddScore applies a submitted score with the scoring mode of the competition
 func (c *Competition) AddScore(playerId string, points int) error {
-	if playerId == "" {
+	_, err := c.SubmitScore(playerId, points, "")
+	return err
+}
+
+func (c *Competition) SubmitScore(playerId string, points int, submissionId string) (bool, error) {
+	result := c.SubmitScores([]ScoreSubmission{{PlayerId: playerId, Points: points, SubmissionId: submissionId, Source: SourceApi}})[0]
+	return result.Applied, result.Err
+}
+
+// SubmitScores validates and applies the submissions in order while the competition is locked,
+// so a player disqualified or a competition ended concurrently cannot receive a score afterwards
+func (c *Competition) SubmitScores(submissions []ScoreSubmission) []SubmissionResult {
+	results := make([]SubmissionResult, len(submissions))
+	c.scoreMutex.Lock()
+	defer c.scoreMutex.Unlock()
+	now := timeprovider.Current.Now()
+	for i, submission := range submissions {
+		if results[i].Err = c.validateSubmission(submission); results[i].Err != nil {
+			continue
+		}
+		if c.ended {
+			results[i].Err = ErrCompetitionEnded
+			continue
+		}
+		results[i].Change, results[i].Applied, results[i].Err = c.submitScore(submission, now)
+	}
+	return results
+}
+
+// validateSubmission checks a submission against the competition. The scores must be locked.
+func (c *Competition) validateSubmission(submission ScoreSubmission) error {
+	if submission.PlayerId == "" {
 		return ErrPlayerIdEmpty
 	}
-	if points < 0 {
+	if submission.Points < 0 && c.scoringMode != ScoringPenalties {
 		return ErrPointsNegative
 	}
 	if c.startedAt.IsZero() {
 		return ErrCompetitionNotStarted
 	}
+	compPlayer, found := c.players[submission.PlayerId]
+	if !found {
+		return ErrPlayerNotFound
+	}
+	if compPlayer.disqualified {
+		return ErrPlayerDisqualified
+	}
+	return nil
+}
+
+// submitScore applies a valid submission while the scores are locked and adds it to the history of the player.
+// Returns false if the submission is a replay, or the error of its validation.
+func (c *Competition) submitScore(submission ScoreSubmission, now time.Time) (ScoreChange, bool, error) {
+	if submission.SubmissionId != "" && c.submissions.seen(submission.PlayerId, submission.SubmissionId, now) {
+		return ScoreChange{}, false, nil
+	}
+	compPlayer := c.players[submission.PlayerId]
+	if submission.Validate != nil {
+		if err := submission.Validate(compPlayer, c.points.minus(compPlayer.points).summary()); err != nil {
+			return ScoreChange{}, false, err
+		}
+	}
+	if submission.SubmissionId != "" {
+		c.submissions.add(AppliedSubmission{PlayerId: submission.PlayerId, SubmissionId: submission.SubmissionId, AppliedAt: now})
+	}
+	if isSubmission(submission.Source) {
+		compPlayer.points.add(submission.Points)
+		c.points.add(submission.Points)
+	}
+	window := int(now.Sub(c.startedAt) / config.ScoreWindow)
+	c.seq++
+	change := ScoreChange{
+		Seq:          c.seq,
+		At:           now,
+		Points:       submission.Points,
+		Delta:        compPlayer.submit(submission.Points, c.scoringMode, window, now),
+		Score:        compPlayer.Score(),
+		Source:       submission.Source,
+		SubmissionId: submission.SubmissionId,
+	}
+	compPlayer.history = append(compPlayer.history, change)
+	c.rerank(compPlayer, change)
+	return change, true, nil
+}
 
-	if compPlayer, found := c.players[playerId]; found {
-		c.scoreMutex.Lock()
-		defer c.scoreMutex.Unlock()
+func (c *Competition) VoidSubmission(playerId, submissionId, reason string) (ScoreChange, error) {
+	c.scoreMutex.Lock()
+	defer c.scoreMutex.Unlock()
+	compPlayer, err := c.moderatedPlayer(playerId)
+	if err != nil {
+		return ScoreChange{}, err
+	}
+	if submissionId == "" {
+		return ScoreChange{}, ErrSubmissionNotFound
+	}
+	index := slices.IndexFunc(compPlayer.history, func(change ScoreChange) bool {
+		return change.SubmissionId == submissionId && change.Source != SourceVoid && !change.Voided
+	})
+	if index < 0 {
+		return ScoreChange{}, ErrSubmissionNotFound
+	}
+	return c.void(compPlayer, index, reason), nil
+}
 
-		compPlayer.AddScore(points)
-		slices.SortStableFunc(c.sortedPlayers, func(a, b *CompetingPlayer) int {
-			if a.Score() == b.Score() {
-				return strings.Compare(a.Player().Id(), b.Player().Id())
-			} else {
-				return b.Score() - a.Score()
-			}
-		})
-		return nil
-	} else {
-		return ErrPlayerNotFound
+func (c *Competition) VoidChange(playerId string, seq int, reason string) (ScoreChange, error) {
+	c.scoreMutex.Lock()
+	defer c.scoreMutex.Unlock()
+	compPlayer, err := c.moderatedPlayer(playerId)
+	if err != nil {
+		return ScoreChange{}, err
+	}
+	index := slices.IndexFunc(compPlayer.history, func(change ScoreChange) bool {
+		return change.Seq == seq && isSubmission(change.Source) && !change.Voided
+	})
+	if index < 0 {
+		return ScoreChange{}, ErrSubmissionNotFound
+	}
+	return c.void(compPlayer, index, reason), nil
+}
+
+// void marks the submission at the index of the history of a player as voided, recomputes their score
+// and returns the change voiding it. The scores must be locked.
+func (c *Competition) void(compPlayer *CompetingPlayer, index int, reason string) ScoreChange {
+	previous := compPlayer.score
+	compPlayer.history[index].Voided = true
+	if isSubmission(compPlayer.history[index].Source) {
+		compPlayer.points.remove(compPlayer.history[index].Points)
+		c.points.remove(compPlayer.history[index].Points)
+	}
+	compPlayer.replay(c.scoringMode, c.startedAt)
+	c.seq++
+	change := ScoreChange{
+		Seq:          c.seq,
+		At:           timeprovider.Current.Now(),
+		Points:       compPlayer.history[index].Points,
+		Delta:        compPlayer.score - previous,
+		Score:        compPlayer.score,
+		Source:       SourceVoid,
+		SubmissionId: compPlayer.history[index].SubmissionId,
+		VoidedSeq:    compPlayer.history[index].Seq,
+		Reason:       reason,
+	}
+	compPlayer.history = append(compPlayer.history, change)
+	c.rerank(compPlayer, change)
+	return change
+}
+
+func (c *Competition) AdjustScore(playerId string, points int, reason string) (ScoreChange, error) {
+	c.scoreMutex.Lock()
+	defer c.scoreMutex.Unlock()
+	compPlayer, err := c.moderatedPlayer(playerId)
+	if err != nil {
+		return ScoreChange{}, err
+	}
+	now := timeprovider.Current.Now()
+	c.seq++
+	change := ScoreChange{
+		Seq:    c.seq,
+		At:     now,
+		Points: points,
+		Delta:  compPlayer.adjust(points, now),
+		Score:  compPlayer.score,
+		Source: SourceAdjustment,
+		Reason: reason,
+	}
+	compPlayer.history = append(compPlayer.history, change)
+	c.rerank(compPlayer, change)
+	return change, nil
+}
+
+func (c *Competition) Disqualify(playerId string) error {
+	c.scoreMutex.Lock()
+	defer c.scoreMutex.Unlock()
+	compPlayer, err := c.moderatedPlayer(playerId)
+	if err != nil {
+		return err
+	}
+	if compPlayer.disqualified {
+		return ErrPlayerDisqualified
+	}
+	previous := c.rankOf(playerId)
+	compPlayer.disqualified = true
+	c.ranking.Remove(playerId)
+	events.Publish(events.PlayerDisqualified{
+		LeaderboardId: c.id,
+		PlayerId:      playerId,
+		At:            timeprovider.Current.Now(),
+		Score:         compPlayer.score,
+		PreviousRank:  previous,
+	})
+	return nil
+}
+
+func (c *Competition) End() error {
+	c.scoreMutex.Lock()
+	defer c.scoreMutex.Unlock()
+	if c.startedAt.IsZero() {
+		return ErrCompetitionNotStarted
+	}
+	if c.ended {
+		return ErrCompetitionEnded
+	}
+	c.ended = true
+	for _, compPlayer := range c.players {
+		// Players who joined another competition since are linked to it
+		if compPlayer.player.Competition() == c {
+			compPlayer.player.SetCompetition(nil)
+		}
+	}
+	events.Publish(events.CompetitionEnded{LeaderboardId: c.id, EndsAt: c.endsAt, EndedAt: timeprovider.Current.Now(), Standings: c.finalStandings()})
+	return nil
+}
+
+// finalStandings returns the ranked players, best first, then the disqualified players by ID with rank 0.
+// The scores must be locked.
+func (c *Competition) finalStandings() []events.Standing {
+	standings := make([]events.Standing, 0, len(c.players))
+	if c.ranking != nil {
+		for _, ranked := range c.ranking.RankedRange(0, c.ranking.Len(), config.RankTiePolicy) {
+			standings = append(standings, events.Standing{PlayerId: ranked.Player.player.Id(), Score: ranked.Player.score, Rank: ranked.Rank})
+		}
+	}
+	disqualified := []string{}
+	for playerId, compPlayer := range c.players {
+		if compPlayer.disqualified {
+			disqualified = append(disqualified, playerId)
+		}
+	}
+	slices.Sort(disqualified)
+	for _, playerId := range disqualified {
+		standings = append(standings, events.Standing{PlayerId: playerId, Score: c.players[playerId].score})
+	}
+	return standings
+}
+
+// moderatedPlayer returns a player of a started competition whose score a moderator can change.
+// Scores are final once the competition has ended. The scores must be locked.
+func (c *Competition) moderatedPlayer(playerId string) (*CompetingPlayer, error) {
+	if playerId == "" {
+		return nil, ErrPlayerIdEmpty
+	}
+	if c.startedAt.IsZero() {
+		return nil, ErrCompetitionNotStarted
+	}
+	if c.ended {
+		return nil, ErrCompetitionEnded
+	}
+	compPlayer, found := c.players[playerId]
+	if !found {
+		return nil, ErrPlayerNotFound
+	}
+	return compPlayer, nil
+}
+
+// rerank moves a player to the position of their new score unless they are disqualified,
+// and publishes the change of their score with their new rank
+func (c *Competition) rerank(compPlayer *CompetingPlayer, change ScoreChange) {
+	playerId := compPlayer.player.Id()
+	previous := c.rankOf(playerId)
+	if !compPlayer.disqualified {
+		c.ranking.Upsert(compPlayer)
+	}
+	events.Publish(events.ScoreAdded{
+		LeaderboardId: c.id,
+		PlayerId:      playerId,
+		At:            change.At,
+		Points:        change.Points,
+		Delta:         change.Delta,
+		Score:         change.Score,
+		Source:        change.Source,
+		SubmissionId:  change.SubmissionId,
+		Rank:          c.rankOf(playerId),
+		PreviousRank:  previous,
+	})
+}
+
+// rankOf returns the rank of a player under config.RankTiePolicy, or 0 if they are not ranked
+func (c *Competition) rankOf(playerId string) int {
+	position, found := c.ranking.Rank(playerId)
+	if !found {
+		return 0
+	}
+	return c.ranking.RankedRange(position-1, 1, config.RankTiePolicy)[0].Rank
+}
+
+// rankPlayers indexes the players by score when the competition starts
+func (c *Competition) rankPlayers() {
+	c.ranking = NewRankIndex(c.tieBreaker)
+	for _, compPlayer := range c.players {
+		if !compPlayer.disqualified {
+			c.ranking.Upsert(compPlayer)
+		}
 	}
 }
 
@@ -151,15 +543,107 @@ func (c *Competition) StartedAt() time.Time {
 func (c *Competition) EndsAt() time.Time {
 	return c.endsAt
 }
+func (c *Competition) Ended() bool {
+	c.scoreMutex.Lock()
+	defer c.scoreMutex.Unlock()
+	return c.ended
+}
 func (c *Competition) PlayersMap() map[string]*CompetingPlayer {
 	return c.players
 }
 func (c *Competition) Leaderboard() []*CompetingPlayer {
-	return c.sortedPlayers
+	c.scoreMutex.Lock()
+	defer c.scoreMutex.Unlock()
+	if c.ranking == nil {
+		return nil
+	}
+	return c.ranking.Range(0, c.ranking.Len())
+}
+func (c *Competition) Standings(offset, count int) []RankedPlayer {
+	c.scoreMutex.Lock()
+	defer c.scoreMutex.Unlock()
+	if c.ranking == nil {
+		return []RankedPlayer{}
+	}
+	return c.ranking.RankedRange(offset, count, config.RankTiePolicy)
+}
+func (c *Competition) RankedCount() int {
+	c.scoreMutex.Lock()
+	defer c.scoreMutex.Unlock()
+	if c.ranking == nil {
+		// Players are ranked when the competition starts, and cannot be disqualified before
+		return len(c.players)
+	}
+	return c.ranking.Len()
+}
+func (c *Competition) Position(playerId string) (int, bool) {
+	c.scoreMutex.Lock()
+	defer c.scoreMutex.Unlock()
+	if c.ranking == nil {
+		return 0, false
+	}
+	rank, found := c.ranking.Rank(playerId)
+	return rank - 1, found
+}
+func (c *Competition) History(playerId string) ([]ScoreChange, bool) {
+	c.scoreMutex.Lock()
+	defer c.scoreMutex.Unlock()
+	compPlayer, found := c.players[playerId]
+	if !found {
+		return nil, false
+	}
+	return compPlayer.History(), true
+}
+func (c *Competition) AppliedSubmissions() []AppliedSubmission {
+	c.scoreMutex.Lock()
+	defer c.scoreMutex.Unlock()
+	return c.submissions.list(timeprovider.Current.Now())
+}
+
+func (c *Competition) Submitted(playerId, submissionId string) bool {
+	if submissionId == "" {
+		return false
+	}
+	c.scoreMutex.Lock()
+	defer c.scoreMutex.Unlock()
+	return c.submissions.seen(playerId, submissionId, timeprovider.Current.Now())
+}
+
+// RestoreSubmission remembers a persisted submission so it is not applied again
+func (c *Competition) RestoreSubmission(submission AppliedSubmission) {
+	c.submissions.add(submission)
 }
 func (c *Competition) InitialLevel() int {
 	return c.initialLevel
 }
+func (c *Competition) TieBreaker() string {
+	return c.tieBreaker
+}
+
+func (c *Competition) SetTieBreaker(tieBreaker string) error {
+	if err := ValidateTieBreaker(tieBreaker); err != nil {
+		return err
+	}
+	if !c.startedAt.IsZero() {
+		return ErrCompetitionStarted
+	}
+	c.tieBreaker = tieBreaker
+	return nil
+}
+func (c *Competition) ScoringMode() string {
+	return c.scoringMode
+}
+
+func (c *Competition) SetScoringMode(mode string) error {
+	if err := ValidateScoringMode(mode); err != nil {
+		return err
+	}
+	if !c.startedAt.IsZero() {
+		return ErrCompetitionStarted
+	}
+	c.scoringMode = mode
+	return nil
+}
 
 func (c *Competition) SetStartedAt(time time.Time) {
 	c.startedAt = time
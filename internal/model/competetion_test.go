@@ -2,10 +2,12 @@ package model
 
 import (
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
 	"leaderboard/internal/config"
+	"leaderboard/internal/events"
 	"leaderboard/internal/timeprovider"
 
 	"github.com/google/uuid"
@@ -321,3 +323,451 @@ func TestCompetition_AddScore_SortLeaderboardAccordingToScoreThenName(t *testing
 		t.Errorf("expected Leaderboard()[0] = b, Leaderboard()[1] = c, Leaderboard()[2] = a after score update")
 	}
 }
+
+func TestCompetition_RemovePlayer_Success(t *testing.T) {
+	competition := NewCompetition(1)
+	player := NewPlayer("p1", 1, "US")
+	_ = competition.AddPlayer(player)
+
+	err := competition.RemovePlayer(player.Id())
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(competition.PlayersMap()) != 0 {
+		t.Errorf("expected no players, got %d", len(competition.PlayersMap()))
+	}
+	if player.Competition() != nil {
+		t.Errorf("expected player's Competition to be cleared, got %v", player.Competition())
+	}
+}
+
+func TestCompetition_RemovePlayer_CompetitionStarted(t *testing.T) {
+	competition := NewCompetition(1)
+	player1 := NewPlayer("p1", 1, "US")
+	_ = competition.AddPlayer(player1)
+	_ = competition.AddPlayer(NewPlayer("p2", 1, "US"))
+	_ = competition.Start()
+
+	err := competition.RemovePlayer(player1.Id())
+
+	if err != ErrCompetitionStarted {
+		t.Errorf("expected ErrCompetitionStarted, got %v", err)
+	}
+	if player1.Competition() != competition {
+		t.Errorf("expected player to stay in the started competition")
+	}
+}
+
+func TestCompetition_RemovePlayer_PlayerNotFound(t *testing.T) {
+	competition := NewCompetition(1)
+
+	err := competition.RemovePlayer("unknown")
+
+	if err != ErrPlayerNotFound {
+		t.Errorf("expected ErrPlayerNotFound, got %v", err)
+	}
+}
+
+func TestCompetition_TieBreakers(t *testing.T) {
+	defer func() { timeprovider.Current = timeprovider.RealTimeProvider{} }()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		tieBreaker string
+		expected   []string
+		ranks      []int
+	}{
+		{TieBreakerPlayerId, []string{"a", "b", "c"}, []int{1, 2, 3}},
+		// c reached 20 first, a needed the fewest submissions, b has the highest level
+		{TieBreakerEarliest, []string{"c", "a", "b"}, []int{1, 2, 3}},
+		{TieBreakerFewestSubmissions, []string{"a", "b", "c"}, []int{1, 2, 3}},
+		{TieBreakerHigherLevel, []string{"b", "a", "c"}, []int{1, 2, 3}},
+		{TieBreakerLowerLevel, []string{"a", "c", "b"}, []int{1, 2, 3}},
+		{TieBreakerShared, []string{"a", "b", "c"}, []int{1, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tieBreaker, func(t *testing.T) {
+			timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: start}
+			competition := NewCompetition(1)
+			if err := competition.SetTieBreaker(tt.tieBreaker); err != nil {
+				t.Fatalf("SetTieBreaker() returned error %v", err)
+			}
+			_ = competition.AddPlayer(NewPlayer("a", 1, "US"))
+			_ = competition.AddPlayer(NewPlayer("b", 2, "US"))
+			_ = competition.AddPlayer(NewPlayer("c", 1, "US"))
+			_ = competition.Start()
+
+			scores := []struct {
+				playerId string
+				points   int
+			}{{"c", 20}, {"a", 20}, {"b", 5}, {"b", 15}, {"c", 0}}
+			for i, score := range scores {
+				timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: start.Add(time.Duration(i) * time.Second)}
+				_ = competition.AddScore(score.playerId, score.points)
+			}
+
+			standings := competition.Standings(0, 10)
+			ids := make([]string, 0, len(standings))
+			ranks := make([]int, 0, len(standings))
+			for _, standing := range standings {
+				ids = append(ids, standing.Player.Player().Id())
+				ranks = append(ranks, standing.Rank)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.expected) || fmt.Sprint(ranks) != fmt.Sprint(tt.ranks) {
+				t.Errorf("expected %v with ranks %v, got %v with ranks %v", tt.expected, tt.ranks, ids, ranks)
+			}
+		})
+	}
+}
+
+func TestCompetition_SetTieBreaker_Errors(t *testing.T) {
+	competition := NewCompetition(1)
+	if competition.TieBreaker() != config.TieBreaker {
+		t.Errorf("expected the default tie-breaker %s, got %s", config.TieBreaker, competition.TieBreaker())
+	}
+	if err := competition.SetTieBreaker("fastest"); err != ErrInvalidTieBreaker {
+		t.Errorf("expected ErrInvalidTieBreaker, got %v", err)
+	}
+	_ = competition.AddPlayer(NewPlayer("a", 1, "US"))
+	_ = competition.AddPlayer(NewPlayer("b", 1, "US"))
+	_ = competition.Start()
+	if err := competition.SetTieBreaker(TieBreakerEarliest); err != ErrCompetitionStarted {
+		t.Errorf("expected ErrCompetitionStarted, got %v", err)
+	}
+}
+
+func TestCompetition_EarliestTieBreaker_ScoreDecreased_RankedWhenScoreReached(t *testing.T) {
+	defer func() { timeprovider.Current = timeprovider.RealTimeProvider{} }()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, mode := range []string{ScoringLatest, ScoringPenalties} {
+		t.Run(mode, func(t *testing.T) {
+			timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: start}
+			competition := NewCompetition(1)
+			_ = competition.SetScoringMode(mode)
+			_ = competition.SetTieBreaker(TieBreakerEarliest)
+			_ = competition.AddPlayer(NewPlayer("a", 1, "US"))
+			_ = competition.AddPlayer(NewPlayer("b", 1, "US"))
+			_ = competition.Start()
+
+			// a scores 30 first, b reaches 20, then a drops to 20
+			scores := []struct {
+				playerId string
+				points   int
+			}{{"a", 30}, {"b", 20}, {"a", 20}}
+			if mode == ScoringPenalties {
+				scores[2].points = -10
+			}
+			for i, score := range scores {
+				timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: start.Add(time.Duration(i) * time.Second)}
+				if err := competition.AddScore(score.playerId, score.points); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			standings := competition.Standings(0, 10)
+			if standings[0].Player.Player().Id() != "b" || standings[0].Player.Score() != 20 || standings[1].Player.Score() != 20 {
+				t.Errorf("expected b to rank first after reaching 20 before a, got %s with %d", standings[0].Player.Player().Id(), standings[0].Player.Score())
+			}
+			if !standings[1].Player.ImprovedAt().Equal(start.Add(2 * time.Second)) {
+				t.Errorf("expected a to reach 20 when the score decreased, got %v", standings[1].Player.ImprovedAt())
+			}
+		})
+	}
+}
+
+func TestCompetition_PlayerUpdated_CompetitionKeepsLevelAndCountryAtJoin(t *testing.T) {
+	competition := NewCompetition(1)
+	_ = competition.SetTieBreaker(TieBreakerHigherLevel)
+	a := NewPlayer("a", 2, "US")
+	b := NewPlayer("b", 3, "GB")
+	_ = competition.AddPlayer(a)
+	_ = competition.AddPlayer(b)
+	_ = competition.Start()
+
+	_ = a.SetLevel(9)
+	_ = a.SetCountryCode("FR")
+	_ = competition.AddScore("a", 10)
+	_ = competition.AddScore("b", 10)
+
+	standings := competition.Standings(0, 10)
+	if standings[0].Player.Player().Id() != "b" {
+		t.Errorf("expected b to rank first with the higher level at join, got %s", standings[0].Player.Player().Id())
+	}
+	if compPlayer := competition.PlayersMap()["a"]; compPlayer.Level() != 2 || compPlayer.CountryCode() != "US" {
+		t.Errorf("expected a to keep level 2 and US in the competition, got %d and %s", compPlayer.Level(), compPlayer.CountryCode())
+	}
+}
+
+func TestCompetition_AddScore_ScoringModes(t *testing.T) {
+	defer func() {
+		timeprovider.Current = timeprovider.RealTimeProvider{}
+		config.MaxPointsPerWindow = 1000
+	}()
+	config.MaxPointsPerWindow = 50
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		mode     string
+		points   []int
+		expected int
+	}{
+		{ScoringIncrement, []int{30, 10, 20}, 60},
+		{ScoringPenalties, []int{30, -10, 20}, 40},
+		{ScoringBest, []int{30, 10, 20}, 30},
+		{ScoringLatest, []int{30, 10, 20}, 20},
+		// 30 and 20 of 40 in the first window, 20 in the second window
+		{ScoringWindow, []int{30, 40, 20}, 70},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: start}
+			competition := NewCompetition(1)
+			if err := competition.SetScoringMode(tt.mode); err != nil {
+				t.Fatalf("SetScoringMode() returned error %v", err)
+			}
+			_ = competition.AddPlayer(NewPlayer("a", 1, "US"))
+			_ = competition.AddPlayer(NewPlayer("b", 1, "US"))
+			_ = competition.Start()
+
+			for i, points := range tt.points {
+				if i == len(tt.points)-1 {
+					timeprovider.Current = &timeprovider.MockTimeProvider{FixedTime: start.Add(config.ScoreWindow)}
+				}
+				if err := competition.AddScore("a", points); err != nil {
+					t.Fatalf("AddScore(%d) returned error %v", points, err)
+				}
+			}
+			compPlayer := competition.PlayersMap()["a"]
+			if compPlayer.Score() != tt.expected || compPlayer.Submissions() != len(tt.points) {
+				t.Errorf("expected score %d after %d submissions, got %d after %d", tt.expected, len(tt.points), compPlayer.Score(), compPlayer.Submissions())
+			}
+		})
+	}
+}
+
+func TestCompetition_AddScore_NegativeOnlyWithPenalties(t *testing.T) {
+	for _, mode := range []string{ScoringIncrement, ScoringBest, ScoringLatest, ScoringWindow} {
+		competition := NewCompetition(1)
+		_ = competition.SetScoringMode(mode)
+		_ = competition.AddPlayer(NewPlayer("a", 1, "US"))
+		_ = competition.AddPlayer(NewPlayer("b", 1, "US"))
+		_ = competition.Start()
+		if err := competition.AddScore("a", -5); err != ErrPointsNegative {
+			t.Errorf("expected ErrPointsNegative in the %s mode, got %v", mode, err)
+		}
+	}
+	if err := NewCompetition(1).SetScoringMode("sum"); err != ErrInvalidScoringMode {
+		t.Errorf("expected ErrInvalidScoringMode, got %v", err)
+	}
+}
+
+func TestCompetition_VoidSubmission_RecomputesScore(t *testing.T) {
+	competition := NewCompetition(1)
+	_ = competition.SetScoringMode(ScoringBest)
+	_ = competition.AddPlayer(NewPlayer("a", 1, "US"))
+	_ = competition.AddPlayer(NewPlayer("b", 1, "US"))
+	_ = competition.Start()
+	_, _ = competition.SubmitScore("a", 30, "s1")
+	_, _ = competition.SubmitScore("a", 50, "s2")
+	_ = competition.AddScore("b", 40)
+
+	change, err := competition.VoidSubmission("a", "s2", "modified client")
+	if err != nil {
+		t.Fatalf("VoidSubmission() returned error %v", err)
+	}
+	if change.Delta != -20 || change.Score != 30 || change.Source != SourceVoid || change.Reason != "modified client" {
+		t.Errorf("expected the best score to go back to 30, got %+v", change)
+	}
+	if leaderboard := competition.Leaderboard(); leaderboard[0].Player().Id() != "b" {
+		t.Errorf("expected b to lead after the void, got %s", leaderboard[0].Player().Id())
+	}
+	history, _ := competition.History("a")
+	if len(history) != 3 || !history[1].Voided || history[2].Source != SourceVoid {
+		t.Errorf("expected the voided submission to stay in the history, got %+v", history)
+	}
+
+	if _, err := competition.VoidSubmission("a", "s2", "again"); err != ErrSubmissionNotFound {
+		t.Errorf("expected ErrSubmissionNotFound for a voided submission, got %v", err)
+	}
+	if applied, _ := competition.SubmitScore("a", 50, "s2"); applied {
+		t.Errorf("expected a retry of the voided submission not to be applied")
+	}
+}
+
+func TestCompetition_AdjustScore(t *testing.T) {
+	competition := NewCompetition(1)
+	_ = competition.SetScoringMode(ScoringLatest)
+	_ = competition.AddPlayer(NewPlayer("a", 1, "US"))
+	_ = competition.AddPlayer(NewPlayer("b", 1, "US"))
+	if _, err := competition.AdjustScore("a", 10, "bonus"); err != ErrCompetitionNotStarted {
+		t.Errorf("expected ErrCompetitionNotStarted, got %v", err)
+	}
+	_ = competition.Start()
+	_ = competition.AddScore("a", 30)
+
+	change, err := competition.AdjustScore("a", -40, "exploit")
+	if err != nil || change.Delta != -40 || change.Score != -10 || change.Source != SourceAdjustment {
+		t.Errorf("expected the score to be adjusted to -10, got %+v (%v)", change, err)
+	}
+	// Adjustments are kept when the score is recomputed
+	_, _ = competition.SubmitScore("a", 20, "s1")
+	_, _ = competition.VoidSubmission("a", "s1", "duplicate")
+	if score := competition.PlayersMap()["a"].Score(); score != -10 {
+		t.Errorf("expected the adjustment to be kept after a void, got %d", score)
+	}
+}
+
+func TestCompetition_SubmitScores_ConcurrentDisqualify_NoScoreAppliedAfterwards(t *testing.T) {
+	competition := NewCompetition(1)
+	_ = competition.AddPlayer(NewPlayer("a", 1, "US"))
+	_ = competition.AddPlayer(NewPlayer("b", 1, "US"))
+	_ = competition.Start()
+
+	results := make(chan error, 100)
+	go func() {
+		defer close(results)
+		for range 100 {
+			results <- competition.AddScore("a", 1)
+		}
+	}()
+	if err := competition.Disqualify("a"); err != nil {
+		t.Fatalf("Disqualify() returned error %v", err)
+	}
+
+	applied, disqualified := 0, false
+	for err := range results {
+		switch {
+		case err == nil && disqualified:
+			t.Fatalf("expected no score to be applied after the disqualification")
+		case err == nil:
+			applied++
+		case err == ErrPlayerDisqualified:
+			disqualified = true
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if history, _ := competition.History("a"); len(history) != applied {
+		t.Errorf("expected %d changes in the history, got %d", applied, len(history))
+	}
+}
+
+func TestCompetition_Disqualify(t *testing.T) {
+	competition := NewCompetition(1)
+	_ = competition.AddPlayer(NewPlayer("a", 1, "US"))
+	_ = competition.AddPlayer(NewPlayer("b", 1, "US"))
+	_ = competition.Start()
+	_ = competition.AddScore("a", 30)
+
+	if err := competition.Disqualify("a"); err != nil {
+		t.Fatalf("Disqualify() returned error %v", err)
+	}
+	if leaderboard := competition.Leaderboard(); len(leaderboard) != 1 || leaderboard[0].Player().Id() != "b" {
+		t.Errorf("expected only b to be ranked, got %v", leaderboard)
+	}
+	if count := competition.RankedCount(); count != 1 {
+		t.Errorf("expected 1 ranked player, got %d", count)
+	}
+	if _, found := competition.Position("a"); found {
+		t.Errorf("expected a not to have a position")
+	}
+	compPlayer := competition.PlayersMap()["a"]
+	if !compPlayer.Disqualified() || compPlayer.Score() != 30 {
+		t.Errorf("expected a to keep their score of 30, got %d", compPlayer.Score())
+	}
+	if err := competition.AddScore("a", 10); err != ErrPlayerDisqualified {
+		t.Errorf("expected ErrPlayerDisqualified, got %v", err)
+	}
+	if err := competition.Disqualify("a"); err != ErrPlayerDisqualified {
+		t.Errorf("expected ErrPlayerDisqualified when disqualified twice, got %v", err)
+	}
+	// Moderating the score of a disqualified player does not rank them again
+	_, _ = competition.AdjustScore("a", 10, "correction")
+	if len(competition.Leaderboard()) != 1 {
+		t.Errorf("expected the disqualified player to stay out of the ranking")
+	}
+}
+
+func TestCompetition_PublishesEvents(t *testing.T) {
+	published := []events.Event{}
+	unsubscribe := events.Subscribe(func(event events.Event) { published = append(published, event) })
+	defer unsubscribe()
+	competition := NewCompetition(1)
+	_ = competition.AddPlayer(NewPlayer("a", 1, "US"))
+	_ = competition.AddPlayer(NewPlayer("b", 1, "US"))
+	_ = competition.Start()
+
+	_, _ = competition.SubmitScore("b", 30, "s1")
+	_ = competition.Disqualify("b")
+
+	if len(published) != 3 {
+		t.Fatalf("expected 3 events, got %+v", published)
+	}
+	if started, ok := published[0].(events.CompetitionStarted); !ok || !slices.Equal(started.PlayerIds, []string{"a", "b"}) ||
+		!started.EndsAt.Equal(competition.EndsAt()) {
+		t.Errorf("expected the competition to start with a and b, got %+v", published[0])
+	}
+	if added, ok := published[1].(events.ScoreAdded); !ok || added.SubmissionId != "s1" || added.Delta != 30 || added.Rank != 1 || added.PreviousRank != 2 {
+		t.Errorf("expected b to move from rank 2 to 1 with 30 points, got %+v", published[1])
+	}
+	if disqualified, ok := published[2].(events.PlayerDisqualified); !ok || disqualified.PreviousRank != 1 || disqualified.Score != 30 {
+		t.Errorf("expected b to be disqualified from rank 1, got %+v", published[2])
+	}
+}
+
+func TestCompetition_End(t *testing.T) {
+	published := []events.Event{}
+	unsubscribe := events.Subscribe(func(event events.Event) { published = append(published, event) })
+	defer unsubscribe()
+	alice, bob, carol := NewPlayer("alice", 1, "US"), NewPlayer("bob", 1, "US"), NewPlayer("carol", 1, "US")
+	competition := NewCompetition(1)
+	for _, player := range []*Player{alice, bob, carol} {
+		_ = competition.AddPlayer(player)
+	}
+	if err := competition.End(); err != ErrCompetitionNotStarted {
+		t.Errorf("expected %v before the start, got %v", ErrCompetitionNotStarted, err)
+	}
+	_ = competition.Start()
+	_ = competition.AddScore("bob", 20)
+	_ = competition.AddScore("carol", 30)
+	_ = competition.Disqualify("carol")
+	// alice already joined another competition
+	other := NewCompetition(1)
+	_ = other.AddPlayer(alice)
+	published = published[:0]
+
+	if err := competition.End(); err != nil {
+		t.Fatalf("End() returned error %v", err)
+	}
+
+	if !competition.Ended() {
+		t.Errorf("expected the competition to be ended")
+	}
+	if alice.Competition() != other || bob.Competition() != nil || carol.Competition() != nil {
+		t.Errorf("expected bob and carol to be unlinked and alice to stay in their new competition")
+	}
+	if err := competition.AddScore("bob", 10); err != ErrCompetitionEnded {
+		t.Errorf("expected %v, got %v", ErrCompetitionEnded, err)
+	}
+	if err := competition.End(); err != ErrCompetitionEnded {
+		t.Errorf("expected %v, got %v", ErrCompetitionEnded, err)
+	}
+	if _, err := competition.AdjustScore("bob", 5, "bonus"); err != ErrCompetitionEnded {
+		t.Errorf("expected %v for an adjustment, got %v", ErrCompetitionEnded, err)
+	}
+	if err := competition.Disqualify("bob"); err != ErrCompetitionEnded {
+		t.Errorf("expected %v for a disqualification, got %v", ErrCompetitionEnded, err)
+	}
+	if score := competition.PlayersMap()["bob"].Score(); score != 20 {
+		t.Errorf("expected the score of bob to stay 20, got %d", score)
+	}
+	if len(published) != 1 {
+		t.Fatalf("expected a single event, got %+v", published)
+	}
+	expected := []events.Standing{{PlayerId: "bob", Score: 20, Rank: 1}, {PlayerId: "alice", Rank: 2}, {PlayerId: "carol", Score: 30}}
+	if ended, ok := published[0].(events.CompetitionEnded); !ok || ended.LeaderboardId != competition.Id() || !slices.Equal(ended.Standings, expected) {
+		t.Errorf("expected the final standings %+v, got %+v", expected, published[0])
+	}
+}
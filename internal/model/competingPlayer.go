@@ -1,14 +1,51 @@
 package model
 
+import (
+	"leaderboard/internal/config"
+	"leaderboard/internal/timeprovider"
+	"time"
+)
+
 type CompetingPlayer struct {
 	player *Player
-	score  int
+	// Level and country code of the player when they joined, so editing the player does not change
+	// their ranking or the aggregate boards of the competitions they are in
+	level       int
+	countryCode string
+	score       int
+	// Number of scores submitted and when the score last changed, used to break ties.
+	// The score may decrease in the latest and penalties modes or with adjustments, so every change counts.
+	submissions int
+	improvedAt  time.Time
+	// Window of the competition of the last submission and the points added in it, used by the window scoring mode.
+	// They are recomputed from the persisted history when the player is restored.
+	window       int
+	windowPoints int
+	// Accepted submissions in the order they were applied
+	history []ScoreChange
+	// Points of the submissions of the player that are not voided
+	points pointStats
+	// Disqualified players are not ranked and cannot submit scores
+	disqualified bool
 }
 
 func NewCompetingPlayer(player *Player) *CompetingPlayer {
 	return &CompetingPlayer{
-		player: player,
-		score:  0}
+		player:      player,
+		level:       player.Level(),
+		countryCode: player.CountryCode(),
+		score:       0}
+}
+
+// RestoreCompetingPlayer recreates a competing player with a previously persisted score,
+// and the level and country code the player had when they joined. The tie-breaker values are recomputed
+// by RestoreHistory.
+func RestoreCompetingPlayer(player *Player, level int, countryCode string, score int) *CompetingPlayer {
+	return &CompetingPlayer{
+		player:      player,
+		level:       level,
+		countryCode: countryCode,
+		score:       score}
 }
 
 func (p *CompetingPlayer) Score() int {
@@ -19,6 +56,124 @@ func (p *CompetingPlayer) Player() *Player {
 	return p.player
 }
 
+// Level returns the level of the player when they joined the competition
+func (p *CompetingPlayer) Level() int {
+	return p.level
+}
+
+// CountryCode returns the country code of the player when they joined the competition
+func (p *CompetingPlayer) CountryCode() string {
+	return p.countryCode
+}
+
+func (p *CompetingPlayer) Submissions() int {
+	return p.submissions
+}
+
+// ImprovedAt returns when the player reached the current score, or the zero time if the score never changed
+func (p *CompetingPlayer) ImprovedAt() time.Time {
+	return p.improvedAt
+}
+
+// History returns the accepted submissions of the player in the order they were applied
+func (p *CompetingPlayer) History() []ScoreChange {
+	return append([]ScoreChange{}, p.history...)
+}
+
+// RestoreHistory replaces the history with previously persisted changes, in the order they were applied.
+// The number of submissions, when the score last changed and the points of the current window are recomputed
+// from the history when it gives the persisted score, so they follow the order of the changes.
+// mode and startedAt are those of the competition.
+func (p *CompetingPlayer) RestoreHistory(history []ScoreChange, mode string, startedAt time.Time) {
+	p.history = append([]ScoreChange{}, history...)
+	p.points = pointStats{}
+	for _, change := range p.history {
+		if isSubmission(change.Source) && !change.Voided {
+			p.points.add(change.Points)
+		}
+	}
+	if len(p.history) == 0 {
+		return
+	}
+	replayed := CompetingPlayer{history: p.history}
+	replayed.replay(mode, startedAt)
+	if replayed.score == p.score {
+		p.submissions, p.improvedAt = replayed.submissions, replayed.improvedAt
+		p.window, p.windowPoints = replayed.window, replayed.windowPoints
+	}
+}
+
+// SubmittedSince returns the number of scores submitted for the player after since, voided ones included.
+// The competition must be locked, e.g. in ScoreSubmission.Validate.
+func (p *CompetingPlayer) SubmittedSince(since time.Time) int {
+	count := 0
+	for i := len(p.history) - 1; i >= 0 && p.history[i].At.After(since); i-- {
+		if isSubmission(p.history[i].Source) {
+			count++
+		}
+	}
+	return count
+}
+
+// Disqualified returns true if a moderator removed the player from the ranking of the competition
+func (p *CompetingPlayer) Disqualified() bool {
+	return p.disqualified
+}
+
+// AddScore adds points to the score
 func (p *CompetingPlayer) AddScore(score int) {
-	p.score += score
+	p.submit(score, ScoringIncrement, 0, timeprovider.Current.Now())
+}
+
+// submit applies a submitted score with a scoring mode and returns the change of the score.
+// window is the index of the current window of the competition.
+func (p *CompetingPlayer) submit(points int, mode string, window int, now time.Time) int {
+	previous := p.score
+	switch mode {
+	case ScoringBest:
+		p.score = max(p.score, points)
+	case ScoringLatest:
+		p.score = points
+	case ScoringWindow:
+		if window != p.window {
+			p.window = window
+			p.windowPoints = 0
+		}
+		points = max(min(points, config.MaxPointsPerWindow-p.windowPoints), 0)
+		p.windowPoints += points
+		p.score += points
+	default:
+		p.score += points
+	}
+	p.submissions++
+	if p.score != previous {
+		p.improvedAt = now
+	}
+	return p.score - previous
+}
+
+// replay recomputes the score from the history, without the voided submissions.
+// startedAt is the start of the competition, used to find the window of each submission.
+func (p *CompetingPlayer) replay(mode string, startedAt time.Time) {
+	p.score, p.submissions, p.improvedAt = 0, 0, time.Time{}
+	p.window, p.windowPoints = 0, 0
+	for _, change := range p.history {
+		switch {
+		case change.Voided || change.Source == SourceVoid:
+			// The voided submission and the change voiding it don't count
+		case change.Source == SourceAdjustment:
+			p.adjust(change.Points, change.At)
+		default:
+			p.submit(change.Points, mode, int(change.At.Sub(startedAt)/config.ScoreWindow), change.At)
+		}
+	}
+}
+
+// adjust adds points to the score regardless of the scoring mode and returns the change of the score
+func (p *CompetingPlayer) adjust(points int, now time.Time) int {
+	p.score += points
+	if points != 0 {
+		p.improvedAt = now
+	}
+	return points
 }
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"leaderboard/internal/timeprovider"
+	"time"
+)
+
+// CompetitionState is a copy of the state of a competition, taken while its players and scores are locked
+// so it can be persisted while the competition changes
+type CompetitionState struct {
+	TieBreaker  string
+	ScoringMode string
+	StartedAt   time.Time
+	EndsAt      time.Time
+	Ended       bool
+	Players     []CompetingPlayerState
+	// Submissions with an ID applied less than config.SubmissionIdTTL ago
+	AppliedSubmissions []AppliedSubmission
+}
+
+// CompetingPlayerState is a copy of the score of a player and of what is needed to rebuild it
+type CompetingPlayerState struct {
+	Player       *Player
+	Level        int
+	CountryCode  string
+	Score        int
+	Disqualified bool
+	History      []ScoreChange
+}
+
+// State returns a copy of the state of the competition
+func (c *Competition) State() CompetitionState {
+	c.scoreMutex.Lock()
+	defer c.scoreMutex.Unlock()
+	state := CompetitionState{
+		TieBreaker:         c.tieBreaker,
+		ScoringMode:        c.scoringMode,
+		StartedAt:          c.startedAt,
+		EndsAt:             c.endsAt,
+		Ended:              c.ended,
+		Players:            make([]CompetingPlayerState, 0, len(c.players)),
+		AppliedSubmissions: c.submissions.list(timeprovider.Current.Now()),
+	}
+	for _, compPlayer := range c.players {
+		state.Players = append(state.Players, CompetingPlayerState{
+			Player:       compPlayer.player,
+			Level:        compPlayer.level,
+			CountryCode:  compPlayer.countryCode,
+			Score:        compPlayer.score,
+			Disqualified: compPlayer.disqualified,
+			History:      compPlayer.History(),
+		})
+	}
+	return state
+}
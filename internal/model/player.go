@@ -1,20 +1,33 @@
 package model
 
 import (
+	"errors"
 	"fmt"
 	"leaderboard/internal/config"
+	"sync"
 )
 
+var (
+	ErrInvalidLevel       = fmt.Errorf("player level must be between MinLevel %d and MaxLevel %d", config.MinLevel, config.MaxLevel)
+	ErrInvalidCountryCode = errors.New("country code must be two uppercase letters")
+)
+
+// Player is shared by the requests, the matchmaking and the competitions,
+// so the fields that can change are read and written under its mutex
 type Player struct {
 	id          string
+	mutex       sync.RWMutex
 	level       int
 	countryCode string
 	competition ICompetition
+	// Banned players cannot join competitions
+	banned bool
 }
 
+// NewPlayer creates a player. It panics if the level is out of range, use ValidateLevel to check user input first
 func NewPlayer(id string, level int, countryCode string) *Player {
-	if level < config.MinLevel || level > config.MaxLevel {
-		panic("player level must be between MinLevel " + fmt.Sprint(config.MinLevel) + " and MaxLevel " + fmt.Sprint(config.MaxLevel))
+	if err := ValidateLevel(level); err != nil {
+		panic(err.Error())
 	}
 	return &Player{
 		id:          id,
@@ -26,14 +39,75 @@ func (p *Player) Id() string {
 	return p.id
 }
 func (p *Player) Level() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
 	return p.level
 }
 func (p *Player) CountryCode() string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
 	return p.countryCode
 }
 func (p *Player) Competition() ICompetition {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
 	return p.competition
 }
 func (p *Player) SetCompetition(c ICompetition) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 	p.competition = c
 }
+
+func (p *Player) Banned() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.banned
+}
+func (p *Player) SetBanned(banned bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.banned = banned
+}
+
+// SetLevel changes the level used by the next competitions the player joins
+func (p *Player) SetLevel(level int) error {
+	if err := ValidateLevel(level); err != nil {
+		return err
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.level = level
+	return nil
+}
+
+// SetCountryCode changes the country code used by the next competitions the player joins
+func (p *Player) SetCountryCode(countryCode string) error {
+	if err := ValidateCountryCode(countryCode); err != nil {
+		return err
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.countryCode = countryCode
+	return nil
+}
+
+func ValidateLevel(level int) error {
+	if level < config.MinLevel || level > config.MaxLevel {
+		return ErrInvalidLevel
+	}
+	return nil
+}
+
+// ValidateCountryCode checks that the country code has the ISO 3166-1 alpha-2 format
+func ValidateCountryCode(countryCode string) error {
+	if len(countryCode) != 2 {
+		return ErrInvalidCountryCode
+	}
+	for _, c := range countryCode {
+		if c < 'A' || c > 'Z' {
+			return ErrInvalidCountryCode
+		}
+	}
+	return nil
+}
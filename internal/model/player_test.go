@@ -0,0 +1,54 @@
+package model
+
+import (
+	"leaderboard/internal/config"
+	"testing"
+)
+
+func TestValidateLevel(t *testing.T) {
+	tests := []struct {
+		level         int
+		expectedError error
+	}{
+		{config.MinLevel, nil},
+		{config.MaxLevel, nil},
+		{config.MinLevel - 1, ErrInvalidLevel},
+		{config.MaxLevel + 1, ErrInvalidLevel},
+	}
+	for _, tt := range tests {
+		if err := ValidateLevel(tt.level); err != tt.expectedError {
+			t.Errorf("ValidateLevel(%d) = %v, expected %v", tt.level, err, tt.expectedError)
+		}
+	}
+}
+
+func TestValidateCountryCode(t *testing.T) {
+	tests := []struct {
+		countryCode   string
+		expectedError error
+	}{
+		{"US", nil},
+		{"", ErrInvalidCountryCode},
+		{"us", ErrInvalidCountryCode},
+		{"USA", ErrInvalidCountryCode},
+		{"U1", ErrInvalidCountryCode},
+	}
+	for _, tt := range tests {
+		if err := ValidateCountryCode(tt.countryCode); err != tt.expectedError {
+			t.Errorf("ValidateCountryCode(%q) = %v, expected %v", tt.countryCode, err, tt.expectedError)
+		}
+	}
+}
+
+func TestPlayer_SetLevel_InvalidLevelKeepsOldLevel(t *testing.T) {
+	player := NewPlayer("p1", 3, "US")
+
+	err := player.SetLevel(config.MaxLevel + 1)
+
+	if err != ErrInvalidLevel {
+		t.Errorf("expected ErrInvalidLevel, got %v", err)
+	}
+	if player.Level() != 3 {
+		t.Errorf("expected level 3, got %d", player.Level())
+	}
+}
@@ -0,0 +1,54 @@
+package model
+
+import "math"
+
+// PointStats summarizes the points of score submissions
+type PointStats struct {
+	Count     int
+	Mean      float64
+	Deviation float64 // Population standard deviation
+}
+
+// pointStats accumulates the points of the score submissions that are not voided, so their mean and standard
+// deviation are known without going through the histories. Adjustments and voids are not submissions.
+type pointStats struct {
+	count      int
+	sum        float64
+	sumSquares float64
+}
+
+func (s *pointStats) add(points int) {
+	s.count++
+	s.sum += float64(points)
+	s.sumSquares += float64(points) * float64(points)
+}
+
+func (s *pointStats) remove(points int) {
+	s.count--
+	s.sum -= float64(points)
+	s.sumSquares -= float64(points) * float64(points)
+}
+
+func (s pointStats) plus(other pointStats) pointStats {
+	return pointStats{count: s.count + other.count, sum: s.sum + other.sum, sumSquares: s.sumSquares + other.sumSquares}
+}
+
+// minus returns the statistics without the points of other, e.g. without the submissions of a player
+func (s pointStats) minus(other pointStats) pointStats {
+	return pointStats{count: s.count - other.count, sum: s.sum - other.sum, sumSquares: s.sumSquares - other.sumSquares}
+}
+
+func (s pointStats) summary() PointStats {
+	if s.count <= 0 {
+		return PointStats{}
+	}
+	mean := s.sum / float64(s.count)
+	// Rounding can make the variance of identical points slightly negative
+	variance := max(s.sumSquares/float64(s.count)-mean*mean, 0)
+	return PointStats{Count: s.count, Mean: mean, Deviation: math.Sqrt(variance)}
+}
+
+// isSubmission tells whether a change of a score was submitted for the player rather than made by a moderator
+func isSubmission(source string) bool {
+	return source != SourceAdjustment && source != SourceVoid
+}
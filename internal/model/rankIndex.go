@@ -0,0 +1,362 @@
+package model
+
+import (
+	"cmp"
+	"errors"
+	"math/rand/v2"
+	"strings"
+)
+
+const (
+	// Maximum number of levels of the skip list, enough for billions of players
+	rankIndexMaxLevel = 32
+	// Probability that a node is promoted to the next level
+	rankIndexPromotion = 0.25
+)
+
+// Tie policies deciding the rank of players with the same score, selected with config.RankTiePolicy
+const (
+	// TiePolicyOrdinal gives every player a distinct rank, equal scores are ordered by player ID ("1234")
+	TiePolicyOrdinal = "ordinal"
+	// TiePolicyCompetition gives equal scores the same rank and skips the following ranks ("1224")
+	TiePolicyCompetition = "competition"
+	// TiePolicyDense gives equal scores the same rank without skipping ranks ("1223")
+	TiePolicyDense = "dense"
+)
+
+var ErrInvalidTiePolicy = errors.New("tie policy must be ordinal, competition or dense")
+
+func ValidateTiePolicy(policy string) error {
+	if policy != TiePolicyOrdinal && policy != TiePolicyCompetition && policy != TiePolicyDense {
+		return ErrInvalidTiePolicy
+	}
+	return nil
+}
+
+// RankedPlayer is a competing player with their rank under a tie policy
+type RankedPlayer struct {
+	Rank   int
+	Player *CompetingPlayer
+}
+
+// RankIndex keeps competing players ordered by score, highest first, then by the tie-breaker and the player ID.
+// Players are kept in an indexable skip list: every link records how many players it skips, so inserting, updating,
+// finding the position of a player and reading a range of positions take O(log n).
+// A second skip list holds the distinct groups of tied players to compute dense ranks.
+// A RankIndex is not safe for concurrent use.
+type RankIndex struct {
+	tieBreaker string
+	players    *skipList
+	nodes      map[string]*rankNode
+	groups     *skipList
+	// Number of players in each group of tied players
+	groupCounts map[rankKey]int
+}
+
+func NewRankIndex(tieBreaker string) *RankIndex {
+	return &RankIndex{
+		tieBreaker:  tieBreaker,
+		players:     newSkipList(),
+		nodes:       make(map[string]*rankNode),
+		groups:      newSkipList(),
+		groupCounts: make(map[rankKey]int),
+	}
+}
+
+// Len returns the number of players in the index
+func (r *RankIndex) Len() int {
+	return r.players.length
+}
+
+// Upsert adds a player or moves them to the position of their current score
+func (r *RankIndex) Upsert(player *CompetingPlayer) {
+	key := rankKeyFor(player, r.tieBreaker)
+	if node, found := r.nodes[key.playerId]; found {
+		if node.key == key {
+			return
+		}
+		r.players.delete(node.key)
+		r.removeFromGroup(node.key)
+	}
+	r.nodes[key.playerId] = r.players.insert(key, player)
+	r.addToGroup(key)
+}
+
+// Remove removes a player from the index. Returns false if the player is not indexed
+func (r *RankIndex) Remove(playerId string) bool {
+	node, found := r.nodes[playerId]
+	if !found {
+		return false
+	}
+	r.players.delete(node.key)
+	r.removeFromGroup(node.key)
+	delete(r.nodes, playerId)
+	return true
+}
+
+// Rank returns the 1-based ordinal rank of a player. Returns false if the player is not indexed
+func (r *RankIndex) Rank(playerId string) (int, bool) {
+	node, found := r.nodes[playerId]
+	if !found {
+		return 0, false
+	}
+	return r.players.rankOf(node.key), true
+}
+
+// Top returns the first k players
+func (r *RankIndex) Top(k int) []*CompetingPlayer {
+	return r.Range(0, k)
+}
+
+// Range returns at most count players starting at the 0-based offset
+func (r *RankIndex) Range(offset, count int) []*CompetingPlayer {
+	nodes := r.players.slice(offset, count)
+	players := make([]*CompetingPlayer, 0, len(nodes))
+	for _, node := range nodes {
+		players = append(players, node.player)
+	}
+	return players
+}
+
+// RankedRange returns at most count players starting at the 0-based offset, with their rank under the tie policy.
+// Players are tied when they have the same score and tie-breaker value. With the shared tie-breaker,
+// tied players always share a rank.
+func (r *RankIndex) RankedRange(offset, count int, policy string) []RankedPlayer {
+	if r.tieBreaker == TieBreakerShared && policy == TiePolicyOrdinal {
+		policy = TiePolicyCompetition
+	}
+	nodes := r.players.slice(offset, count)
+	start := max(offset, 0)
+	ranked := make([]RankedPlayer, 0, len(nodes))
+	for i, node := range nodes {
+		rank := start + i + 1
+		switch {
+		case policy == TiePolicyOrdinal:
+			// Each player keeps their position
+		case i > 0 && node.key.group() == nodes[i-1].key.group():
+			rank = ranked[i-1].Rank
+		case i > 0 && policy == TiePolicyDense:
+			rank = ranked[i-1].Rank + 1
+		case i == 0 && policy == TiePolicyCompetition:
+			// Players ranked higher are ordered before the smallest key of the group
+			rank = r.players.countBefore(node.key.group()) + 1
+		case i == 0 && policy == TiePolicyDense:
+			rank = r.groups.countBefore(node.key.group()) + 1
+		}
+		ranked = append(ranked, RankedPlayer{Rank: rank, Player: node.player})
+	}
+	return ranked
+}
+
+func (r *RankIndex) addToGroup(key rankKey) {
+	group := key.group()
+	r.groupCounts[group]++
+	if r.groupCounts[group] == 1 {
+		r.groups.insert(group, nil)
+	}
+}
+
+func (r *RankIndex) removeFromGroup(key rankKey) {
+	group := key.group()
+	r.groupCounts[group]--
+	if r.groupCounts[group] == 0 {
+		delete(r.groupCounts, group)
+		r.groups.delete(group)
+	}
+}
+
+// rankKey is the position of a player in the index. Values not used by the tie-breaker are zero
+type rankKey struct {
+	score       int
+	improvedAt  int64
+	submissions int
+	level       int // Negated when higher levels rank first
+	playerId    string
+}
+
+// group returns the smallest key of the players tied with this key
+func (k rankKey) group() rankKey {
+	k.playerId = ""
+	return k
+}
+
+// compareRankKeys orders higher scores first, then by the tie-breaker values, then by player ID
+func compareRankKeys(a, b rankKey) int {
+	if a.score != b.score {
+		return cmp.Compare(b.score, a.score)
+	}
+	if a.improvedAt != b.improvedAt {
+		return cmp.Compare(a.improvedAt, b.improvedAt)
+	}
+	if a.submissions != b.submissions {
+		return cmp.Compare(a.submissions, b.submissions)
+	}
+	if a.level != b.level {
+		return cmp.Compare(a.level, b.level)
+	}
+	return strings.Compare(a.playerId, b.playerId)
+}
+
+type rankNode struct {
+	key    rankKey
+	player *CompetingPlayer
+	next   []*rankNode
+	// Number of nodes between this node and the next one at each level, the next one included
+	span []int
+}
+
+func newRankNode(key rankKey, player *CompetingPlayer, level int) *rankNode {
+	return &rankNode{
+		key:    key,
+		player: player,
+		next:   make([]*rankNode, level),
+		span:   make([]int, level),
+	}
+}
+
+// skipList is an indexable skip list of unique rank keys
+type skipList struct {
+	head   *rankNode
+	level  int
+	length int
+}
+
+func newSkipList() *skipList {
+	return &skipList{
+		head:  newRankNode(rankKey{}, nil, rankIndexMaxLevel),
+		level: 1,
+	}
+}
+
+// rankOf returns the 1-based position of a key in the list
+func (l *skipList) rankOf(key rankKey) int {
+	rank := 0
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && compareRankKeys(x.next[i].key, key) <= 0 {
+			rank += x.span[i]
+			x = x.next[i]
+		}
+	}
+	return rank
+}
+
+// countBefore returns the number of keys ordered before a key
+func (l *skipList) countBefore(key rankKey) int {
+	count := 0
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && compareRankKeys(x.next[i].key, key) < 0 {
+			count += x.span[i]
+			x = x.next[i]
+		}
+	}
+	return count
+}
+
+// slice returns at most count nodes starting at the 0-based offset
+func (l *skipList) slice(offset, count int) []*rankNode {
+	if offset < 0 {
+		count += offset
+		offset = 0
+	}
+	count = min(count, l.length-offset)
+	if count <= 0 {
+		return []*rankNode{}
+	}
+	nodes := make([]*rankNode, 0, count)
+	for x := l.nodeAt(offset + 1); x != nil && len(nodes) < count; x = x.next[0] {
+		nodes = append(nodes, x)
+	}
+	return nodes
+}
+
+// nodeAt returns the node at a 1-based position
+func (l *skipList) nodeAt(rank int) *rankNode {
+	traversed := 0
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && traversed+x.span[i] <= rank {
+			traversed += x.span[i]
+			x = x.next[i]
+		}
+		if traversed == rank {
+			return x
+		}
+	}
+	return nil
+}
+
+func (l *skipList) insert(key rankKey, player *CompetingPlayer) *rankNode {
+	var update [rankIndexMaxLevel]*rankNode
+	var rank [rankIndexMaxLevel]int
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		if i < l.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.next[i] != nil && compareRankKeys(x.next[i].key, key) < 0 {
+			rank[i] += x.span[i]
+			x = x.next[i]
+		}
+		update[i] = x
+	}
+
+	level := randomRankLevel()
+	if level > l.level {
+		for i := l.level; i < level; i++ {
+			update[i] = l.head
+			l.head.span[i] = l.length
+		}
+		l.level = level
+	}
+
+	node := newRankNode(key, player, level)
+	for i := range level {
+		node.next[i] = update[i].next[i]
+		update[i].next[i] = node
+		node.span[i] = update[i].span[i] - (rank[0] - rank[i])
+		update[i].span[i] = rank[0] - rank[i] + 1
+	}
+	for i := level; i < l.level; i++ {
+		update[i].span[i]++
+	}
+	l.length++
+	return node
+}
+
+func (l *skipList) delete(key rankKey) {
+	var update [rankIndexMaxLevel]*rankNode
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && compareRankKeys(x.next[i].key, key) < 0 {
+			x = x.next[i]
+		}
+		update[i] = x
+	}
+	node := x.next[0]
+	if node == nil || node.key != key {
+		return
+	}
+
+	for i := range l.level {
+		if update[i].next[i] == node {
+			update[i].span[i] += node.span[i] - 1
+			update[i].next[i] = node.next[i]
+		} else {
+			update[i].span[i]--
+		}
+	}
+	for l.level > 1 && l.head.next[l.level-1] == nil {
+		l.level--
+	}
+	l.length--
+}
+
+func randomRankLevel() int {
+	level := 1
+	for level < rankIndexMaxLevel && rand.Float64() < rankIndexPromotion {
+		level++
+	}
+	return level
+}
@@ -0,0 +1,186 @@
+package model
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func newRankedPlayers(n int) []*CompetingPlayer {
+	players := make([]*CompetingPlayer, n)
+	for i := range players {
+		players[i] = NewCompetingPlayer(NewPlayer(fmt.Sprintf("p%05d", i), 1, "US"))
+	}
+	return players
+}
+
+// sortByScore is the reference order of the index: highest score first, then player ID
+func sortByScore(players []*CompetingPlayer) {
+	slices.SortStableFunc(players, func(a, b *CompetingPlayer) int {
+		if a.Score() == b.Score() {
+			return strings.Compare(a.Player().Id(), b.Player().Id())
+		}
+		return b.Score() - a.Score()
+	})
+}
+
+func TestRankIndex_RandomScores_MatchesSortedOrder(t *testing.T) {
+	players := newRankedPlayers(500)
+	index := NewRankIndex(TieBreakerPlayerId)
+	for _, player := range players {
+		index.Upsert(player)
+	}
+	for range 5000 {
+		player := players[rand.IntN(len(players))]
+		player.AddScore(rand.IntN(50))
+		index.Upsert(player)
+	}
+
+	sorted := slices.Clone(players)
+	sortByScore(sorted)
+	if got := index.Range(0, index.Len()); !slices.Equal(got, sorted) {
+		t.Fatalf("expected the index to match the sorted players")
+	}
+	for i, player := range sorted {
+		if rank, found := index.Rank(player.Player().Id()); !found || rank != i+1 {
+			t.Fatalf("expected %s at rank %d, got %d", player.Player().Id(), i+1, rank)
+		}
+	}
+	if got := index.Range(100, 10); !slices.Equal(got, sorted[100:110]) {
+		t.Errorf("expected ranks 101 to 110 to match the sorted players")
+	}
+	if got := index.Top(3); !slices.Equal(got, sorted[:3]) {
+		t.Errorf("expected the top 3 to match the sorted players")
+	}
+}
+
+func TestRankIndex_Range_OutOfBounds(t *testing.T) {
+	index := NewRankIndex(TieBreakerPlayerId)
+	for _, player := range newRankedPlayers(3) {
+		index.Upsert(player)
+	}
+
+	if got := index.Range(2, 10); len(got) != 1 {
+		t.Errorf("expected 1 player after the offset, got %d", len(got))
+	}
+	if got := index.Range(5, 10); len(got) != 0 {
+		t.Errorf("expected no players past the end, got %d", len(got))
+	}
+	if got := index.Range(-1, 2); len(got) != 1 {
+		t.Errorf("expected the negative offset to be clamped, got %d players", len(got))
+	}
+}
+
+func TestRankIndex_Remove(t *testing.T) {
+	players := newRankedPlayers(3)
+	index := NewRankIndex(TieBreakerPlayerId)
+	for i, player := range players {
+		player.AddScore(i * 10)
+		index.Upsert(player)
+	}
+
+	if !index.Remove("p00002") {
+		t.Fatalf("expected the player to be removed")
+	}
+	if index.Remove("p00002") {
+		t.Errorf("expected the second removal to fail")
+	}
+	if _, found := index.Rank("p00002"); found {
+		t.Errorf("expected the removed player not to be ranked")
+	}
+	if rank, _ := index.Rank("p00001"); rank != 1 || index.Len() != 2 {
+		t.Errorf("expected p00001 to move to rank 1 of 2, got %d of %d", rank, index.Len())
+	}
+}
+
+var benchmarkSizes = []int{10, 1_000, 10_000}
+
+func BenchmarkAddScore_RankIndex(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("players=%d", size), func(b *testing.B) {
+			players := newRankedPlayers(size)
+			index := NewRankIndex(TieBreakerPlayerId)
+			for _, player := range players {
+				index.Upsert(player)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				player := players[i%size]
+				player.AddScore(i % 100)
+				index.Upsert(player)
+			}
+		})
+	}
+}
+
+// BenchmarkAddScore_Sort measures the previous implementation, which sorted all players on every score
+func BenchmarkAddScore_Sort(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("players=%d", size), func(b *testing.B) {
+			players := newRankedPlayers(size)
+			sorted := slices.Clone(players)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				players[i%size].AddScore(i % 100)
+				sortByScore(sorted)
+			}
+		})
+	}
+}
+
+func TestRankIndex_RankedRange_TiePolicies(t *testing.T) {
+	players := newRankedPlayers(5)
+	index := NewRankIndex(TieBreakerPlayerId)
+	for i, score := range []int{30, 20, 20, 10, 10} {
+		players[i].AddScore(score)
+		index.Upsert(players[i])
+	}
+
+	tests := []struct {
+		policy   string
+		offset   int
+		expected []int
+	}{
+		{TiePolicyOrdinal, 0, []int{1, 2, 3, 4, 5}},
+		{TiePolicyCompetition, 0, []int{1, 2, 2, 4, 4}},
+		{TiePolicyDense, 0, []int{1, 2, 2, 3, 3}},
+		// A page starting in the middle of a tie keeps the rank of the tie
+		{TiePolicyOrdinal, 2, []int{3, 4, 5}},
+		{TiePolicyCompetition, 2, []int{2, 4, 4}},
+		{TiePolicyDense, 2, []int{2, 3, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s from %d", tt.policy, tt.offset), func(t *testing.T) {
+			ranked := index.RankedRange(tt.offset, 10, tt.policy)
+			ranks := make([]int, 0, len(ranked))
+			for i, player := range ranked {
+				ranks = append(ranks, player.Rank)
+				if player.Player != players[tt.offset+i] {
+					t.Errorf("expected %s at position %d, got %s", players[tt.offset+i].Player().Id(), tt.offset+i, player.Player.Player().Id())
+				}
+			}
+			if !slices.Equal(ranks, tt.expected) {
+				t.Errorf("expected ranks %v, got %v", tt.expected, ranks)
+			}
+		})
+	}
+}
+
+func TestRankIndex_RankedRange_TiedByTieBreaker(t *testing.T) {
+	index := NewRankIndex(TieBreakerLowerLevel)
+	for _, player := range []*Player{NewPlayer("a", 1, "US"), NewPlayer("b", 2, "US"), NewPlayer("c", 1, "US")} {
+		compPlayer := NewCompetingPlayer(player)
+		compPlayer.AddScore(20)
+		index.Upsert(compPlayer)
+	}
+
+	// Players with the same score and level are tied
+	for policy, expected := range map[string][]int{TiePolicyCompetition: {1, 1, 3}, TiePolicyDense: {1, 1, 2}} {
+		ranked := index.RankedRange(1, 2, policy)
+		if ranks := []int{index.RankedRange(0, 1, policy)[0].Rank, ranked[0].Rank, ranked[1].Rank}; !slices.Equal(ranks, expected) {
+			t.Errorf("expected %s ranks %v, got %v", policy, expected, ranks)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// Sources of score changes
+const (
+	// SourceApi is a score submitted with the score submission endpoint
+	SourceApi = "api"
+	// SourceBatch is a score submitted in a batch
+	SourceBatch = "batch"
+	// SourceAdjustment is a change of the score made by a moderator, added to the score whatever the scoring mode
+	SourceAdjustment = "adjustment"
+	// SourceVoid is the change of the score when a moderator voids a submission
+	SourceVoid = "void"
+)
+
+// ScoreChange is an accepted score submission or a moderation of the score, kept in the score history of a player
+type ScoreChange struct {
+	// Order in which the changes of the competition were applied, from 1. It is 0 for changes persisted without it
+	Seq int
+	At  time.Time
+	// Points submitted
+	Points int
+	// Change of the score once the scoring mode is applied, and the resulting score
+	Delta int
+	Score int
+	// Where the submission comes from, such as SourceApi
+	Source       string
+	SubmissionId string
+	// Why a moderator changed the score
+	Reason string
+	// Whether a moderator voided the submission. Voided submissions no longer count towards the score
+	Voided bool
+	// Seq of the submission voided by a change with the SourceVoid source
+	VoidedSeq int
+}
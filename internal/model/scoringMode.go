@@ -0,0 +1,27 @@
+package model
+
+import "errors"
+
+// Scoring modes deciding how a submitted score changes the score of a player, selected with config.ScoringMode
+const (
+	// ScoringIncrement adds the submitted points to the score
+	ScoringIncrement = "increment"
+	// ScoringPenalties adds the submitted points to the score, negative points are accepted as penalties
+	ScoringPenalties = "penalties"
+	// ScoringBest keeps the best single score submitted
+	ScoringBest = "best"
+	// ScoringLatest keeps the latest score submitted
+	ScoringLatest = "latest"
+	// ScoringWindow adds the submitted points to the score, up to config.MaxPointsPerWindow in each config.ScoreWindow
+	ScoringWindow = "window"
+)
+
+var ErrInvalidScoringMode = errors.New("scoring mode must be increment, penalties, best, latest or window")
+
+func ValidateScoringMode(mode string) error {
+	switch mode {
+	case ScoringIncrement, ScoringPenalties, ScoringBest, ScoringLatest, ScoringWindow:
+		return nil
+	}
+	return ErrInvalidScoringMode
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"leaderboard/internal/config"
+	"time"
+)
+
+// ScoreSubmission is a score submitted for a player, with an optional client-supplied submission ID
+type ScoreSubmission struct {
+	PlayerId     string
+	Points       int
+	SubmissionId string
+	Source       string
+	// Validate is called before the submission is applied, unless it is a replay, with the statistics of the points
+	// submitted by the other players. An error rejects the submission. The competition is locked while it runs,
+	// so it can read the player but must not call the competition.
+	Validate func(player *CompetingPlayer, others PointStats) error
+}
+
+// SubmissionResult tells whether a score submission was applied and how it changed the score, or why it was rejected.
+// A submission that is neither applied nor rejected is a replay.
+type SubmissionResult struct {
+	Applied bool
+	Change  ScoreChange
+	Err     error
+}
+
+// AppliedSubmission is a score submission applied to a competition with a client-supplied submission ID
+type AppliedSubmission struct {
+	PlayerId     string
+	SubmissionId string
+	AppliedAt    time.Time
+}
+
+type submissionKey struct {
+	playerId     string
+	submissionId string
+}
+
+// submissionLog remembers the submission IDs applied to a competition for config.SubmissionIdTTL,
+// so a submission retried by a client is not applied twice.
+// Submission IDs are scoped to a player. A submissionLog is not safe for concurrent use.
+type submissionLog struct {
+	appliedAt map[submissionKey]time.Time
+	// Submissions in the order they were applied, to forget the expired ones first
+	applied []AppliedSubmission
+}
+
+func newSubmissionLog() *submissionLog {
+	return &submissionLog{appliedAt: make(map[submissionKey]time.Time)}
+}
+
+// seen returns true if the submission was applied less than config.SubmissionIdTTL ago
+func (l *submissionLog) seen(playerId, submissionId string, now time.Time) bool {
+	l.expire(now)
+	_, found := l.appliedAt[submissionKey{playerId, submissionId}]
+	return found
+}
+
+func (l *submissionLog) add(submission AppliedSubmission) {
+	key := submissionKey{submission.PlayerId, submission.SubmissionId}
+	if _, found := l.appliedAt[key]; found {
+		return
+	}
+	l.appliedAt[key] = submission.AppliedAt
+	l.applied = append(l.applied, submission)
+}
+
+// expire forgets the submissions applied config.SubmissionIdTTL ago or earlier
+func (l *submissionLog) expire(now time.Time) {
+	expired := 0
+	for expired < len(l.applied) && !now.Before(l.applied[expired].AppliedAt.Add(config.SubmissionIdTTL)) {
+		submission := l.applied[expired]
+		delete(l.appliedAt, submissionKey{submission.PlayerId, submission.SubmissionId})
+		expired++
+	}
+	l.applied = l.applied[expired:]
+}
+
+// list returns the submissions that have not expired, in the order they were applied
+func (l *submissionLog) list(now time.Time) []AppliedSubmission {
+	l.expire(now)
+	return append([]AppliedSubmission{}, l.applied...)
+}
@@ -0,0 +1,48 @@
+package model
+
+import "errors"
+
+// Tie-breakers ordering players with the same score in a competition, selected with config.TieBreaker
+const (
+	// TieBreakerPlayerId orders tied players by player ID
+	TieBreakerPlayerId = "player_id"
+	// TieBreakerEarliest ranks first the player who reached the score first
+	TieBreakerEarliest = "earliest"
+	// TieBreakerFewestSubmissions ranks first the player who submitted the fewest scores
+	TieBreakerFewestSubmissions = "fewest_submissions"
+	// TieBreakerHigherLevel ranks first the player with the higher level
+	TieBreakerHigherLevel = "higher_level"
+	// TieBreakerLowerLevel ranks first the player with the lower level
+	TieBreakerLowerLevel = "lower_level"
+	// TieBreakerShared gives tied players the same rank. They are listed by player ID
+	TieBreakerShared = "shared"
+)
+
+var ErrInvalidTieBreaker = errors.New("tie-breaker must be player_id, earliest, fewest_submissions, higher_level, lower_level or shared")
+
+func ValidateTieBreaker(tieBreaker string) error {
+	switch tieBreaker {
+	case TieBreakerPlayerId, TieBreakerEarliest, TieBreakerFewestSubmissions, TieBreakerHigherLevel, TieBreakerLowerLevel, TieBreakerShared:
+		return nil
+	}
+	return ErrInvalidTieBreaker
+}
+
+// rankKeyFor returns the position of a player in a rank index.
+// Only the value used by the tie-breaker is set, so players are tied when it is equal.
+func rankKeyFor(player *CompetingPlayer, tieBreaker string) rankKey {
+	key := rankKey{score: player.Score(), playerId: player.Player().Id()}
+	switch tieBreaker {
+	case TieBreakerEarliest:
+		if !player.ImprovedAt().IsZero() {
+			key.improvedAt = player.ImprovedAt().UnixNano()
+		}
+	case TieBreakerFewestSubmissions:
+		key.submissions = player.Submissions()
+	case TieBreakerHigherLevel:
+		key.level = -player.Level()
+	case TieBreakerLowerLevel:
+		key.level = player.Level()
+	}
+	return key
+}
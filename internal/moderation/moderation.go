@@ -0,0 +1,185 @@
+package moderation
+
+import (
+	"errors"
+	"leaderboard/internal/model"
+	"leaderboard/internal/storage"
+	"leaderboard/internal/timeprovider"
+)
+
+var (
+	ErrLeaderboardIdEmpty  = errors.New("leaderboard ID cannot be empty")
+	ErrPlayerIdEmpty       = errors.New("player ID cannot be empty")
+	ErrSubmissionIdEmpty   = errors.New("a submission ID or sequence number is required")
+	ErrSubmissionAmbiguous = errors.New("a submission ID and a sequence number cannot both be given")
+	ErrReasonEmpty         = errors.New("a reason is required")
+	ErrPointsZero          = errors.New("points of an adjustment cannot be zero")
+	ErrCompetitionNotFound = errors.New("competition not found")
+	ErrPlayerNotFound      = errors.New("player not found")
+	ErrPlayerBanned        = errors.New("player is already banned")
+	ErrPlayerNotBanned     = errors.New("player is not banned")
+)
+
+// Moderation actions recorded in the audit log
+const (
+	ActionVoid       = "void"
+	ActionAdjust     = "adjust"
+	ActionDisqualify = "disqualify"
+	ActionBan        = "ban"
+	ActionUnban      = "unban"
+)
+
+// VoidSubmission cancels a submission applied for a player in a competition and recomputes their score without it.
+// The submission is identified by its submission ID, or by its sequence number in the score history of the player.
+var VoidSubmission = func(leaderboardId, playerId, submissionId string, seq int, reason string) (*storage.AuditRecord, error) {
+	if submissionId == "" && seq <= 0 {
+		return nil, ErrSubmissionIdEmpty
+	} else if submissionId != "" && seq > 0 {
+		return nil, ErrSubmissionAmbiguous
+	}
+	comp, err := getModeratedCompetition(leaderboardId, playerId, reason)
+	if err != nil {
+		return nil, err
+	}
+	var change model.ScoreChange
+	if seq > 0 {
+		change, err = comp.VoidChange(playerId, seq, reason)
+	} else {
+		change, err = comp.VoidSubmission(playerId, submissionId, reason)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := storage.Current.PutScore(comp.Id(), playerId, change); err != nil {
+		return nil, err
+	}
+	return audit(storage.AuditRecord{
+		Action:        ActionVoid,
+		LeaderboardId: comp.Id(),
+		PlayerId:      playerId,
+		SubmissionId:  change.SubmissionId,
+		Seq:           change.VoidedSeq,
+		Points:        change.Delta,
+		Reason:        reason,
+	})
+}
+
+// AdjustScore adds points, or removes them if negative, to the score of a player in a competition
+var AdjustScore = func(leaderboardId, playerId string, points int, reason string) (*storage.AuditRecord, error) {
+	if points == 0 {
+		return nil, ErrPointsZero
+	}
+	comp, err := getModeratedCompetition(leaderboardId, playerId, reason)
+	if err != nil {
+		return nil, err
+	}
+	change, err := comp.AdjustScore(playerId, points, reason)
+	if err != nil {
+		return nil, err
+	}
+	if err := storage.Current.PutScore(comp.Id(), playerId, change); err != nil {
+		return nil, err
+	}
+	return audit(storage.AuditRecord{
+		Action:        ActionAdjust,
+		LeaderboardId: comp.Id(),
+		PlayerId:      playerId,
+		Points:        points,
+		Reason:        reason,
+	})
+}
+
+// DisqualifyPlayer removes a player from the ranking of a competition. Their score and history are kept
+var DisqualifyPlayer = func(leaderboardId, playerId, reason string) (*storage.AuditRecord, error) {
+	comp, err := getModeratedCompetition(leaderboardId, playerId, reason)
+	if err != nil {
+		return nil, err
+	}
+	if err := comp.Disqualify(playerId); err != nil {
+		return nil, err
+	}
+	if err := storage.Current.PutCompetition(comp); err != nil {
+		return nil, err
+	}
+	return audit(storage.AuditRecord{
+		Action:        ActionDisqualify,
+		LeaderboardId: comp.Id(),
+		PlayerId:      playerId,
+		Reason:        reason,
+	})
+}
+
+// BanPlayer prevents a player from joining competitions. A player already in a competition stays in it
+var BanPlayer = func(playerId, reason string) (*storage.AuditRecord, error) {
+	return setBanned(playerId, reason, true)
+}
+
+// UnbanPlayer allows a banned player to join competitions again
+var UnbanPlayer = func(playerId, reason string) (*storage.AuditRecord, error) {
+	return setBanned(playerId, reason, false)
+}
+
+// GetAuditLog returns the moderations of a player, or of all players if the player ID is empty, oldest first
+var GetAuditLog = func(playerId string) []storage.AuditRecord {
+	records := []storage.AuditRecord{}
+	for _, record := range storage.Current.ListAudit() {
+		if playerId == "" || record.PlayerId == playerId {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+func setBanned(playerId, reason string, banned bool) (*storage.AuditRecord, error) {
+	if playerId == "" {
+		return nil, ErrPlayerIdEmpty
+	}
+	if reason == "" {
+		return nil, ErrReasonEmpty
+	}
+	player, found := storage.Current.GetPlayer(playerId)
+	if !found {
+		return nil, ErrPlayerNotFound
+	}
+	if banned && player.Banned() {
+		return nil, ErrPlayerBanned
+	} else if !banned && !player.Banned() {
+		return nil, ErrPlayerNotBanned
+	}
+	player.SetBanned(banned)
+	if err := storage.Current.PutPlayer(player); err != nil {
+		return nil, err
+	}
+
+	action := ActionBan
+	if !banned {
+		action = ActionUnban
+	}
+	return audit(storage.AuditRecord{Action: action, PlayerId: playerId, Reason: reason})
+}
+
+func getModeratedCompetition(leaderboardId, playerId, reason string) (model.ICompetition, error) {
+	if leaderboardId == "" {
+		return nil, ErrLeaderboardIdEmpty
+	}
+	if playerId == "" {
+		return nil, ErrPlayerIdEmpty
+	}
+	if reason == "" {
+		return nil, ErrReasonEmpty
+	}
+	comp, found := storage.Current.GetCompetition(leaderboardId)
+	if !found {
+		return nil, ErrCompetitionNotFound
+	}
+	return comp, nil
+}
+
+// audit records a moderation in the audit log
+func audit(record storage.AuditRecord) (*storage.AuditRecord, error) {
+	record.At = timeprovider.Current.Now()
+	if err := storage.Current.PutAudit(record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
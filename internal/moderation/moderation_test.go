@@ -0,0 +1,108 @@
+package moderation
+
+import (
+	"leaderboard/internal/model"
+	"leaderboard/internal/storage"
+	"testing"
+)
+
+// startCompetition stores alice and bob in a started competition
+func startCompetition(t *testing.T) model.ICompetition {
+	t.Helper()
+	storage.Current = storage.NewMemoryStore()
+	comp := model.NewCompetition(1)
+	for _, id := range []string{"alice", "bob"} {
+		player := model.NewPlayer(id, 1, "US")
+		_ = storage.Current.PutPlayer(player)
+		_ = comp.AddPlayer(player)
+	}
+	if err := comp.Start(); err != nil {
+		t.Fatalf("Start() returned error %v", err)
+	}
+	_ = storage.Current.PutCompetition(comp)
+	return comp
+}
+
+func TestModeration_RecordsAuditLog(t *testing.T) {
+	defer func() { storage.Current = storage.NewMemoryStore() }()
+	comp := startCompetition(t)
+	_, _ = comp.SubmitScore("alice", 50, "s1")
+
+	if record, err := VoidSubmission(comp.Id(), "alice", "s1", 0, "impossible score"); err != nil || record.Points != -50 {
+		t.Errorf("expected the void to remove 50 points, got %+v (%v)", record, err)
+	}
+	if _, err := AdjustScore(comp.Id(), "bob", 5, "compensation"); err != nil {
+		t.Errorf("AdjustScore() returned error %v", err)
+	}
+	if _, err := DisqualifyPlayer(comp.Id(), "alice", "cheating"); err != nil {
+		t.Errorf("DisqualifyPlayer() returned error %v", err)
+	}
+	if _, err := BanPlayer("alice", "cheating"); err != nil {
+		t.Errorf("BanPlayer() returned error %v", err)
+	}
+	if player, _ := storage.Current.GetPlayer("alice"); !player.Banned() {
+		t.Errorf("expected alice to be banned")
+	}
+
+	actions := []string{}
+	for _, record := range GetAuditLog("alice") {
+		actions = append(actions, record.Action)
+	}
+	if len(actions) != 3 || actions[0] != ActionVoid || actions[1] != ActionDisqualify || actions[2] != ActionBan {
+		t.Errorf("expected the void, disqualification and ban of alice, got %v", actions)
+	}
+	if len(GetAuditLog("")) != 4 {
+		t.Errorf("expected 4 moderations, got %d", len(GetAuditLog("")))
+	}
+}
+
+func TestVoidSubmission_BySeq_VoidsSubmissionWithoutId(t *testing.T) {
+	defer func() { storage.Current = storage.NewMemoryStore() }()
+	comp := startCompetition(t)
+	_ = comp.AddScore("alice", 30)
+	_ = comp.AddScore("alice", 20)
+	history, _ := comp.History("alice")
+
+	record, err := VoidSubmission(comp.Id(), "alice", "", history[1].Seq, "impossible score")
+	if err != nil || record.Points != -20 || record.Seq != history[1].Seq {
+		t.Fatalf("expected the second submission to be voided, got %+v (%v)", record, err)
+	}
+	if score := comp.PlayersMap()["alice"].Score(); score != 30 {
+		t.Errorf("expected the score to be recomputed to 30, got %d", score)
+	}
+	if _, err := VoidSubmission(comp.Id(), "alice", "", history[1].Seq, "again"); err != model.ErrSubmissionNotFound {
+		t.Errorf("expected a voided submission not to be voided again, got %v", err)
+	}
+}
+
+func TestModeration_Errors(t *testing.T) {
+	defer func() { storage.Current = storage.NewMemoryStore() }()
+	comp := startCompetition(t)
+
+	tests := []struct {
+		name     string
+		moderate func() error
+		expected error
+	}{
+		{"ReasonEmpty", func() error { _, err := AdjustScore(comp.Id(), "alice", 5, ""); return err }, ErrReasonEmpty},
+		{"PointsZero", func() error { _, err := AdjustScore(comp.Id(), "alice", 0, "none"); return err }, ErrPointsZero},
+		{"SubmissionIdEmpty", func() error { _, err := VoidSubmission(comp.Id(), "alice", "", 0, "cheat"); return err }, ErrSubmissionIdEmpty},
+		{"SubmissionAmbiguous", func() error { _, err := VoidSubmission(comp.Id(), "alice", "s1", 1, "cheat"); return err }, ErrSubmissionAmbiguous},
+		{"SubmissionNotFound", func() error { _, err := VoidSubmission(comp.Id(), "alice", "s9", 0, "cheat"); return err }, model.ErrSubmissionNotFound},
+		{"SeqNotFound", func() error { _, err := VoidSubmission(comp.Id(), "alice", "", 9, "cheat"); return err }, model.ErrSubmissionNotFound},
+		{"CompetitionNotFound", func() error { _, err := DisqualifyPlayer("unknown", "alice", "cheat"); return err }, ErrCompetitionNotFound},
+		{"PlayerNotInCompetition", func() error { _, err := DisqualifyPlayer(comp.Id(), "carol", "cheat"); return err }, model.ErrPlayerNotFound},
+		{"PlayerNotFound", func() error { _, err := BanPlayer("carol", "cheat"); return err }, ErrPlayerNotFound},
+		{"PlayerNotBanned", func() error { _, err := UnbanPlayer("bob", "mistake"); return err }, ErrPlayerNotBanned},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.moderate(); err != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+	if len(GetAuditLog("")) != 0 {
+		t.Errorf("expected failed moderations not to be audited")
+	}
+}
@@ -0,0 +1,163 @@
+package notifications
+
+import (
+	"errors"
+	"leaderboard/internal/config"
+	"leaderboard/internal/events"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var ErrTooManySubscriptions = errors.New("too many event streams for this player")
+
+// Types of the notifications sent to players
+const (
+	// Queued is sent when a player joins matchmaking and waits in a competition
+	Queued = "queued"
+	// Matched is sent when the competition of a waiting player starts
+	Matched = "matched"
+	// CompetitionStarted is sent to each player of a competition when it starts
+	CompetitionStarted = "competition_started"
+	// RankChanged is sent when the score or rank of a player changes
+	RankChanged = "rank_changed"
+	// CompetitionEnded is sent to each player of a competition at its end with their final standing
+	CompetitionEnded = "competition_ended"
+)
+
+// Notification tells a player about their matchmaking or their competition
+type Notification struct {
+	Type          string    `json:"type"`
+	PlayerId      string    `json:"player_id"`
+	LeaderboardId string    `json:"leaderboard_id"`
+	At            time.Time `json:"at"`
+	// End of the competition, in the competition_started notification
+	EndsAt time.Time `json:"ends_at,omitzero"`
+	// Score and rank of the player, in the rank_changed and competition_ended notifications
+	Standing *Standing `json:"standing,omitempty"`
+}
+
+// Standing is the score and rank of a player. Ranks follow config.RankTiePolicy and are 0 when the player is not ranked.
+type Standing struct {
+	Score        int `json:"score"`
+	Rank         int `json:"rank"`
+	PreviousRank int `json:"previous_rank,omitempty"`
+}
+
+// Subscription receives the notifications sent to a player
+type Subscription struct {
+	playerId      string
+	notifications chan Notification
+}
+
+var (
+	// Subscriptions of each player
+	subscriptions = struct {
+		sync.Mutex
+		byPlayer map[string]map[*Subscription]bool
+	}{byPlayer: map[string]map[*Subscription]bool{}}
+
+	notificationsDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "leaderboard_player_events_dropped_total",
+		Help: "The total number of events dropped because the queue of a player event stream was full",
+	})
+)
+
+// Attach subscribes to the domain events to notify the players. The notifications are queued for the subscriptions
+// in the goroutine of the publisher, so a full subscription queue is the only place where one can be dropped.
+// Returns a function that unsubscribes.
+func Attach() (detach func()) {
+	return events.Subscribe(notify)
+}
+
+// notify sends the notifications of a domain event to its players
+func notify(event events.Event) {
+	switch e := event.(type) {
+	case events.PlayerQueued:
+		Publish(Notification{Type: Queued, PlayerId: e.PlayerId, LeaderboardId: e.LeaderboardId, At: e.At})
+	case events.CompetitionStarted:
+		for _, playerId := range e.PlayerIds {
+			Publish(Notification{Type: Matched, PlayerId: playerId, LeaderboardId: e.LeaderboardId, At: e.StartedAt})
+			Publish(Notification{Type: CompetitionStarted, PlayerId: playerId, LeaderboardId: e.LeaderboardId, At: e.StartedAt, EndsAt: e.EndsAt})
+		}
+	case events.ScoreAdded:
+		if e.Delta == 0 && e.Rank == e.PreviousRank {
+			return
+		}
+		Publish(Notification{
+			Type:          RankChanged,
+			PlayerId:      e.PlayerId,
+			LeaderboardId: e.LeaderboardId,
+			At:            e.At,
+			Standing:      &Standing{Score: e.Score, Rank: e.Rank, PreviousRank: e.PreviousRank},
+		})
+	case events.PlayerDisqualified:
+		Publish(Notification{
+			Type:          RankChanged,
+			PlayerId:      e.PlayerId,
+			LeaderboardId: e.LeaderboardId,
+			At:            e.At,
+			Standing:      &Standing{Score: e.Score, PreviousRank: e.PreviousRank},
+		})
+	case events.CompetitionEnded:
+		for _, standing := range e.Standings {
+			Publish(Notification{
+				Type:          CompetitionEnded,
+				PlayerId:      standing.PlayerId,
+				LeaderboardId: e.LeaderboardId,
+				At:            e.EndedAt,
+				Standing:      &Standing{Score: standing.Score, Rank: standing.Rank},
+			})
+		}
+	}
+}
+
+// Publish queues a notification for the subscriptions of its player without blocking.
+// The notification is dropped for a subscription whose queue is full.
+func Publish(notification Notification) {
+	subscriptions.Lock()
+	defer subscriptions.Unlock()
+	for subscription := range subscriptions.byPlayer[notification.PlayerId] {
+		select {
+		case subscription.notifications <- notification:
+		default:
+			notificationsDropped.Inc()
+		}
+	}
+}
+
+// Subscribe receives the notifications sent to a player, up to config.MaxEventStreamsPerPlayer subscriptions per player.
+// The subscription must be unsubscribed.
+var Subscribe = func(playerId string) (*Subscription, error) {
+	subscriptions.Lock()
+	defer subscriptions.Unlock()
+	playerSubscriptions, found := subscriptions.byPlayer[playerId]
+	if !found {
+		playerSubscriptions = map[*Subscription]bool{}
+		subscriptions.byPlayer[playerId] = playerSubscriptions
+	}
+	if len(playerSubscriptions) >= config.MaxEventStreamsPerPlayer {
+		return nil, ErrTooManySubscriptions
+	}
+	subscription := &Subscription{playerId: playerId, notifications: make(chan Notification, config.EventStreamBufferSize)}
+	playerSubscriptions[subscription] = true
+	return subscription, nil
+}
+
+// Notifications returns the queue of the notifications sent to the player
+func (s *Subscription) Notifications() <-chan Notification {
+	return s.notifications
+}
+
+// Unsubscribe stops receiving the notifications of the player
+func (s *Subscription) Unsubscribe() {
+	subscriptions.Lock()
+	defer subscriptions.Unlock()
+	playerSubscriptions := subscriptions.byPlayer[s.playerId]
+	delete(playerSubscriptions, s)
+	if len(playerSubscriptions) == 0 {
+		delete(subscriptions.byPlayer, s.playerId)
+	}
+}
@@ -0,0 +1,98 @@
+package notifications
+
+import (
+	"leaderboard/internal/config"
+	"leaderboard/internal/events"
+	"testing"
+	"time"
+)
+
+func TestPublish_QueuesNotificationsOfPlayer(t *testing.T) {
+	defer func(size int) { config.EventStreamBufferSize = size }(config.EventStreamBufferSize)
+	config.EventStreamBufferSize = 2
+	alice, _ := Subscribe("alice")
+	defer alice.Unsubscribe()
+	bob, _ := Subscribe("bob")
+	defer bob.Unsubscribe()
+
+	for _, notificationType := range []string{Queued, Matched, CompetitionStarted} {
+		Publish(Notification{Type: notificationType, PlayerId: "alice"})
+	}
+
+	if len(bob.Notifications()) != 0 {
+		t.Errorf("expected bob not to receive the notifications of alice, got %d", len(bob.Notifications()))
+	}
+	if len(alice.Notifications()) != 2 {
+		t.Fatalf("expected the notifications to be dropped once the queue is full, got %d queued", len(alice.Notifications()))
+	}
+	if notification := <-alice.Notifications(); notification.Type != Queued {
+		t.Errorf("expected the queued notification first, got %s", notification.Type)
+	}
+}
+
+func TestSubscribe_CapsSubscriptions(t *testing.T) {
+	defer func(max int) { config.MaxEventStreamsPerPlayer = max }(config.MaxEventStreamsPerPlayer)
+	config.MaxEventStreamsPerPlayer = 1
+
+	subscription, err := Subscribe("alice")
+	if err != nil {
+		t.Fatalf("Subscribe() returned error %v", err)
+	}
+	if _, err := Subscribe("alice"); err != ErrTooManySubscriptions {
+		t.Errorf("expected %v, got %v", ErrTooManySubscriptions, err)
+	}
+	subscription.Unsubscribe()
+	if subscription, err := Subscribe("alice"); err != nil {
+		t.Errorf("expected a subscription to fit after the first left, got %v", err)
+	} else {
+		subscription.Unsubscribe()
+	}
+	if len(subscriptions.byPlayer) != 0 {
+		t.Errorf("expected no subscriptions left, got %v", subscriptions.byPlayer)
+	}
+}
+
+func TestAttach_NotifiesPlayersOfDomainEvents(t *testing.T) {
+	detach := Attach()
+	defer detach()
+	subscription, _ := Subscribe("alice")
+	defer subscription.Unsubscribe()
+
+	events.Publish(events.PlayerQueued{PlayerId: "alice", LeaderboardId: "comp1"})
+	events.Publish(events.CompetitionStarted{LeaderboardId: "comp1", PlayerIds: []string{"alice", "bob"}})
+	// A submission that changes neither the score nor the rank is not notified
+	events.Publish(events.ScoreAdded{LeaderboardId: "comp1", PlayerId: "alice", Points: 5, Score: 10, Rank: 1, PreviousRank: 1})
+	events.Publish(events.ScoreAdded{LeaderboardId: "comp1", PlayerId: "alice", Points: 20, Delta: 20, Score: 30, Rank: 1, PreviousRank: 2})
+	events.Publish(events.CompetitionEnded{LeaderboardId: "comp1", Standings: []events.Standing{{PlayerId: "alice", Score: 30, Rank: 1}}})
+
+	expected := []string{Queued, Matched, CompetitionStarted, RankChanged, CompetitionEnded}
+	for _, notificationType := range expected {
+		select {
+		case notification := <-subscription.Notifications():
+			if notification.Type != notificationType || notification.LeaderboardId != "comp1" {
+				t.Fatalf("expected a %s notification of comp1, got %+v", notificationType, notification)
+			}
+			if notificationType == RankChanged && *notification.Standing != (Standing{Score: 30, Rank: 1, PreviousRank: 2}) {
+				t.Errorf("expected alice to move to rank 1 with 30 points, got %+v", notification.Standing)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected a %s notification", notificationType)
+		}
+	}
+}
+
+func TestAttach_QueuesNotificationsUntilTheStreamIsFull(t *testing.T) {
+	detach := Attach()
+	defer detach()
+	subscription, _ := Subscribe("alice")
+	defer subscription.Unsubscribe()
+
+	standings := []events.Standing{{PlayerId: "alice", Score: 30, Rank: 1}}
+	for range config.EventStreamBufferSize + 1 {
+		events.Publish(events.CompetitionEnded{LeaderboardId: "comp1", Standings: standings})
+	}
+	// The notifications are queued by the publisher, only the one that doesn't fit in the stream is dropped
+	if queued := len(subscription.Notifications()); queued != config.EventStreamBufferSize {
+		t.Errorf("expected %d queued notifications, got %d", config.EventStreamBufferSize, queued)
+	}
+}
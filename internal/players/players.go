@@ -0,0 +1,123 @@
+package players
+
+import (
+	"errors"
+	"leaderboard/internal/matchmaking"
+	"leaderboard/internal/model"
+	"leaderboard/internal/storage"
+	"sync"
+)
+
+var (
+	ErrPlayerIdEmpty      = errors.New("player ID cannot be empty")
+	ErrPlayerNotFound     = errors.New("player not found")
+	ErrPlayerExists       = errors.New("player already exists")
+	ErrInvalidLevel       = model.ErrInvalidLevel
+	ErrInvalidCountryCode = model.ErrInvalidCountryCode
+	ErrPlayerWaiting      = matchmaking.ErrPlayerWaitingForMatch
+)
+
+// Serializes registrations so two requests cannot create the same player
+var mutex = &sync.Mutex{}
+
+var RegisterPlayer = func(id string, level int, countryCode string) (*PlayerResponse, error) {
+	if id == "" {
+		return nil, ErrPlayerIdEmpty
+	}
+	if err := model.ValidateLevel(level); err != nil {
+		return nil, err
+	}
+	if err := model.ValidateCountryCode(countryCode); err != nil {
+		return nil, err
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if _, found := storage.Current.GetPlayer(id); found {
+		return nil, ErrPlayerExists
+	}
+	player := model.NewPlayer(id, level, countryCode)
+	if err := storage.Current.PutPlayer(player); err != nil {
+		return nil, err
+	}
+	return asPlayerResponse(player), nil
+}
+
+var GetPlayer = func(id string) (*PlayerResponse, error) {
+	player, err := getPlayer(id)
+	if err != nil {
+		return nil, err
+	}
+	return asPlayerResponse(player), nil
+}
+
+// UpdatePlayer changes the level and/or country code of a player. Nil values are left unchanged
+var UpdatePlayer = func(id string, level *int, countryCode *string) (*PlayerResponse, error) {
+	player, err := getPlayer(id)
+	if err != nil {
+		return nil, err
+	}
+
+	newLevel := player.Level()
+	if level != nil {
+		newLevel = *level
+	}
+	newCountryCode := player.CountryCode()
+	if countryCode != nil {
+		newCountryCode = *countryCode
+	}
+	if err := matchmaking.UpdatePlayer(player, newLevel, newCountryCode); err != nil {
+		return nil, err
+	}
+	if err := storage.Current.PutPlayer(player); err != nil {
+		return nil, err
+	}
+	return asPlayerResponse(player), nil
+}
+
+// DeletePlayer removes a player. A waiting player is removed from the matchmaking queue.
+// A player in a started competition keeps their entry in its leaderboard but cannot submit scores anymore.
+var DeletePlayer = func(id string) error {
+	player, err := getPlayer(id)
+	if err != nil {
+		return err
+	}
+	// Delete first so the player cannot join again while leaving the queue
+	if err := storage.Current.DeletePlayer(id); err != nil {
+		return err
+	}
+	return matchmaking.LeaveCompetition(player)
+}
+
+func getPlayer(id string) (*model.Player, error) {
+	if id == "" {
+		return nil, ErrPlayerIdEmpty
+	}
+	player, found := storage.Current.GetPlayer(id)
+	if !found {
+		return nil, ErrPlayerNotFound
+	}
+	return player, nil
+}
+
+func asPlayerResponse(player *model.Player) *PlayerResponse {
+	response := &PlayerResponse{
+		Id:          player.Id(),
+		Level:       player.Level(),
+		CountryCode: player.CountryCode(),
+		Banned:      player.Banned(),
+	}
+	if comp := player.Competition(); comp != nil {
+		response.CompetitionId = comp.Id()
+	}
+	return response
+}
+
+type PlayerResponse struct {
+	Id            string `json:"id"`
+	Level         int    `json:"level"`
+	CountryCode   string `json:"country_code"`
+	CompetitionId string `json:"competition_id,omitempty"`
+	Banned        bool   `json:"banned,omitempty"`
+}
@@ -0,0 +1,117 @@
+package players
+
+import (
+	"leaderboard/internal/matchmaking"
+	"leaderboard/internal/storage"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func tearDown() {
+	storage.Current = storage.NewMemoryStore()
+}
+
+func TestRegisterPlayer_ConcurrentRegistrations_OnlyOneSucceeds(t *testing.T) {
+	defer tearDown()
+	var wg sync.WaitGroup
+	var created atomic.Int32
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := RegisterPlayer("alice", 3, "US"); err == nil {
+				created.Add(1)
+			} else if err != ErrPlayerExists {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if created.Load() != 1 {
+		t.Errorf("expected exactly one registration to succeed, got %d", created.Load())
+	}
+}
+
+func TestRegisterPlayer_InvalidInput(t *testing.T) {
+	defer tearDown()
+	tests := []struct {
+		name          string
+		id            string
+		level         int
+		countryCode   string
+		expectedError error
+	}{
+		{"EmptyId", "", 1, "US", ErrPlayerIdEmpty},
+		{"LevelTooLow", "a", 0, "US", ErrInvalidLevel},
+		{"LevelTooHigh", "a", 11, "US", ErrInvalidLevel},
+		{"InvalidCountryCode", "a", 1, "U", ErrInvalidCountryCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := RegisterPlayer(tt.id, tt.level, tt.countryCode); err != tt.expectedError {
+				t.Errorf("expected %v, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestUpdatePlayer_PartialUpdate(t *testing.T) {
+	defer tearDown()
+	_, _ = RegisterPlayer("alice", 3, "US")
+	level := 7
+
+	response, err := UpdatePlayer("alice", &level, nil)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.Level != 7 || response.CountryCode != "US" {
+		t.Errorf("expected level 7 and country US, got %+v", response)
+	}
+}
+
+func TestUpdatePlayer_ConcurrentReads(t *testing.T) {
+	defer tearDown()
+	_, _ = RegisterPlayer("alice", 3, "US")
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			level := i%10 + 1
+			if _, err := UpdatePlayer("alice", &level, nil); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := GetPlayer("alice"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDeletePlayer_WaitingPlayer_RemovedFromQueue(t *testing.T) {
+	defer tearDown()
+	_, _ = RegisterPlayer("alice", 3, "US")
+	comp, err := matchmaking.JoinCompetition("alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := DeletePlayer("alice"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := GetPlayer("alice"); err != ErrPlayerNotFound {
+		t.Errorf("expected ErrPlayerNotFound, got %v", err)
+	}
+	if _, found := storage.Current.GetCompetition(comp.Id()); found {
+		t.Errorf("expected the empty waiting competition to be discarded")
+	}
+}
@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"leaderboard/internal/config"
+)
+
+// FileLogSuffix is appended to the path of the snapshot of a file store to get the path of its log
+const FileLogSuffix = ".log"
+
+// FileStore keeps players and competitions in memory and appends every change to a log next to the snapshot file,
+// so a change costs the size of its record instead of a rewrite of the whole state. Reads are served from memory.
+// The log is compacted into the snapshot every config.FileCompactionInterval and when the store is closed.
+type FileStore struct {
+	*JournaledStore
+}
+
+// OpenFileStore loads the snapshot at path and replays its log, or starts empty if the files do not exist yet.
+// Appended changes are synced to disk at most config.FileSaveDelay after they are accepted.
+func OpenFileStore(path string) (*FileStore, error) {
+	store, err := OpenJournaledStore(path, path+FileLogSuffix, config.FileSaveDelay, config.FileCompactionInterval)
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{JournaledStore: store}, nil
+}
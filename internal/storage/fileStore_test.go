@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"fmt"
+	"leaderboard/internal/config"
+	"leaderboard/internal/model"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore_ListCompetitions_KeepsInsertionOrder(t *testing.T) {
+	store := NewMemoryStore()
+	comps := []model.ICompetition{model.NewCompetition(1), model.NewCompetition(2), model.NewCompetition(3)}
+	for _, comp := range comps {
+		if err := store.PutCompetition(comp); err != nil {
+			t.Fatalf("PutCompetition() returned error %v", err)
+		}
+	}
+	// Updating an existing competition should not change its position
+	_ = store.PutCompetition(comps[0])
+	_ = store.DeleteCompetition(comps[1].Id())
+
+	listed := store.ListCompetitions()
+	if len(listed) != 2 {
+		t.Fatalf("expected 2 competitions, got %d", len(listed))
+	}
+	if listed[0].Id() != comps[0].Id() || listed[1].Id() != comps[2].Id() {
+		t.Errorf("expected competitions in insertion order, got %s and %s", listed[0].Id(), listed[1].Id())
+	}
+}
+
+func TestFileStore_ReopenRestoresPlayersCompetitionsAndScores(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leaderboard.json")
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore() returned error %v", err)
+	}
+
+	alice := model.NewPlayer("alice", 1, "US")
+	bob := model.NewPlayer("bob", 2, "GB")
+	carlos := model.NewPlayer("carlos", 3, "MX")
+	for _, player := range []*model.Player{alice, bob, carlos} {
+		if err := store.PutPlayer(player); err != nil {
+			t.Fatalf("PutPlayer() returned error %v", err)
+		}
+	}
+
+	started := model.NewCompetition(1)
+	_ = started.AddPlayer(alice)
+	_ = started.AddPlayer(bob)
+	_ = started.Start()
+	_ = store.PutCompetition(started)
+	result := started.SubmitScores([]model.ScoreSubmission{{PlayerId: "bob", Points: 15}})[0]
+	if err := store.PutScore(started.Id(), "bob", result.Change); err != nil {
+		t.Fatalf("PutScore() returned error %v", err)
+	}
+
+	waiting := model.NewCompetition(3)
+	_ = waiting.AddPlayer(carlos)
+	_ = store.PutCompetition(waiting)
+
+	reopened, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore() returned error %v", err)
+	}
+
+	if len(reopened.ListPlayers()) != 3 {
+		t.Errorf("expected 3 players, got %d", len(reopened.ListPlayers()))
+	}
+	comps := reopened.ListCompetitions()
+	if len(comps) != 2 || comps[0].Id() != started.Id() || comps[1].Id() != waiting.Id() {
+		t.Fatalf("expected competitions %s and %s in order, got %v", started.Id(), waiting.Id(), comps)
+	}
+
+	restored := comps[0]
+	if !restored.StartedAt().Equal(started.StartedAt()) || !restored.EndsAt().Equal(started.EndsAt()) {
+		t.Errorf("expected start and end times to be restored, got %v and %v", restored.StartedAt(), restored.EndsAt())
+	}
+	leaderboard := restored.Leaderboard()
+	if len(leaderboard) != 2 || leaderboard[0].Player().Id() != "bob" || leaderboard[0].Score() != 15 {
+		t.Errorf("expected bob to lead with 15 points, got %v", leaderboard)
+	}
+
+	restoredBob, _ := reopened.GetPlayer("bob")
+	if restoredBob.Competition() == nil || restoredBob.Competition().Id() != started.Id() {
+		t.Errorf("expected bob to be linked to competition %s, got %v", started.Id(), restoredBob.Competition())
+	}
+	restoredCarlos, _ := reopened.GetPlayer("carlos")
+	if restoredCarlos.Competition() == nil || !restoredCarlos.Competition().StartedAt().IsZero() {
+		t.Errorf("expected carlos to be linked to a waiting competition, got %v", restoredCarlos.Competition())
+	}
+}
+
+func TestOpenFileStore_MissingFileStartsEmpty(t *testing.T) {
+	store, err := OpenFileStore(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("OpenFileStore() returned error %v", err)
+	}
+	if len(store.ListPlayers()) != 0 || len(store.ListCompetitions()) != 0 {
+		t.Errorf("expected empty store")
+	}
+}
+
+func TestFileStore_AppendsChangesAndCompactsOnClose(t *testing.T) {
+	defer func() { config.FileCompactionInterval = 5 * time.Minute }()
+	config.FileCompactionInterval = 0
+	path := filepath.Join(t.TempDir(), "leaderboard.json")
+	store, _ := OpenFileStore(path)
+	for _, player := range []*model.Player{model.NewPlayer("alice", 1, "US"), model.NewPlayer("bob", 1, "GB")} {
+		_ = store.PutPlayer(player)
+	}
+	comp := startCompetitionForTest(t, store, 1, "alice", "bob")
+	addScoreForTest(store, comp, "bob", 15)
+
+	// Changes are appended to the log, the snapshot is only written by the compaction
+	if snapshot, _ := ReadSnapshotFile(path); len(snapshot.Players) != 0 || len(snapshot.Competitions) != 0 {
+		t.Errorf("expected no snapshot before the compaction, got %+v", snapshot)
+	}
+	if info, err := os.Stat(path + FileLogSuffix); err != nil || info.Size() == 0 {
+		t.Fatalf("expected the changes in the log, got %v", err)
+	}
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() returned error %v", err)
+	}
+	snapshot, _ := ReadSnapshotFile(path)
+	if len(snapshot.Competitions) != 1 || snapshot.Competitions[0].Scores["bob"] != 15 {
+		t.Errorf("expected the score of bob in the snapshot written on close, got %+v", snapshot.Competitions)
+	}
+	if info, err := os.Stat(path + FileLogSuffix); err != nil || info.Size() != 0 {
+		t.Errorf("expected the log to be truncated on close, got %v", info)
+	}
+
+	reopened, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore() returned error %v", err)
+	}
+	defer reopened.Close()
+	restored, _ := reopened.GetCompetition(comp.Id())
+	if score := restored.PlayersMap()["bob"].Score(); score != 15 {
+		t.Errorf("expected the score to be restored, got %d", score)
+	}
+}
+
+func TestTakeSnapshot_WhilePlayersJoinAndScore(t *testing.T) {
+	store := NewMemoryStore()
+	for _, player := range []*model.Player{model.NewPlayer("a", 1, "US"), model.NewPlayer("b", 1, "GB")} {
+		_ = store.PutPlayer(player)
+	}
+	started := startCompetitionForTest(t, store, 1, "a", "b")
+	waiting := model.NewCompetition(1)
+	_ = store.PutCompetition(waiting)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range config.MaxPlayersForCompetition - 1 {
+			_ = waiting.AddPlayer(model.NewPlayer(fmt.Sprintf("p%d", i), 1, "US"))
+			_ = started.AddScore("a", 1)
+		}
+	}()
+	for range 50 {
+		TakeSnapshot(store)
+	}
+	<-done
+}
@@ -0,0 +1,563 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"leaderboard/internal/model"
+	"leaderboard/internal/wal"
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Journal record types
+const (
+	RecordPlayer           = "player"
+	RecordDeletePlayer     = "delete_player"
+	RecordCreate           = "create"
+	RecordJoin             = "join"
+	RecordLeave            = "leave"
+	RecordStart            = "start"
+	RecordScore            = "score"
+	RecordDelete           = "delete"
+	RecordResults          = "results"
+	RecordDisqualify       = "disqualify"
+	RecordEnd              = "end"
+	RecordAudit            = "audit"
+	RecordWebhook          = "webhook"
+	RecordDeleteWebhook    = "delete_webhook"
+	RecordDeadLetter       = "dead_letter"
+	RecordDeleteDeadLetter = "delete_dead_letter"
+)
+
+// JournalRecord is a single entry of the write-ahead log.
+// Records are idempotent so replaying them on top of a newer snapshot gives the same state: changes of a score, applied
+// submissions and audit records already in the snapshot are skipped, e.g. after a crash before the log was truncated.
+type JournalRecord struct {
+	Type          string            `json:"type"`
+	Seq           int               `json:"seq,omitempty"`
+	CompetitionId string            `json:"competition_id,omitempty"`
+	PlayerId      string            `json:"player_id,omitempty"`
+	Level         int               `json:"level,omitempty"`
+	CountryCode   string            `json:"country_code,omitempty"`
+	StartedAt     time.Time         `json:"started_at,omitzero"`
+	EndsAt        time.Time         `json:"ends_at,omitzero"`
+	TieBreaker    string            `json:"tie_breaker,omitempty"`
+	ScoringMode   string            `json:"scoring_mode,omitempty"`
+	Score         int               `json:"score,omitempty"`
+	SubmissionId  string            `json:"submission_id,omitempty"`
+	VoidedSeq     int               `json:"voided_seq,omitempty"`
+	SubmittedAt   time.Time         `json:"submitted_at,omitzero"`
+	Points        int               `json:"points,omitempty"`
+	Source        string            `json:"source,omitempty"`
+	Reason        string            `json:"reason,omitempty"`
+	Banned        bool              `json:"banned,omitempty"`
+	Results       []ResultRecord    `json:"results,omitempty"`
+	Audit         *AuditRecord      `json:"audit,omitempty"`
+	Webhook       *WebhookRecord    `json:"webhook,omitempty"`
+	DeadLetter    *DeadLetterRecord `json:"dead_letter,omitempty"`
+}
+
+// JournaledStore keeps players and competitions in memory and appends every accepted change to a write-ahead log.
+// On open, the last snapshot is loaded and the log is replayed on top of it.
+// The log is compacted into a new snapshot periodically so replay time stays bounded.
+type JournaledStore struct {
+	*MemoryStore
+	snapshotPath string
+	log          *wal.Log
+
+	// Serializes changes so the log has the same order as the store
+	mutex sync.Mutex
+	// What has been logged for each competition, used to derive join, leave and start records
+	logged map[string]*loggedCompetition
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type loggedCompetition struct {
+	players      map[string]bool
+	started      bool
+	disqualified map[string]bool
+	ended        bool
+}
+
+// OpenJournaledStore restores the state from the snapshot and log files and opens the log for appending.
+// A compactionInterval of zero disables periodic compaction.
+func OpenJournaledStore(snapshotPath, logPath string, syncInterval, compactionInterval time.Duration) (*JournaledStore, error) {
+	snapshot, err := ReadSnapshotFile(snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+
+	state := newReplayState(snapshot)
+	journal, err := wal.Open(logPath, syncInterval, state.apply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay log %s: %w", logPath, err)
+	}
+
+	store := &JournaledStore{
+		MemoryStore:  NewMemoryStore(),
+		snapshotPath: snapshotPath,
+		log:          journal,
+		logged:       map[string]*loggedCompetition{},
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	if err := state.snapshot().RestoreInto(store.MemoryStore); err != nil {
+		journal.Close()
+		return nil, err
+	}
+	for _, comp := range store.MemoryStore.ListCompetitions() {
+		store.logged[comp.Id()] = newLoggedCompetition(comp)
+	}
+
+	go store.compactionLoop(compactionInterval)
+	return store, nil
+}
+
+func (s *JournaledStore) PutPlayer(player *model.Player) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.MemoryStore.PutPlayer(player); err != nil {
+		return err
+	}
+	return s.append(JournalRecord{
+		Type:        RecordPlayer,
+		PlayerId:    player.Id(),
+		Level:       player.Level(),
+		CountryCode: player.CountryCode(),
+		Banned:      player.Banned(),
+	})
+}
+
+func (s *JournaledStore) DeletePlayer(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.MemoryStore.DeletePlayer(id); err != nil {
+		return err
+	}
+	return s.append(JournalRecord{Type: RecordDeletePlayer, PlayerId: id})
+}
+
+func (s *JournaledStore) PutCompetition(comp model.ICompetition) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.MemoryStore.PutCompetition(comp); err != nil {
+		return err
+	}
+
+	logged, found := s.logged[comp.Id()]
+	if !found {
+		logged = &loggedCompetition{players: map[string]bool{}, disqualified: map[string]bool{}}
+		s.logged[comp.Id()] = logged
+		if err := s.append(JournalRecord{Type: RecordCreate, CompetitionId: comp.Id(), Level: comp.InitialLevel(), TieBreaker: comp.TieBreaker(), ScoringMode: comp.ScoringMode()}); err != nil {
+			return err
+		}
+	}
+
+	state := comp.State()
+	players := make(map[string]model.CompetingPlayerState, len(state.Players))
+	for _, player := range state.Players {
+		players[player.Player.Id()] = player
+	}
+	for playerId, player := range players {
+		if !logged.players[playerId] {
+			if err := s.append(JournalRecord{
+				Type:          RecordJoin,
+				CompetitionId: comp.Id(),
+				PlayerId:      playerId,
+				Level:         player.Level,
+				CountryCode:   player.CountryCode,
+			}); err != nil {
+				return err
+			}
+			logged.players[playerId] = true
+		}
+	}
+	for playerId := range logged.players {
+		if _, found := players[playerId]; !found {
+			if err := s.append(JournalRecord{Type: RecordLeave, CompetitionId: comp.Id(), PlayerId: playerId}); err != nil {
+				return err
+			}
+			delete(logged.players, playerId)
+		}
+	}
+	if !logged.started && !state.StartedAt.IsZero() {
+		// The tie-breaker and scoring mode can change until the competition starts
+		if err := s.append(JournalRecord{
+			Type:          RecordStart,
+			CompetitionId: comp.Id(),
+			TieBreaker:    state.TieBreaker,
+			ScoringMode:   state.ScoringMode,
+			StartedAt:     state.StartedAt,
+			EndsAt:        state.EndsAt,
+		}); err != nil {
+			return err
+		}
+		logged.started = true
+	}
+	for playerId, player := range players {
+		if player.Disqualified && !logged.disqualified[playerId] {
+			if err := s.append(JournalRecord{Type: RecordDisqualify, CompetitionId: comp.Id(), PlayerId: playerId}); err != nil {
+				return err
+			}
+			logged.disqualified[playerId] = true
+		}
+	}
+	if !logged.ended && state.Ended {
+		if err := s.append(JournalRecord{Type: RecordEnd, CompetitionId: comp.Id()}); err != nil {
+			return err
+		}
+		logged.ended = true
+	}
+	return nil
+}
+
+func (s *JournaledStore) DeleteCompetition(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.MemoryStore.DeleteCompetition(id); err != nil {
+		return err
+	}
+	delete(s.logged, id)
+	return s.append(JournalRecord{Type: RecordDelete, CompetitionId: id})
+}
+
+func (s *JournaledStore) PutScore(competitionId string, playerId string, change model.ScoreChange) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.MemoryStore.PutScore(competitionId, playerId, change); err != nil {
+		return err
+	}
+	// The number of submissions and when the score last changed are recomputed from the history on restore
+	return s.append(JournalRecord{
+		Type:          RecordScore,
+		Seq:           change.Seq,
+		CompetitionId: competitionId,
+		PlayerId:      playerId,
+		Score:         change.Score,
+		SubmissionId:  change.SubmissionId,
+		VoidedSeq:     change.VoidedSeq,
+		SubmittedAt:   change.At,
+		Points:        change.Points,
+		Source:        change.Source,
+		Reason:        change.Reason,
+	})
+}
+
+func (s *JournaledStore) PutResults(competitionId string, results []ResultRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.MemoryStore.PutResults(competitionId, results); err != nil {
+		return err
+	}
+	return s.append(JournalRecord{Type: RecordResults, CompetitionId: competitionId, Results: results})
+}
+
+func (s *JournaledStore) PutAudit(audit AuditRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.MemoryStore.PutAudit(audit); err != nil {
+		return err
+	}
+	return s.append(JournalRecord{Type: RecordAudit, PlayerId: audit.PlayerId, Audit: &audit})
+}
+
+func (s *JournaledStore) PutWebhook(webhook WebhookRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.MemoryStore.PutWebhook(webhook); err != nil {
+		return err
+	}
+	return s.append(JournalRecord{Type: RecordWebhook, Webhook: &webhook})
+}
+
+func (s *JournaledStore) DeleteWebhook(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.MemoryStore.DeleteWebhook(id); err != nil {
+		return err
+	}
+	return s.append(JournalRecord{Type: RecordDeleteWebhook, Webhook: &WebhookRecord{Id: id}})
+}
+
+func (s *JournaledStore) PutDeadLetter(deadLetter DeadLetterRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.MemoryStore.PutDeadLetter(deadLetter); err != nil {
+		return err
+	}
+	return s.append(JournalRecord{Type: RecordDeadLetter, DeadLetter: &deadLetter})
+}
+
+func (s *JournaledStore) DeleteDeadLetter(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.MemoryStore.DeleteDeadLetter(id); err != nil {
+		return err
+	}
+	return s.append(JournalRecord{Type: RecordDeleteDeadLetter, DeadLetter: &DeadLetterRecord{Id: id}})
+}
+
+// Compact writes the current state to the snapshot file and truncates the log
+func (s *JournaledStore) Compact() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := WriteSnapshotFile(s.snapshotPath, TakeSnapshot(s.MemoryStore)); err != nil {
+		return err
+	}
+	return s.log.Truncate()
+}
+
+// Close stops the periodic compaction, compacts the log a last time and closes it
+func (s *JournaledStore) Close() error {
+	close(s.stop)
+	<-s.done
+	if err := s.Compact(); err != nil {
+		log.Printf("Failed to compact log on close: %v", err)
+	}
+	return s.log.Close()
+}
+
+func (s *JournaledStore) append(record JournalRecord) error {
+	entry, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.log.Append(entry)
+}
+
+func (s *JournaledStore) compactionLoop(interval time.Duration) {
+	defer close(s.done)
+	if interval <= 0 {
+		<-s.stop
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Compact(); err != nil {
+				log.Printf("Failed to compact log: %v", err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func newLoggedCompetition(comp model.ICompetition) *loggedCompetition {
+	state := comp.State()
+	logged := &loggedCompetition{
+		players:      make(map[string]bool, len(state.Players)),
+		started:      !state.StartedAt.IsZero(),
+		disqualified: map[string]bool{},
+		ended:        state.Ended,
+	}
+	for _, player := range state.Players {
+		logged.players[player.Player.Id()] = true
+		if player.Disqualified {
+			logged.disqualified[player.Player.Id()] = true
+		}
+	}
+	return logged
+}
+
+// replayState applies journal records on top of a snapshot
+type replayState struct {
+	players      []PlayerRecord
+	playerIndex  map[string]int
+	competitions []*CompetitionRecord
+	compIndex    map[string]*CompetitionRecord
+	results      []ResultRecord
+	// Competitions whose results are recorded
+	resultsRecorded map[string]bool
+	audit           []AuditRecord
+	webhooks        []WebhookRecord
+	deadLetters     []DeadLetterRecord
+}
+
+func newReplayState(snapshot *Snapshot) *replayState {
+	state := &replayState{
+		playerIndex:     make(map[string]int, len(snapshot.Players)),
+		compIndex:       make(map[string]*CompetitionRecord, len(snapshot.Competitions)),
+		results:         snapshot.Results,
+		resultsRecorded: map[string]bool{},
+		audit:           snapshot.Audit,
+		webhooks:        snapshot.Webhooks,
+		deadLetters:     snapshot.DeadLetters,
+	}
+	for _, result := range snapshot.Results {
+		state.resultsRecorded[result.CompetitionId] = true
+	}
+	for _, player := range snapshot.Players {
+		state.putPlayer(player)
+	}
+	for _, comp := range snapshot.Competitions {
+		record := comp
+		state.competitions = append(state.competitions, &record)
+		state.compIndex[comp.Id] = &record
+	}
+	return state
+}
+
+func (s *replayState) putPlayer(player PlayerRecord) {
+	if index, found := s.playerIndex[player.Id]; found {
+		s.players[index] = player
+	} else {
+		s.playerIndex[player.Id] = len(s.players)
+		s.players = append(s.players, player)
+	}
+}
+
+func (s *replayState) deletePlayer(id string) {
+	index, found := s.playerIndex[id]
+	if !found {
+		return
+	}
+	s.players = slices.Delete(s.players, index, index+1)
+	delete(s.playerIndex, id)
+	for i := index; i < len(s.players); i++ {
+		s.playerIndex[s.players[i].Id] = i
+	}
+}
+
+func (s *replayState) apply(entry []byte) error {
+	var record JournalRecord
+	if err := json.Unmarshal(entry, &record); err != nil {
+		return fmt.Errorf("failed to decode log record: %w", err)
+	}
+
+	if record.Type == RecordPlayer {
+		s.putPlayer(PlayerRecord{Id: record.PlayerId, CountryCode: record.CountryCode, Level: record.Level, Banned: record.Banned})
+		return nil
+	}
+	if record.Type == RecordDeletePlayer {
+		s.deletePlayer(record.PlayerId)
+		return nil
+	}
+	if record.Type == RecordResults {
+		// Results are kept after the competition is deleted
+		if !s.resultsRecorded[record.CompetitionId] {
+			s.resultsRecorded[record.CompetitionId] = true
+			s.results = append(s.results, record.Results...)
+		}
+		return nil
+	}
+	if record.Type == RecordAudit {
+		// The audit log is kept after the competition is deleted
+		if record.Audit != nil && !slices.ContainsFunc(s.audit, record.Audit.sameAs) {
+			s.audit = append(s.audit, *record.Audit)
+		}
+		return nil
+	}
+	if record.Type == RecordWebhook || record.Type == RecordDeleteWebhook {
+		if record.Webhook != nil {
+			s.webhooks = slices.DeleteFunc(s.webhooks, func(w WebhookRecord) bool { return w.Id == record.Webhook.Id })
+			if record.Type == RecordWebhook {
+				s.webhooks = append(s.webhooks, *record.Webhook)
+			}
+		}
+		return nil
+	}
+	if record.Type == RecordDeadLetter || record.Type == RecordDeleteDeadLetter {
+		if record.DeadLetter != nil {
+			s.deadLetters = slices.DeleteFunc(s.deadLetters, func(d DeadLetterRecord) bool { return d.Id == record.DeadLetter.Id })
+			if record.Type == RecordDeadLetter {
+				s.deadLetters = append(s.deadLetters, *record.DeadLetter)
+			}
+		}
+		return nil
+	}
+	if record.Type == RecordCreate {
+		if _, found := s.compIndex[record.CompetitionId]; !found {
+			comp := &CompetitionRecord{Id: record.CompetitionId, InitialLevel: record.Level, TieBreaker: record.TieBreaker, ScoringMode: record.ScoringMode, Scores: map[string]int{}}
+			s.competitions = append(s.competitions, comp)
+			s.compIndex[comp.Id] = comp
+		}
+		return nil
+	}
+
+	comp, found := s.compIndex[record.CompetitionId]
+	if !found {
+		// The competition was deleted later, or removed by a newer snapshot
+		return nil
+	}
+	switch record.Type {
+	case RecordJoin:
+		if _, joined := comp.Scores[record.PlayerId]; !joined {
+			comp.Scores[record.PlayerId] = 0
+		}
+		comp.setPlayerInfo(record.PlayerId, record.Level, record.CountryCode)
+	case RecordLeave:
+		delete(comp.Scores, record.PlayerId)
+		delete(comp.Levels, record.PlayerId)
+		delete(comp.Countries, record.PlayerId)
+	case RecordStart:
+		comp.StartedAt = record.StartedAt
+		comp.EndsAt = record.EndsAt
+		if record.TieBreaker != "" {
+			comp.TieBreaker = record.TieBreaker
+		}
+		if record.ScoringMode != "" {
+			comp.ScoringMode = record.ScoringMode
+		}
+	case RecordScore:
+		change := ScoreChangeRecord{
+			Seq:          record.Seq,
+			At:           record.SubmittedAt,
+			Points:       record.Points,
+			Score:        record.Score,
+			Source:       record.Source,
+			SubmissionId: record.SubmissionId,
+			Reason:       record.Reason,
+			VoidedSeq:    record.VoidedSeq,
+		}
+		if slices.ContainsFunc(comp.History[record.PlayerId], change.sameAs) {
+			return nil
+		}
+		if record.Source == model.SourceVoid {
+			comp.voidScoreChange(record.PlayerId, record.VoidedSeq)
+		}
+		comp.insertScoreChange(record.PlayerId, change)
+		if record.SubmissionId != "" && record.Source != model.SourceVoid && !comp.applied(record.PlayerId, record.SubmissionId) {
+			comp.AppliedSubmissions = append(comp.AppliedSubmissions, SubmissionRecord{
+				PlayerId:     record.PlayerId,
+				SubmissionId: record.SubmissionId,
+				AppliedAt:    record.SubmittedAt,
+			})
+		}
+	case RecordDisqualify:
+		if !slices.Contains(comp.Disqualified, record.PlayerId) {
+			comp.Disqualified = append(comp.Disqualified, record.PlayerId)
+		}
+	case RecordEnd:
+		comp.Ended = true
+	case RecordDelete:
+		delete(s.compIndex, record.CompetitionId)
+		s.competitions = slices.DeleteFunc(s.competitions, func(c *CompetitionRecord) bool {
+			return c.Id == record.CompetitionId
+		})
+	default:
+		return fmt.Errorf("unknown log record type %q", record.Type)
+	}
+	return nil
+}
+
+func (s *replayState) snapshot() *Snapshot {
+	snapshot := &Snapshot{
+		Players:      s.players,
+		Competitions: make([]CompetitionRecord, 0, len(s.competitions)),
+		Results:      s.results,
+		Audit:        s.audit,
+		Webhooks:     s.webhooks,
+		DeadLetters:  s.deadLetters,
+	}
+	for _, comp := range s.competitions {
+		snapshot.Competitions = append(snapshot.Competitions, *comp)
+	}
+	return snapshot
+}
@@ -0,0 +1,518 @@
+package storage
+
+import (
+	"leaderboard/internal/config"
+	"leaderboard/internal/model"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openJournaledStoreForTest(t *testing.T, dir string) *JournaledStore {
+	store, err := OpenJournaledStore(filepath.Join(dir, "snapshot.json"), filepath.Join(dir, "log.wal"), time.Hour, 0)
+	if err != nil {
+		t.Fatalf("OpenJournaledStore() returned error %v", err)
+	}
+	return store
+}
+
+// crash closes the log without the final compaction done by Close
+func crash(store *JournaledStore) {
+	close(store.stop)
+	<-store.done
+	store.log.Close()
+}
+
+func startCompetitionForTest(t *testing.T, store Store, level int, playerIds ...string) model.ICompetition {
+	comp := model.NewCompetition(level)
+	if err := store.PutCompetition(comp); err != nil {
+		t.Fatalf("PutCompetition() returned error %v", err)
+	}
+	for _, playerId := range playerIds {
+		player, _ := store.GetPlayer(playerId)
+		if err := comp.AddPlayer(player); err != nil {
+			t.Fatalf("AddPlayer() returned error %v", err)
+		}
+		_ = store.PutCompetition(comp)
+	}
+	if err := comp.Start(); err != nil {
+		t.Fatalf("Start() returned error %v", err)
+	}
+	_ = store.PutCompetition(comp)
+	return comp
+}
+
+func addScoreForTest(store Store, comp model.ICompetition, playerId string, points int) {
+	submitScoreForTest(store, comp, model.ScoreSubmission{PlayerId: playerId, Points: points, Source: model.SourceApi})
+}
+
+func submitScoreForTest(store Store, comp model.ICompetition, submission model.ScoreSubmission) {
+	result := comp.SubmitScores([]model.ScoreSubmission{submission})[0]
+	if result.Applied {
+		_ = store.PutScore(comp.Id(), submission.PlayerId, result.Change)
+	}
+}
+
+func TestJournaledStore_ReplayAfterCrash(t *testing.T) {
+	dir := t.TempDir()
+	store := openJournaledStoreForTest(t, dir)
+	for _, player := range []*model.Player{model.NewPlayer("a", 1, "US"), model.NewPlayer("b", 1, "GB"), model.NewPlayer("c", 2, "MX")} {
+		_ = store.PutPlayer(player)
+	}
+	comp := startCompetitionForTest(t, store, 1, "a", "b")
+	addScoreForTest(store, comp, "a", 10)
+	addScoreForTest(store, comp, "b", 25)
+	addScoreForTest(store, comp, "a", 20)
+
+	waiting := model.NewCompetition(2)
+	c, _ := store.GetPlayer("c")
+	_ = waiting.AddPlayer(c)
+	_ = store.PutCompetition(waiting)
+	crash(store)
+
+	restored := openJournaledStoreForTest(t, dir)
+	defer restored.Close()
+
+	comps := restored.ListCompetitions()
+	if len(comps) != 2 || comps[0].Id() != comp.Id() || comps[1].Id() != waiting.Id() {
+		t.Fatalf("expected competitions %s and %s, got %v", comp.Id(), waiting.Id(), comps)
+	}
+	if !comps[0].StartedAt().Equal(comp.StartedAt()) || !comps[0].EndsAt().Equal(comp.EndsAt()) {
+		t.Errorf("expected start and end times to be restored")
+	}
+	leaderboard := comps[0].Leaderboard()
+	if len(leaderboard) != 2 ||
+		leaderboard[0].Player().Id() != "a" || leaderboard[0].Score() != 30 ||
+		leaderboard[1].Player().Id() != "b" || leaderboard[1].Score() != 25 {
+		t.Errorf("expected leaderboard a=30, b=25, got %v", leaderboard)
+	}
+	a, _ := restored.GetPlayer("a")
+	if a.Competition() == nil || a.Competition().Id() != comp.Id() {
+		t.Errorf("expected a to be linked to competition %s", comp.Id())
+	}
+	restoredC, _ := restored.GetPlayer("c")
+	if restoredC.Competition() == nil || restoredC.Competition().Id() != waiting.Id() {
+		t.Errorf("expected c to be linked to waiting competition %s", waiting.Id())
+	}
+}
+
+func TestJournaledStore_ReplayOnTopOfCompactedSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	store := openJournaledStoreForTest(t, dir)
+	for _, player := range []*model.Player{model.NewPlayer("a", 1, "US"), model.NewPlayer("b", 1, "GB")} {
+		_ = store.PutPlayer(player)
+	}
+	first := startCompetitionForTest(t, store, 1, "a", "b")
+	addScoreForTest(store, first, "a", 10)
+	if err := store.Compact(); err != nil {
+		t.Fatalf("Compact() returned error %v", err)
+	}
+
+	addScoreForTest(store, first, "b", 5)
+	_ = store.DeleteCompetition(first.Id())
+	second := startCompetitionForTest(t, store, 1, "a", "b")
+	addScoreForTest(store, second, "b", 7)
+	crash(store)
+
+	restored := openJournaledStoreForTest(t, dir)
+	defer restored.Close()
+
+	if _, found := restored.GetCompetition(first.Id()); found {
+		t.Errorf("expected deleted competition %s not to be restored", first.Id())
+	}
+	comp, found := restored.GetCompetition(second.Id())
+	if !found {
+		t.Fatalf("expected competition %s to be restored", second.Id())
+	}
+	if comp.PlayersMap()["b"].Score() != 7 || comp.PlayersMap()["a"].Score() != 0 {
+		t.Errorf("expected scores a=0, b=7, got a=%d, b=%d", comp.PlayersMap()["a"].Score(), comp.PlayersMap()["b"].Score())
+	}
+}
+
+func TestJournaledStore_CloseCompactsLog(t *testing.T) {
+	dir := t.TempDir()
+	store := openJournaledStoreForTest(t, dir)
+	_ = store.PutPlayer(model.NewPlayer("a", 1, "US"))
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() returned error %v", err)
+	}
+
+	snapshot, err := ReadSnapshotFile(filepath.Join(dir, "snapshot.json"))
+	if err != nil {
+		t.Fatalf("ReadSnapshotFile() returned error %v", err)
+	}
+	if len(snapshot.Players) != 1 || snapshot.Players[0].Id != "a" {
+		t.Errorf("expected snapshot to contain player a, got %v", snapshot.Players)
+	}
+}
+
+func TestJournaledStore_ResultsKeptAfterCompetitionDeleted(t *testing.T) {
+	dir := t.TempDir()
+	store := openJournaledStoreForTest(t, dir)
+	results := []ResultRecord{
+		{CompetitionId: "comp1", PlayerId: "a", Level: 1, CountryCode: "US", Rank: 1, Score: 30},
+		{CompetitionId: "comp1", PlayerId: "b", Level: 1, CountryCode: "GB", Rank: 2, Score: 25},
+	}
+	if err := store.PutResults("comp1", results); err != nil {
+		t.Fatalf("PutResults() returned error %v", err)
+	}
+	if err := store.PutResults("comp1", results); err != ErrResultsRecorded {
+		t.Errorf("expected ErrResultsRecorded, got %v", err)
+	}
+	if err := store.Compact(); err != nil {
+		t.Fatalf("Compact() returned error %v", err)
+	}
+	_ = store.PutResults("comp2", []ResultRecord{{CompetitionId: "comp2", PlayerId: "a", Level: 2, CountryCode: "US", Rank: 2, Score: 5}})
+	crash(store)
+
+	restored := openJournaledStoreForTest(t, dir)
+	defer restored.Close()
+
+	listed := restored.ListResults()
+	if len(listed) != 3 || listed[0] != results[0] || listed[1] != results[1] || listed[2].CompetitionId != "comp2" {
+		t.Errorf("expected the results of comp1 and comp2 in order, got %v", listed)
+	}
+	if err := restored.PutResults("comp2", nil); err != ErrResultsRecorded {
+		t.Errorf("expected ErrResultsRecorded after restore, got %v", err)
+	}
+}
+
+func TestJournaledStore_ReplayKeepsTieBreakerAndScoringMode(t *testing.T) {
+	dir := t.TempDir()
+	store := openJournaledStoreForTest(t, dir)
+	for _, player := range []*model.Player{model.NewPlayer("a", 1, "US"), model.NewPlayer("b", 1, "GB")} {
+		_ = store.PutPlayer(player)
+	}
+	comp := model.NewCompetition(1)
+	_ = comp.SetTieBreaker(model.TieBreakerFewestSubmissions)
+	_ = comp.SetScoringMode(model.ScoringPenalties)
+	_ = store.PutCompetition(comp)
+	for _, playerId := range []string{"a", "b"} {
+		player, _ := store.GetPlayer(playerId)
+		_ = comp.AddPlayer(player)
+	}
+	_ = comp.Start()
+	_ = store.PutCompetition(comp)
+	addScoreForTest(store, comp, "a", 10)
+	addScoreForTest(store, comp, "a", 10)
+	addScoreForTest(store, comp, "b", 20)
+	crash(store)
+
+	restored := openJournaledStoreForTest(t, dir)
+	restoredComp, _ := restored.GetCompetition(comp.Id())
+	if restoredComp.TieBreaker() != model.TieBreakerFewestSubmissions {
+		t.Errorf("expected the fewest_submissions tie-breaker, got %s", restoredComp.TieBreaker())
+	}
+	if restoredComp.ScoringMode() != model.ScoringPenalties {
+		t.Errorf("expected the penalties scoring mode, got %s", restoredComp.ScoringMode())
+	}
+	// b needed fewer submissions to reach the same score
+	leaderboard := restoredComp.Leaderboard()
+	if leaderboard[0].Player().Id() != "b" || leaderboard[1].Submissions() != 2 || leaderboard[1].ImprovedAt().IsZero() {
+		t.Errorf("expected b first and a with 2 submissions, got %v", leaderboard)
+	}
+
+	// The values are kept in the compacted snapshot
+	_ = restored.Close()
+	reopened := openJournaledStoreForTest(t, dir)
+	defer reopened.Close()
+	reopenedComp, _ := reopened.GetCompetition(comp.Id())
+	if reopenedComp.TieBreaker() != model.TieBreakerFewestSubmissions || reopenedComp.ScoringMode() != model.ScoringPenalties ||
+		reopenedComp.Leaderboard()[0].Player().Id() != "b" {
+		t.Errorf("expected the tie-breaker and scoring mode to be restored from the snapshot")
+	}
+}
+
+func TestJournaledStore_ReplayKeepsWindowPoints(t *testing.T) {
+	defer func() { config.MaxPointsPerWindow = 1000 }()
+	config.MaxPointsPerWindow = 50
+	dir := t.TempDir()
+	store := openJournaledStoreForTest(t, dir)
+	for _, player := range []*model.Player{model.NewPlayer("a", 1, "US"), model.NewPlayer("b", 1, "GB")} {
+		_ = store.PutPlayer(player)
+	}
+	comp := model.NewCompetition(1)
+	_ = comp.SetScoringMode(model.ScoringWindow)
+	_ = store.PutCompetition(comp)
+	for _, playerId := range []string{"a", "b"} {
+		player, _ := store.GetPlayer(playerId)
+		_ = comp.AddPlayer(player)
+	}
+	_ = comp.Start()
+	_ = store.PutCompetition(comp)
+	addScoreForTest(store, comp, "a", 40)
+	crash(store)
+
+	// The points of the current window are kept, so only 10 more points are added in it
+	restored := openJournaledStoreForTest(t, dir)
+	restoredComp, _ := restored.GetCompetition(comp.Id())
+	addScoreForTest(restored, restoredComp, "a", 30)
+	if score := restoredComp.PlayersMap()["a"].Score(); score != 50 {
+		t.Errorf("expected the window limit to be kept after a crash, got %d", score)
+	}
+
+	_ = restored.Close()
+	reopened := openJournaledStoreForTest(t, dir)
+	defer reopened.Close()
+	reopenedComp, _ := reopened.GetCompetition(comp.Id())
+	addScoreForTest(reopened, reopenedComp, "a", 30)
+	if score := reopenedComp.PlayersMap()["a"].Score(); score != 50 {
+		t.Errorf("expected the window limit to be kept after a compaction, got %d", score)
+	}
+}
+
+func TestJournaledStore_ReplayKeepsLevelAndCountryOfDeletedPlayers(t *testing.T) {
+	dir := t.TempDir()
+	store := openJournaledStoreForTest(t, dir)
+	for _, player := range []*model.Player{model.NewPlayer("a", 3, "GB"), model.NewPlayer("b", 3, "US")} {
+		_ = store.PutPlayer(player)
+	}
+	comp := startCompetitionForTest(t, store, 3, "a", "b")
+	addScoreForTest(store, comp, "a", 10)
+	_ = store.DeletePlayer("a")
+	crash(store)
+
+	restored := openJournaledStoreForTest(t, dir)
+	restoredComp, _ := restored.GetCompetition(comp.Id())
+	if player := restoredComp.PlayersMap()["a"].Player(); player.Level() != 3 || player.CountryCode() != "GB" {
+		t.Errorf("expected the deleted player to keep level 3 and GB after a crash, got %d and %q", player.Level(), player.CountryCode())
+	}
+
+	_ = restored.Close()
+	reopened := openJournaledStoreForTest(t, dir)
+	defer reopened.Close()
+	reopenedComp, _ := reopened.GetCompetition(comp.Id())
+	if player := reopenedComp.PlayersMap()["a"].Player(); player.Level() != 3 || player.CountryCode() != "GB" {
+		t.Errorf("expected the deleted player to keep level 3 and GB after a compaction, got %d and %q", player.Level(), player.CountryCode())
+	}
+}
+
+func TestJournaledStore_ReplayKeepsSubmissionIds(t *testing.T) {
+	dir := t.TempDir()
+	store := openJournaledStoreForTest(t, dir)
+	for _, player := range []*model.Player{model.NewPlayer("a", 1, "US"), model.NewPlayer("b", 1, "GB")} {
+		_ = store.PutPlayer(player)
+	}
+	comp := startCompetitionForTest(t, store, 1, "a", "b")
+	submitScoreForTest(store, comp, model.ScoreSubmission{PlayerId: "a", Points: 10, SubmissionId: "s1"})
+	crash(store)
+
+	restored := openJournaledStoreForTest(t, dir)
+	restoredComp, _ := restored.GetCompetition(comp.Id())
+	if applied, _ := restoredComp.SubmitScore("a", 10, "s1"); applied {
+		t.Errorf("expected the replayed submission not to be applied after a crash")
+	}
+
+	_ = restored.Close()
+	reopened := openJournaledStoreForTest(t, dir)
+	defer reopened.Close()
+	reopenedComp, _ := reopened.GetCompetition(comp.Id())
+	if applied, _ := reopenedComp.SubmitScore("a", 10, "s1"); applied || reopenedComp.PlayersMap()["a"].Score() != 10 {
+		t.Errorf("expected the replayed submission not to be applied after a compaction")
+	}
+}
+
+func TestJournaledStore_ReplayKeepsScoreHistory(t *testing.T) {
+	dir := t.TempDir()
+	store := openJournaledStoreForTest(t, dir)
+	for _, player := range []*model.Player{model.NewPlayer("a", 1, "US"), model.NewPlayer("b", 1, "GB")} {
+		_ = store.PutPlayer(player)
+	}
+	comp := startCompetitionForTest(t, store, 1, "a", "b")
+	addScoreForTest(store, comp, "a", 10)
+	submitScoreForTest(store, comp, model.ScoreSubmission{PlayerId: "a", Points: 5, SubmissionId: "s1", Source: model.SourceBatch})
+	expected, _ := comp.History("a")
+	crash(store)
+
+	restored := openJournaledStoreForTest(t, dir)
+	restoredComp, _ := restored.GetCompetition(comp.Id())
+	assertHistory(t, restoredComp, expected)
+
+	_ = restored.Close()
+	reopened := openJournaledStoreForTest(t, dir)
+	defer reopened.Close()
+	reopenedComp, _ := reopened.GetCompetition(comp.Id())
+	assertHistory(t, reopenedComp, expected)
+}
+
+func TestJournaledStore_ReplayOrdersScoresJournaledOutOfOrder(t *testing.T) {
+	dir := t.TempDir()
+	store := openJournaledStoreForTest(t, dir)
+	for _, player := range []*model.Player{model.NewPlayer("a", 1, "US"), model.NewPlayer("b", 1, "GB")} {
+		_ = store.PutPlayer(player)
+	}
+	comp := startCompetitionForTest(t, store, 1, "a", "b")
+	// Concurrent submissions can reach the log in a different order than they were applied
+	first := comp.SubmitScores([]model.ScoreSubmission{{PlayerId: "a", Points: 10, Source: model.SourceApi}})[0].Change
+	second := comp.SubmitScores([]model.ScoreSubmission{{PlayerId: "a", Points: 5, Source: model.SourceApi}})[0].Change
+	_ = store.PutScore(comp.Id(), "a", second)
+	_ = store.PutScore(comp.Id(), "a", first)
+	expected, _ := comp.History("a")
+	crash(store)
+
+	// Restored from the log, then from the snapshot compacted on close
+	for range 2 {
+		restored := openJournaledStoreForTest(t, dir)
+		restoredComp, _ := restored.GetCompetition(comp.Id())
+		assertHistory(t, restoredComp, expected)
+		if score := restoredComp.PlayersMap()["a"].Score(); score != 15 {
+			t.Errorf("expected a to have 15 points, got %d", score)
+		}
+		_ = restored.Close()
+	}
+}
+
+func TestJournaledStore_ReplaySkipsChangesAlreadyInSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	store := openJournaledStoreForTest(t, dir)
+	for _, player := range []*model.Player{model.NewPlayer("a", 1, "US"), model.NewPlayer("b", 1, "GB")} {
+		_ = store.PutPlayer(player)
+	}
+	comp := startCompetitionForTest(t, store, 1, "a", "b")
+	submitScoreForTest(store, comp, model.ScoreSubmission{PlayerId: "a", Points: 10, SubmissionId: "s1", Source: model.SourceApi})
+	change, _ := comp.AdjustScore("a", 3, "bonus")
+	_ = store.PutScore(comp.Id(), "a", change)
+	_ = store.PutAudit(AuditRecord{At: time.Now(), Action: "adjust", LeaderboardId: comp.Id(), PlayerId: "a", Points: 3, Reason: "bonus"})
+	expected, _ := comp.History("a")
+	// Crash after the snapshot was written but before the log was truncated
+	if err := WriteSnapshotFile(store.snapshotPath, TakeSnapshot(store.MemoryStore)); err != nil {
+		t.Fatalf("WriteSnapshotFile() returned error %v", err)
+	}
+	crash(store)
+
+	restored := openJournaledStoreForTest(t, dir)
+	defer restored.Close()
+	restoredComp, _ := restored.GetCompetition(comp.Id())
+	assertHistory(t, restoredComp, expected)
+	if score := restoredComp.PlayersMap()["a"].Score(); score != 13 {
+		t.Errorf("expected a to have 13 points, got %d", score)
+	}
+	if applied := restoredComp.AppliedSubmissions(); len(applied) != 1 {
+		t.Errorf("expected s1 to be applied once, got %+v", applied)
+	}
+	if audit := restored.ListAudit(); len(audit) != 1 {
+		t.Errorf("expected a single audit record, got %+v", audit)
+	}
+}
+
+func TestJournaledStore_ReplayKeepsModerations(t *testing.T) {
+	dir := t.TempDir()
+	store := openJournaledStoreForTest(t, dir)
+	for _, player := range []*model.Player{model.NewPlayer("a", 1, "US"), model.NewPlayer("b", 1, "GB"), model.NewPlayer("c", 1, "MX")} {
+		_ = store.PutPlayer(player)
+	}
+	comp := startCompetitionForTest(t, store, 1, "a", "b", "c")
+	submitScoreForTest(store, comp, model.ScoreSubmission{PlayerId: "a", Points: 10, SubmissionId: "s1", Source: model.SourceApi})
+	submitScoreForTest(store, comp, model.ScoreSubmission{PlayerId: "a", Points: 5, SubmissionId: "s2", Source: model.SourceApi})
+	change, _ := comp.VoidSubmission("a", "s1", "cheat")
+	_ = store.PutScore(comp.Id(), "a", change)
+	change, _ = comp.AdjustScore("a", 3, "bonus")
+	_ = store.PutScore(comp.Id(), "a", change)
+	addScoreForTest(store, comp, "b", 20)
+	_ = comp.Disqualify("b")
+	_ = store.PutCompetition(comp)
+	banned, _ := store.GetPlayer("c")
+	banned.SetBanned(true)
+	_ = store.PutPlayer(banned)
+	_ = store.PutAudit(AuditRecord{At: time.Now(), Action: "ban", PlayerId: "c", Reason: "abuse"})
+	expected, _ := comp.History("a")
+	crash(store)
+
+	// Restored from the log, then from the snapshot compacted on close
+	for range 2 {
+		restored := openJournaledStoreForTest(t, dir)
+		restoredComp, _ := restored.GetCompetition(comp.Id())
+		assertHistory(t, restoredComp, expected)
+		history, _ := restoredComp.History("a")
+		if !history[0].Voided || history[2].Reason != "cheat" || restoredComp.PlayersMap()["a"].Score() != 8 {
+			t.Errorf("expected s1 to stay voided and a to have 8 points, got %+v", history)
+		}
+		if !restoredComp.PlayersMap()["b"].Disqualified() || len(restoredComp.Leaderboard()) != 2 {
+			t.Errorf("expected b to stay disqualified, got leaderboard %v", restoredComp.Leaderboard())
+		}
+		if player, _ := restored.GetPlayer("c"); !player.Banned() {
+			t.Errorf("expected c to stay banned")
+		}
+		if audit := restored.ListAudit(); len(audit) != 1 || audit[0].PlayerId != "c" || audit[0].Reason != "abuse" {
+			t.Errorf("expected the audit log to be restored, got %+v", audit)
+		}
+		_ = restored.Close()
+	}
+}
+
+func TestJournaledStore_ReplayKeepsWebhooks(t *testing.T) {
+	dir := t.TempDir()
+	store := openJournaledStoreForTest(t, dir)
+	_ = store.PutWebhook(WebhookRecord{Id: "w1", Url: "http://rewards.local/hook", Secret: "s1"})
+	_ = store.PutWebhook(WebhookRecord{Id: "w2", Url: "http://other.local/hook", Secret: "s2"})
+	_ = store.PutWebhook(WebhookRecord{Id: "w1", Url: "http://rewards.local/v2", Secret: "s3"})
+	_ = store.DeleteWebhook("w2")
+	crash(store)
+
+	// Restored from the log, then from the snapshot compacted on close
+	for range 2 {
+		restored := openJournaledStoreForTest(t, dir)
+		if webhooks := restored.ListWebhooks(); len(webhooks) != 1 || webhooks[0].Url != "http://rewards.local/v2" || webhooks[0].Secret != "s3" {
+			t.Errorf("expected the updated w1 to be the only webhook, got %+v", webhooks)
+		}
+		_ = restored.Close()
+	}
+}
+
+func TestJournaledStore_ReplayKeepsDeadLetters(t *testing.T) {
+	dir := t.TempDir()
+	store := openJournaledStoreForTest(t, dir)
+	_ = store.PutDeadLetter(DeadLetterRecord{Id: "d1", WebhookId: "w1", Attempts: 6, Payload: []byte(`{"event":"competition_ended"}`)})
+	_ = store.PutDeadLetter(DeadLetterRecord{Id: "d2", WebhookId: "w1", Attempts: 6, Payload: []byte(`{}`)})
+	_ = store.DeleteDeadLetter("d1")
+	crash(store)
+
+	// Restored from the log, then from the snapshot compacted on close
+	for range 2 {
+		restored := openJournaledStoreForTest(t, dir)
+		if letters := restored.ListDeadLetters(); len(letters) != 1 || letters[0].Id != "d2" || string(letters[0].Payload) != `{}` {
+			t.Errorf("expected d2 to be the only dead letter, got %+v", letters)
+		}
+		_ = restored.Close()
+	}
+}
+
+func TestJournaledStore_ReplayKeepsEndedCompetitions(t *testing.T) {
+	dir := t.TempDir()
+	store := openJournaledStoreForTest(t, dir)
+	for _, player := range []*model.Player{model.NewPlayer("a", 1, "US"), model.NewPlayer("b", 1, "GB")} {
+		_ = store.PutPlayer(player)
+	}
+	comp := startCompetitionForTest(t, store, 1, "a", "b")
+	addScoreForTest(store, comp, "a", 10)
+	_ = comp.End()
+	_ = store.PutCompetition(comp)
+	crash(store)
+
+	// Restored from the log, then from the snapshot compacted on close
+	for range 2 {
+		restored := openJournaledStoreForTest(t, dir)
+		restoredComp, _ := restored.GetCompetition(comp.Id())
+		if !restoredComp.Ended() || restoredComp.PlayersMap()["a"].Score() != 10 {
+			t.Errorf("expected the competition to stay ended with the score of a")
+		}
+		if player, _ := restored.GetPlayer("a"); player.Competition() != nil {
+			t.Errorf("expected a not to be linked to the ended competition")
+		}
+		_ = restored.Close()
+	}
+}
+
+func assertHistory(t *testing.T, comp model.ICompetition, expected []model.ScoreChange) {
+	t.Helper()
+	history, _ := comp.History("a")
+	if len(history) != len(expected) {
+		t.Fatalf("expected %d changes, got %d", len(expected), len(history))
+	}
+	for i, change := range history {
+		if !change.At.Equal(expected[i].At) || change.Points != expected[i].Points || change.Delta != expected[i].Delta ||
+			change.Score != expected[i].Score || change.Source != expected[i].Source || change.SubmissionId != expected[i].SubmissionId {
+			t.Errorf("expected change %+v, got %+v", expected[i], change)
+		}
+	}
+}
@@ -3,15 +3,192 @@ package storage
 import (
 	"encoding/json"
 	"leaderboard/internal/model"
+	"slices"
+	"sync"
 )
 
-// Holds all Players and Competitions in memory
-var (
-	Players      = map[string]*model.Player{}
-	Competitions = map[string]model.ICompetition{}
-)
+// MemoryStore holds all Players and Competitions in memory
+type MemoryStore struct {
+	mutex            sync.RWMutex
+	players          map[string]*model.Player
+	competitions     map[string]model.ICompetition
+	competitionOrder []string
+	results          []ResultRecord
+	// Competitions whose results are recorded
+	resultsRecorded map[string]bool
+	audit           []AuditRecord
+	webhooks        []WebhookRecord
+	deadLetters     []DeadLetterRecord
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		players:         map[string]*model.Player{},
+		competitions:    map[string]model.ICompetition{},
+		resultsRecorded: map[string]bool{},
+	}
+}
+
+func (s *MemoryStore) GetPlayer(id string) (*model.Player, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	player, found := s.players[id]
+	return player, found
+}
+
+func (s *MemoryStore) PutPlayer(player *model.Player) error {
+	if player == nil {
+		return ErrPlayerNil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.players[player.Id()] = player
+	return nil
+}
+
+func (s *MemoryStore) DeletePlayer(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.players, id)
+	return nil
+}
+
+func (s *MemoryStore) ListPlayers() []*model.Player {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	players := make([]*model.Player, 0, len(s.players))
+	for _, player := range s.players {
+		players = append(players, player)
+	}
+	return players
+}
+
+func (s *MemoryStore) GetCompetition(id string) (model.ICompetition, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	comp, found := s.competitions[id]
+	return comp, found
+}
+
+func (s *MemoryStore) PutCompetition(comp model.ICompetition) error {
+	if comp == nil {
+		return ErrCompetitionNil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, found := s.competitions[comp.Id()]; !found {
+		s.competitionOrder = append(s.competitionOrder, comp.Id())
+	}
+	s.competitions[comp.Id()] = comp
+	return nil
+}
+
+func (s *MemoryStore) DeleteCompetition(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, found := s.competitions[id]; !found {
+		return nil
+	}
+	delete(s.competitions, id)
+	s.competitionOrder = slices.DeleteFunc(s.competitionOrder, func(compId string) bool {
+		return compId == id
+	})
+	return nil
+}
+
+func (s *MemoryStore) ListCompetitions() []model.ICompetition {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	comps := make([]model.ICompetition, 0, len(s.competitionOrder))
+	for _, id := range s.competitionOrder {
+		comps = append(comps, s.competitions[id])
+	}
+	return comps
+}
+
+// PutScore is a no-op for the memory store because competitions are held as live objects
+func (s *MemoryStore) PutScore(competitionId string, playerId string, change model.ScoreChange) error {
+	return nil
+}
+
+func (s *MemoryStore) PutResults(competitionId string, results []ResultRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.resultsRecorded[competitionId] {
+		return ErrResultsRecorded
+	}
+	s.resultsRecorded[competitionId] = true
+	s.results = append(s.results, results...)
+	return nil
+}
+
+func (s *MemoryStore) ListResults() []ResultRecord {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return slices.Clone(s.results)
+}
+
+func (s *MemoryStore) PutAudit(audit AuditRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.audit = append(s.audit, audit)
+	return nil
+}
+
+func (s *MemoryStore) ListAudit() []AuditRecord {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return slices.Clone(s.audit)
+}
+
+func (s *MemoryStore) PutWebhook(webhook WebhookRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if index := slices.IndexFunc(s.webhooks, func(w WebhookRecord) bool { return w.Id == webhook.Id }); index >= 0 {
+		s.webhooks[index] = webhook
+	} else {
+		s.webhooks = append(s.webhooks, webhook)
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteWebhook(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.webhooks = slices.DeleteFunc(s.webhooks, func(w WebhookRecord) bool { return w.Id == id })
+	return nil
+}
+
+func (s *MemoryStore) ListWebhooks() []WebhookRecord {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return slices.Clone(s.webhooks)
+}
+
+func (s *MemoryStore) PutDeadLetter(deadLetter DeadLetterRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if index := slices.IndexFunc(s.deadLetters, func(d DeadLetterRecord) bool { return d.Id == deadLetter.Id }); index >= 0 {
+		s.deadLetters[index] = deadLetter
+	} else {
+		s.deadLetters = append(s.deadLetters, deadLetter)
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteDeadLetter(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.deadLetters = slices.DeleteFunc(s.deadLetters, func(d DeadLetterRecord) bool { return d.Id == id })
+	return nil
+}
+
+func (s *MemoryStore) ListDeadLetters() []DeadLetterRecord {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return slices.Clone(s.deadLetters)
+}
 
-// TODO: Define an interface
 func LoadDummyPlayers() {
 	var dummyPlayers []NewPlayer
 	err := json.Unmarshal([]byte(dummyPlayersJson), &dummyPlayers)
@@ -24,7 +201,9 @@ func LoadDummyPlayers() {
 func AddPlayers(players []NewPlayer) {
 	for _, dummy := range players {
 		player := model.NewPlayer(dummy.Id, dummy.Level, dummy.CountryCode)
-		Players[player.Id()] = player
+		if err := Current.PutPlayer(player); err != nil {
+			panic("Failed to add player: " + err.Error())
+		}
 	}
 }
 
@@ -0,0 +1,389 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"leaderboard/internal/model"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Snapshot is a serializable copy of the players and competitions held by a store
+type Snapshot struct {
+	Players      []PlayerRecord      `json:"players"`
+	Competitions []CompetitionRecord `json:"competitions"`
+	Results      []ResultRecord      `json:"results,omitempty"`
+	Audit        []AuditRecord       `json:"audit,omitempty"`
+	Webhooks     []WebhookRecord     `json:"webhooks,omitempty"`
+	DeadLetters  []DeadLetterRecord  `json:"dead_letters,omitempty"`
+}
+
+type PlayerRecord struct {
+	Id          string `json:"id"`
+	CountryCode string `json:"country_code"`
+	Level       int    `json:"level"`
+	Banned      bool   `json:"banned,omitempty"`
+}
+
+type CompetitionRecord struct {
+	Id           string         `json:"id"`
+	InitialLevel int            `json:"initial_level"`
+	TieBreaker   string         `json:"tie_breaker,omitempty"`
+	ScoringMode  string         `json:"scoring_mode,omitempty"`
+	StartedAt    time.Time      `json:"started_at"`
+	EndsAt       time.Time      `json:"ends_at"`
+	Scores       map[string]int `json:"scores"`
+	// Level and country of each player, used to restore the players deleted after they joined
+	Levels    map[string]int    `json:"levels,omitempty"`
+	Countries map[string]string `json:"countries,omitempty"`
+	// Submissions with a client-supplied ID that are remembered to ignore retries
+	AppliedSubmissions []SubmissionRecord `json:"applied_submissions,omitempty"`
+	// Accepted submissions of each player
+	History map[string][]ScoreChangeRecord `json:"history,omitempty"`
+	// Players removed from the ranking by a moderator
+	Disqualified []string `json:"disqualified,omitempty"`
+	// Set once the competition has been finalized, its scores are frozen
+	Ended bool `json:"ended,omitempty"`
+}
+
+type ScoreChangeRecord struct {
+	Seq          int       `json:"seq,omitempty"`
+	At           time.Time `json:"at"`
+	Points       int       `json:"points"`
+	Delta        int       `json:"delta"`
+	Score        int       `json:"score"`
+	Source       string    `json:"source,omitempty"`
+	SubmissionId string    `json:"submission_id,omitempty"`
+	Reason       string    `json:"reason,omitempty"`
+	Voided       bool      `json:"voided,omitempty"`
+	VoidedSeq    int       `json:"voided_seq,omitempty"`
+}
+
+type SubmissionRecord struct {
+	PlayerId     string    `json:"player_id"`
+	SubmissionId string    `json:"submission_id"`
+	AppliedAt    time.Time `json:"applied_at"`
+}
+
+// ResultRecord is the final standing of a player in an ended competition.
+// The level and country code are those of the player when the competition ended.
+type ResultRecord struct {
+	CompetitionId string    `json:"competition_id"`
+	PlayerId      string    `json:"player_id"`
+	Level         int       `json:"level"`
+	CountryCode   string    `json:"country_code"`
+	Rank          int       `json:"rank"`
+	Score         int       `json:"score"`
+	EndsAt        time.Time `json:"ends_at"`
+}
+
+// AuditRecord is a moderation of a player or of their score, kept in the audit log
+type AuditRecord struct {
+	At            time.Time `json:"at"`
+	Action        string    `json:"action"`
+	LeaderboardId string    `json:"leaderboard_id,omitempty"`
+	PlayerId      string    `json:"player_id"`
+	SubmissionId  string    `json:"submission_id,omitempty"`
+	Seq           int       `json:"seq,omitempty"` // Sequence number of the voided submission in the history of the player
+	Points        int       `json:"points,omitempty"`
+	Reason        string    `json:"reason"`
+}
+
+// WebhookRecord is an endpoint notified of the ended competitions. The secret signs the payloads.
+type WebhookRecord struct {
+	Id        string    `json:"id"`
+	Url       string    `json:"url"`
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DeadLetterRecord is a delivery to a webhook whose attempts all failed, kept to be replayed
+type DeadLetterRecord struct {
+	Id            string          `json:"id"`
+	WebhookId     string          `json:"webhook_id"`
+	Url           string          `json:"url"`
+	Event         string          `json:"event"`
+	Attempts      int             `json:"attempts"`
+	LastAttemptAt time.Time       `json:"last_attempt_at"`
+	LastError     string          `json:"last_error"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// TakeSnapshot copies the current state of the store
+func TakeSnapshot(store Store) *Snapshot {
+	players := store.ListPlayers()
+	comps := store.ListCompetitions()
+
+	snapshot := &Snapshot{
+		Players:      make([]PlayerRecord, 0, len(players)),
+		Competitions: make([]CompetitionRecord, 0, len(comps)),
+		Results:      store.ListResults(),
+		Audit:        store.ListAudit(),
+		Webhooks:     store.ListWebhooks(),
+		DeadLetters:  store.ListDeadLetters(),
+	}
+	for _, player := range players {
+		snapshot.Players = append(snapshot.Players, PlayerRecord{
+			Id:          player.Id(),
+			CountryCode: player.CountryCode(),
+			Level:       player.Level(),
+			Banned:      player.Banned(),
+		})
+	}
+	for _, comp := range comps {
+		snapshot.Competitions = append(snapshot.Competitions, newCompetitionRecord(comp))
+	}
+	return snapshot
+}
+
+func newCompetitionRecord(comp model.ICompetition) CompetitionRecord {
+	state := comp.State()
+	record := CompetitionRecord{
+		Id:           comp.Id(),
+		InitialLevel: comp.InitialLevel(),
+		TieBreaker:   state.TieBreaker,
+		ScoringMode:  state.ScoringMode,
+		StartedAt:    state.StartedAt,
+		EndsAt:       state.EndsAt,
+		Scores:       make(map[string]int, len(state.Players)),
+		Levels:       make(map[string]int, len(state.Players)),
+		Countries:    make(map[string]string, len(state.Players)),
+		Ended:        state.Ended,
+	}
+	for _, player := range state.Players {
+		playerId := player.Player.Id()
+		record.Scores[playerId] = player.Score
+		record.setPlayerInfo(playerId, player.Level, player.CountryCode)
+		if player.Disqualified {
+			record.Disqualified = append(record.Disqualified, playerId)
+		}
+		for _, change := range player.History {
+			record.addScoreChange(playerId, ScoreChangeRecord(change))
+		}
+	}
+	for _, submission := range state.AppliedSubmissions {
+		record.AppliedSubmissions = append(record.AppliedSubmissions, SubmissionRecord{
+			PlayerId:     submission.PlayerId,
+			SubmissionId: submission.SubmissionId,
+			AppliedAt:    submission.AppliedAt,
+		})
+	}
+	return record
+}
+
+// setPlayerInfo records the level and country of a player of the competition
+func (r *CompetitionRecord) setPlayerInfo(playerId string, level int, countryCode string) {
+	if r.Levels == nil {
+		r.Levels = map[string]int{}
+	}
+	if r.Countries == nil {
+		r.Countries = map[string]string{}
+	}
+	r.Levels[playerId] = level
+	r.Countries[playerId] = countryCode
+}
+
+func (r *CompetitionRecord) addScoreChange(playerId string, change ScoreChangeRecord) {
+	if r.History == nil {
+		r.History = map[string][]ScoreChangeRecord{}
+	}
+	r.History[playerId] = append(r.History[playerId], change)
+}
+
+// insertScoreChange adds a change to the history of a player in the order of the sequence numbers, since changes
+// can be journaled in a different order than they were applied. The score of the player is the score of the last change.
+func (r *CompetitionRecord) insertScoreChange(playerId string, change ScoreChangeRecord) {
+	if r.History == nil {
+		r.History = map[string][]ScoreChangeRecord{}
+	}
+	history := r.History[playerId]
+	index := len(history)
+	if change.Seq > 0 {
+		if later := slices.IndexFunc(history, func(c ScoreChangeRecord) bool { return c.Seq > change.Seq }); later >= 0 {
+			index = later
+		}
+	}
+	if change.Source != model.SourceVoid && change.SubmissionId != "" {
+		// The submission may have been voided by a change journaled before it
+		change.Voided = slices.ContainsFunc(history[index:], func(c ScoreChangeRecord) bool {
+			return c.Source == model.SourceVoid && c.SubmissionId == change.SubmissionId
+		})
+	}
+	previous := r.Scores[playerId]
+	if index > 0 {
+		previous = history[index-1].Score
+	} else if len(history) > 0 {
+		previous = 0
+	}
+	change.Delta = change.Score - previous
+	history = slices.Insert(history, index, change)
+	if index+1 < len(history) {
+		history[index+1].Delta = history[index+1].Score - change.Score
+	}
+	r.History[playerId] = history
+	r.Scores[playerId] = history[len(history)-1].Score
+}
+
+// voidScoreChange marks the submission of a player with the sequence number as voided
+func (r *CompetitionRecord) voidScoreChange(playerId string, seq int) {
+	for i, change := range r.History[playerId] {
+		if change.Seq == seq && change.Source != model.SourceVoid {
+			r.History[playerId][i].Voided = true
+		}
+	}
+}
+
+// sameAs tells whether both records are the same change, by sequence number when both have one
+func (c ScoreChangeRecord) sameAs(other ScoreChangeRecord) bool {
+	if c.Seq > 0 && other.Seq > 0 {
+		return c.Seq == other.Seq
+	}
+	return c.At.Equal(other.At) && c.Points == other.Points && c.Score == other.Score && c.Source == other.Source &&
+		c.SubmissionId == other.SubmissionId && c.Reason == other.Reason
+}
+
+// sameAs tells whether both records are the same entry of the audit log
+func (a AuditRecord) sameAs(other AuditRecord) bool {
+	return a.At.Equal(other.At) && a.Action == other.Action && a.LeaderboardId == other.LeaderboardId &&
+		a.PlayerId == other.PlayerId && a.SubmissionId == other.SubmissionId && a.Seq == other.Seq && a.Points == other.Points &&
+		a.Reason == other.Reason
+}
+
+func (r *CompetitionRecord) applied(playerId string, submissionId string) bool {
+	return slices.ContainsFunc(r.AppliedSubmissions, func(s SubmissionRecord) bool {
+		return s.PlayerId == playerId && s.SubmissionId == submissionId
+	})
+}
+
+// RestoreInto recreates the players and competitions of the snapshot in the given store.
+// Competitions are restored in order, so each player is linked to the latest competition they joined.
+func (s *Snapshot) RestoreInto(store Store) error {
+	for _, record := range s.Players {
+		player := model.NewPlayer(record.Id, record.Level, record.CountryCode)
+		player.SetBanned(record.Banned)
+		if err := store.PutPlayer(player); err != nil {
+			return err
+		}
+	}
+	for _, record := range s.Competitions {
+		compPlayers := make([]*model.CompetingPlayer, 0, len(record.Scores))
+		for playerId, score := range record.Scores {
+			// Players keep the level and country they had when they joined, so their results are aggregated
+			// in the same boards.
+			level, countryCode := record.Levels[playerId], record.Countries[playerId]
+			player, found := store.GetPlayer(playerId)
+			if !found {
+				// Deleted players keep their entries in the competitions they played
+				player = model.NewPlayer(playerId, level, countryCode)
+			}
+			compPlayer := model.RestoreCompetingPlayer(player, level, countryCode, score)
+			history := make([]model.ScoreChange, 0, len(record.History[playerId]))
+			for _, change := range record.History[playerId] {
+				history = append(history, model.ScoreChange(change))
+			}
+			compPlayer.RestoreHistory(history, record.ScoringMode, record.StartedAt)
+			compPlayers = append(compPlayers, compPlayer)
+		}
+		comp := model.RestoreCompetition(record.Id, record.InitialLevel, record.TieBreaker, record.ScoringMode, record.StartedAt, record.EndsAt, compPlayers)
+		for _, submission := range record.AppliedSubmissions {
+			comp.RestoreSubmission(model.AppliedSubmission{
+				PlayerId:     submission.PlayerId,
+				SubmissionId: submission.SubmissionId,
+				AppliedAt:    submission.AppliedAt,
+			})
+		}
+		for _, playerId := range record.Disqualified {
+			_ = comp.Disqualify(playerId)
+		}
+		if record.Ended {
+			_ = comp.End()
+		}
+		if err := store.PutCompetition(comp); err != nil {
+			return err
+		}
+	}
+	for _, results := range groupResults(s.Results) {
+		if err := store.PutResults(results[0].CompetitionId, results); err != nil {
+			return err
+		}
+	}
+	for _, audit := range s.Audit {
+		if err := store.PutAudit(audit); err != nil {
+			return err
+		}
+	}
+	for _, webhook := range s.Webhooks {
+		if err := store.PutWebhook(webhook); err != nil {
+			return err
+		}
+	}
+	for _, deadLetter := range s.DeadLetters {
+		if err := store.PutDeadLetter(deadLetter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupResults splits results into the consecutive results of each competition
+func groupResults(results []ResultRecord) [][]ResultRecord {
+	var groups [][]ResultRecord
+	start := 0
+	for i := range results {
+		if i == len(results)-1 || results[i+1].CompetitionId != results[i].CompetitionId {
+			groups = append(groups, results[start:i+1])
+			start = i + 1
+		}
+	}
+	return groups
+}
+
+// ReadSnapshotFile reads a snapshot from disk. A missing file results in an empty snapshot
+func ReadSnapshotFile(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Snapshot{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot %s: %w", path, err)
+	}
+	return &snapshot, nil
+}
+
+// WriteSnapshotFile atomically replaces the snapshot on disk
+func WriteSnapshotFile(path string, snapshot *Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
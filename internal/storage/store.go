@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"errors"
+	"leaderboard/internal/model"
+)
+
+var (
+	ErrPlayerNil       = errors.New("player cannot be nil")
+	ErrCompetitionNil  = errors.New("competition cannot be nil")
+	ErrResultsRecorded = errors.New("results of the competition are already recorded")
+)
+
+// Current is the store used by the application. It is selected at startup in main.go
+var Current Store = NewMemoryStore()
+
+// Store defines the contract for persisting players, competitions and scores
+type Store interface {
+	GetPlayer(id string) (*model.Player, bool)
+	PutPlayer(player *model.Player) error
+	DeletePlayer(id string) error
+	ListPlayers() []*model.Player
+
+	GetCompetition(id string) (model.ICompetition, bool)
+	PutCompetition(comp model.ICompetition) error
+	DeleteCompetition(id string) error
+	// ListCompetitions returns the competitions in the order they were first stored
+	ListCompetitions() []model.ICompetition
+
+	// PutScore records an accepted score submission of a player in a competition and the resulting total score
+	PutScore(competitionId string, playerId string, change model.ScoreChange) error
+
+	// PutResults records the final standings of an ended competition.
+	// Results are kept after the competition is deleted and can only be recorded once per competition.
+	PutResults(competitionId string, results []ResultRecord) error
+	// ListResults returns the results of all ended competitions in the order they were recorded
+	ListResults() []ResultRecord
+
+	// PutAudit appends a moderation to the audit log
+	PutAudit(audit AuditRecord) error
+	// ListAudit returns the audit log in the order the moderations were recorded
+	ListAudit() []AuditRecord
+
+	// PutWebhook registers a webhook or replaces the webhook with the same ID
+	PutWebhook(webhook WebhookRecord) error
+	DeleteWebhook(id string) error
+	// ListWebhooks returns the webhooks in the order they were registered
+	ListWebhooks() []WebhookRecord
+
+	// PutDeadLetter keeps a delivery whose attempts all failed, or replaces the dead letter with the same ID
+	PutDeadLetter(deadLetter DeadLetterRecord) error
+	DeleteDeadLetter(id string) error
+	// ListDeadLetters returns the dead letters in the order they were kept
+	ListDeadLetters() []DeadLetterRecord
+}
@@ -0,0 +1,201 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Each entry is framed as [length uint32][crc32 uint32][data] so a torn write at the end of the
+// file can be detected and discarded on replay.
+const (
+	headerSize   = 8
+	maxEntrySize = 1 << 20
+)
+
+var (
+	ErrLogClosed     = errors.New("log is closed")
+	ErrEntryEmpty    = errors.New("log entry cannot be empty")
+	ErrEntryTooLarge = errors.New("log entry is too large")
+)
+
+// Log is an append-only log file.
+// Entries are written to the file immediately, so they survive a process crash.
+// fsync is batched and runs at most once per sync interval, which bounds what can be lost on a machine crash.
+type Log struct {
+	mutex  sync.Mutex
+	file   *os.File
+	dirty  bool
+	closed bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Open replays the existing entries of the log at path through apply and opens it for appending.
+// A torn or corrupt entry at the end of the file is discarded.
+func Open(path string, syncInterval time.Duration, apply func(entry []byte) error) (*Log, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	validSize, err := replay(file, apply)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Truncate(validSize); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(validSize, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	l := &Log{
+		file: file,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go l.syncLoop(syncInterval)
+	return l, nil
+}
+
+// Append writes an entry at the end of the log
+func (l *Log) Append(entry []byte) error {
+	if len(entry) == 0 {
+		return ErrEntryEmpty
+	} else if len(entry) > maxEntrySize {
+		return ErrEntryTooLarge
+	}
+	frame := make([]byte, headerSize+len(entry))
+	binary.LittleEndian.PutUint32(frame[0:4], uint32(len(entry)))
+	binary.LittleEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(entry))
+	copy(frame[headerSize:], entry)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return ErrLogClosed
+	}
+	if _, err := l.file.Write(frame); err != nil {
+		return err
+	}
+	l.dirty = true
+	return nil
+}
+
+// Sync flushes appended entries to stable storage
+func (l *Log) Sync() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.sync()
+}
+
+func (l *Log) sync() error {
+	if l.closed || !l.dirty {
+		return nil
+	}
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+	l.dirty = false
+	return nil
+}
+
+// Truncate discards all entries. Used after the state has been compacted into a snapshot
+func (l *Log) Truncate() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return ErrLogClosed
+	}
+	if err := l.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := l.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	l.dirty = true
+	return l.sync()
+}
+
+// Close syncs and closes the log file
+func (l *Log) Close() error {
+	l.mutex.Lock()
+	if l.closed {
+		l.mutex.Unlock()
+		return nil
+	}
+	err := l.sync()
+	l.closed = true
+	l.mutex.Unlock()
+
+	close(l.stop)
+	<-l.done
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (l *Log) syncLoop(interval time.Duration) {
+	defer close(l.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := l.Sync(); err != nil {
+				log.Printf("Failed to sync log %s: %v", l.file.Name(), err)
+			}
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// replay applies every complete entry and returns the size of the valid part of the file
+func replay(file *os.File, apply func(entry []byte) error) (int64, error) {
+	reader := bufio.NewReader(file)
+	header := make([]byte, headerSize)
+	var offset int64
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return offset, nil
+			}
+			return 0, err
+		}
+		size := binary.LittleEndian.Uint32(header[0:4])
+		checksum := binary.LittleEndian.Uint32(header[4:8])
+		if size == 0 || size > maxEntrySize {
+			log.Printf("Discarding corrupt entries at offset %d of log %s", offset, file.Name())
+			return offset, nil
+		}
+
+		entry := make([]byte, size)
+		if _, err := io.ReadFull(reader, entry); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return offset, nil
+			}
+			return 0, err
+		}
+		if crc32.ChecksumIEEE(entry) != checksum {
+			log.Printf("Discarding corrupt entries at offset %d of log %s", offset, file.Name())
+			return offset, nil
+		}
+		if err := apply(entry); err != nil {
+			return 0, err
+		}
+		offset += int64(headerSize) + int64(size)
+	}
+}
@@ -0,0 +1,114 @@
+package wal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openForTest(t *testing.T, path string) (*Log, []string) {
+	var entries []string
+	l, err := Open(path, time.Hour, func(entry []byte) error {
+		entries = append(entries, string(entry))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Open() returned error %v", err)
+	}
+	return l, entries
+}
+
+func TestLog_AppendAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.wal")
+	l, entries := openForTest(t, path)
+	if len(entries) != 0 {
+		t.Fatalf("expected no entries in a new log, got %v", entries)
+	}
+	for _, entry := range []string{"one", "two", "three"} {
+		if err := l.Append([]byte(entry)); err != nil {
+			t.Fatalf("Append() returned error %v", err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close() returned error %v", err)
+	}
+
+	l, entries = openForTest(t, path)
+	defer l.Close()
+	if len(entries) != 3 || entries[0] != "one" || entries[1] != "two" || entries[2] != "three" {
+		t.Errorf("expected entries to be replayed in order, got %v", entries)
+	}
+}
+
+func TestLog_TornTailIsDiscarded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.wal")
+	l, _ := openForTest(t, path)
+	_ = l.Append([]byte("complete"))
+	_ = l.Append([]byte("torn"))
+	_ = l.Close()
+
+	// Simulate a crash in the middle of writing the last entry
+	info, _ := os.Stat(path)
+	if err := os.Truncate(path, info.Size()-2); err != nil {
+		t.Fatalf("failed to truncate log: %v", err)
+	}
+
+	l, entries := openForTest(t, path)
+	if len(entries) != 1 || entries[0] != "complete" {
+		t.Fatalf("expected only the complete entry, got %v", entries)
+	}
+	// New entries are appended after the last complete entry
+	_ = l.Append([]byte("after"))
+	_ = l.Close()
+
+	l, entries = openForTest(t, path)
+	defer l.Close()
+	if len(entries) != 2 || entries[1] != "after" {
+		t.Errorf("expected entry appended after the torn tail to be replayed, got %v", entries)
+	}
+}
+
+func TestLog_CorruptEntryIsDiscarded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.wal")
+	l, _ := openForTest(t, path)
+	_ = l.Append([]byte("first"))
+	_ = l.Append([]byte("second"))
+	_ = l.Close()
+
+	data, _ := os.ReadFile(path)
+	data[len(data)-1] ^= 0xff
+	_ = os.WriteFile(path, data, 0o644)
+
+	l, entries := openForTest(t, path)
+	defer l.Close()
+	if len(entries) != 1 || entries[0] != "first" {
+		t.Errorf("expected corrupt entry to be discarded, got %v", entries)
+	}
+}
+
+func TestLog_Truncate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.wal")
+	l, _ := openForTest(t, path)
+	_ = l.Append([]byte("old"))
+	if err := l.Truncate(); err != nil {
+		t.Fatalf("Truncate() returned error %v", err)
+	}
+	_ = l.Append([]byte("new"))
+	_ = l.Close()
+
+	l, entries := openForTest(t, path)
+	defer l.Close()
+	if len(entries) != 1 || entries[0] != "new" {
+		t.Errorf("expected only entries appended after truncate, got %v", entries)
+	}
+}
+
+func TestLog_AppendAfterClose(t *testing.T) {
+	l, _ := openForTest(t, filepath.Join(t.TempDir(), "test.wal"))
+	_ = l.Close()
+
+	if err := l.Append([]byte("late")); err != ErrLogClosed {
+		t.Errorf("expected ErrLogClosed, got %v", err)
+	}
+}
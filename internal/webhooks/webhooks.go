@@ -0,0 +1,369 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"leaderboard/internal/config"
+	"leaderboard/internal/events"
+	"leaderboard/internal/leaderboard"
+	"leaderboard/internal/storage"
+	"leaderboard/internal/timeprovider"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	ErrUrlInvalid       = errors.New("webhook URL must be an absolute http or https URL")
+	ErrSecretEmpty      = errors.New("webhook secret cannot be empty")
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("dead letter not found")
+)
+
+// EventCompetitionEnded is the event of the deliveries sent when a competition ends
+const EventCompetitionEnded = "competition_ended"
+
+// Headers of the deliveries
+const (
+	HeaderEvent     = "X-Leaderboard-Event"
+	HeaderDelivery  = "X-Leaderboard-Delivery"
+	HeaderSignature = "X-Leaderboard-Signature"
+)
+
+// Webhook is a registered endpoint, without its secret
+type Webhook struct {
+	Id        string    `json:"id"`
+	Url       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Payload is the body of a delivery
+type Payload struct {
+	Event      string    `json:"event"`
+	DeliveryId string    `json:"delivery_id"`
+	At         time.Time `json:"at"`
+	// When the competition was ended, after its scheduled end in the leaderboard
+	EndedAt time.Time `json:"ended_at"`
+	// Final leaderboard of the competition
+	Leaderboard *leaderboard.LeaderboardResponse `json:"leaderboard"`
+}
+
+// Delivery is a payload sent to a webhook, kept in the dead letters once all its attempts failed
+type Delivery struct {
+	Id            string          `json:"id"`
+	WebhookId     string          `json:"webhook_id"`
+	Url           string          `json:"url"`
+	Event         string          `json:"event"`
+	Attempts      int             `json:"attempts"`
+	LastAttemptAt time.Time       `json:"last_attempt_at"`
+	LastError     string          `json:"last_error"`
+	Payload       json.RawMessage `json:"payload"`
+	secret        string
+}
+
+var (
+	// Serializes the changes of the dead letters, which are persisted by the store
+	deadLettersMutex sync.Mutex
+
+	// Goroutines preparing and sending the deliveries. Their context is cancelled by the detach function of Attach
+	workers = struct {
+		sync.Mutex
+		ctx     context.Context
+		running sync.WaitGroup
+	}{ctx: context.Background()}
+
+	deliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "leaderboard_webhook_deliveries_total",
+		Help: "The total number of webhook deliveries by result: delivered or dead_lettered",
+	}, []string{"result"})
+)
+
+// Attach subscribes to the end of the competitions to deliver their final leaderboard to the webhooks.
+// The subscriber is synchronous so no competition is missed, and the deliveries are prepared in their own goroutine
+// since the competition is locked while the event is published. Returns a function that unsubscribes, interrupts
+// the deliveries in progress and waits for them to be kept as dead letters.
+func Attach() (detach func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	workers.Lock()
+	workers.ctx = ctx
+	workers.Unlock()
+	unsubscribe := events.Subscribe(func(ended events.CompetitionEnded) {
+		spawn(func(context.Context) { competitionEnded(ended) })
+	})
+	return func() {
+		unsubscribe()
+		workers.Lock()
+		cancel()
+		workers.Unlock()
+		workers.running.Wait()
+	}
+}
+
+// spawn runs work in its own goroutine with the context of the workers.
+// Returns false once the workers are stopped.
+func spawn(work func(ctx context.Context)) bool {
+	workers.Lock()
+	defer workers.Unlock()
+	ctx := workers.ctx
+	if ctx.Err() != nil {
+		return false
+	}
+	workers.running.Add(1)
+	go func() {
+		defer workers.running.Done()
+		work(ctx)
+	}()
+	return true
+}
+
+// startDelivery delivers a payload in its own goroutine, or keeps it as a dead letter once the workers are stopped
+func startDelivery(delivery *Delivery) {
+	if !spawn(func(ctx context.Context) { deliver(ctx, delivery) }) {
+		delivery.LastError = "not delivered before the shutdown"
+		deadLetter(delivery)
+	}
+}
+
+// RegisterWebhook registers an endpoint receiving the final leaderboard of the ended competitions.
+// Payloads are signed with the secret.
+var RegisterWebhook = func(webhookUrl, secret string) (*Webhook, error) {
+	parsed, err := url.Parse(webhookUrl)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, ErrUrlInvalid
+	}
+	if secret == "" {
+		return nil, ErrSecretEmpty
+	}
+	record := storage.WebhookRecord{Id: uuid.New().String(), Url: webhookUrl, Secret: secret, CreatedAt: timeprovider.Current.Now()}
+	if err := storage.Current.PutWebhook(record); err != nil {
+		return nil, err
+	}
+	return asWebhook(record), nil
+}
+
+// ListWebhooks returns the registered webhooks in the order they were registered
+var ListWebhooks = func() []Webhook {
+	webhooks := []Webhook{}
+	for _, record := range storage.Current.ListWebhooks() {
+		webhooks = append(webhooks, *asWebhook(record))
+	}
+	return webhooks
+}
+
+// DeleteWebhook stops the deliveries to a webhook. Deliveries being retried are still attempted
+var DeleteWebhook = func(id string) error {
+	if _, found := getWebhook(id); !found {
+		return ErrWebhookNotFound
+	}
+	return storage.Current.DeleteWebhook(id)
+}
+
+// GetDeadLetters returns the deliveries whose attempts all failed, oldest first
+var GetDeadLetters = func() []Delivery {
+	letters := []Delivery{}
+	for _, record := range storage.Current.ListDeadLetters() {
+		letters = append(letters, *asDelivery(record))
+	}
+	return letters
+}
+
+// ReplayDelivery removes a delivery from the dead letters and delivers its payload again to the current URL and
+// with the current secret of its webhook
+var ReplayDelivery = func(id string) (*Delivery, error) {
+	delivery, err := takeDeadLetter(id)
+	if err != nil {
+		return nil, err
+	}
+	replayed := *delivery
+	startDelivery(delivery)
+	return &replayed, nil
+}
+
+// takeDeadLetter removes a delivery from the dead letters and prepares it to be delivered again
+func takeDeadLetter(id string) (*Delivery, error) {
+	deadLettersMutex.Lock()
+	defer deadLettersMutex.Unlock()
+	letters := storage.Current.ListDeadLetters()
+	index := slices.IndexFunc(letters, func(record storage.DeadLetterRecord) bool { return record.Id == id })
+	if index < 0 {
+		return nil, ErrDeliveryNotFound
+	}
+	delivery := asDelivery(letters[index])
+	webhook, found := getWebhook(delivery.WebhookId)
+	if !found {
+		return nil, ErrWebhookNotFound
+	}
+	if err := storage.Current.DeleteDeadLetter(id); err != nil {
+		return nil, err
+	}
+
+	delivery.Url, delivery.secret = webhook.Url, webhook.Secret
+	delivery.Attempts, delivery.LastError = 0, ""
+	return delivery, nil
+}
+
+func competitionEnded(ended events.CompetitionEnded) {
+	webhooks := storage.Current.ListWebhooks()
+	if len(webhooks) == 0 {
+		return
+	}
+	// The final leaderboard is taken from the event, since the competition may already be deleted
+	finalLeaderboard := &leaderboard.LeaderboardResponse{Id: ended.LeaderboardId, EndsAt: ended.EndsAt, Leaderboard: []leaderboard.PlayerScore{}}
+	for _, standing := range ended.Standings {
+		// Disqualified players are not ranked
+		if standing.Rank > 0 {
+			finalLeaderboard.Leaderboard = append(finalLeaderboard.Leaderboard, leaderboard.PlayerScore{Rank: standing.Rank, PlayerId: standing.PlayerId, Score: standing.Score})
+		}
+	}
+	finalLeaderboard.Total = len(finalLeaderboard.Leaderboard)
+
+	for _, webhook := range webhooks {
+		delivery := &Delivery{Id: uuid.New().String(), WebhookId: webhook.Id, Url: webhook.Url, Event: EventCompetitionEnded, secret: webhook.Secret}
+		var err error
+		delivery.Payload, err = json.Marshal(Payload{
+			Event:       EventCompetitionEnded,
+			DeliveryId:  delivery.Id,
+			At:          ended.EndedAt,
+			EndedAt:     ended.EndedAt,
+			Leaderboard: finalLeaderboard,
+		})
+		if err != nil {
+			log.Printf("Failed to encode the payload of competition %s for webhook %s: %v", ended.LeaderboardId, webhook.Id, err)
+			continue
+		}
+		startDelivery(delivery)
+	}
+}
+
+// deliver attempts to send a delivery up to config.WebhookMaxAttempts times with an exponential backoff,
+// then moves it to the dead letters. A delivery interrupted by the cancellation of ctx is moved to the dead letters
+// right away, so it can be replayed.
+func deliver(ctx context.Context, delivery *Delivery) {
+	backoff := config.WebhookInitialBackoff
+attempts:
+	for {
+		delivery.Attempts++
+		delivery.LastAttemptAt = timeprovider.Current.Now()
+		err := send(ctx, delivery)
+		if err == nil {
+			deliveries.WithLabelValues("delivered").Inc()
+			return
+		}
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= config.WebhookMaxAttempts {
+			break
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			break attempts
+		}
+		backoff = min(backoff*2, config.WebhookMaxBackoff)
+	}
+
+	log.Printf("Failed to deliver %s to webhook %s after %d attempts: %s", delivery.Id, delivery.WebhookId, delivery.Attempts, delivery.LastError)
+	deadLetter(delivery)
+}
+
+// deadLetter keeps a delivery in the dead letters, up to config.MaxDeadLetters
+func deadLetter(delivery *Delivery) {
+	deliveries.WithLabelValues("dead_lettered").Inc()
+	deadLettersMutex.Lock()
+	defer deadLettersMutex.Unlock()
+	if err := storage.Current.PutDeadLetter(asDeadLetterRecord(delivery)); err != nil {
+		log.Printf("Failed to keep the dead letter %s: %v", delivery.Id, err)
+		return
+	}
+	letters := storage.Current.ListDeadLetters()
+	for _, oldest := range letters[:max(len(letters)-config.MaxDeadLetters, 0)] {
+		if err := storage.Current.DeleteDeadLetter(oldest.Id); err != nil {
+			log.Printf("Failed to drop the dead letter %s: %v", oldest.Id, err)
+		}
+	}
+}
+
+// send posts the payload of a delivery, signed with the HMAC-SHA256 of the secret of the webhook
+func send(ctx context.Context, delivery *Delivery) error {
+	ctx, cancel := context.WithTimeout(ctx, config.WebhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.Id)
+	req.Header.Set(HeaderSignature, Sign(delivery.secret, delivery.Payload))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the signature of a payload sent in the X-Leaderboard-Signature header: "sha256=" followed by the
+// hexadecimal HMAC-SHA256 of the payload with the secret of the webhook
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func getWebhook(id string) (storage.WebhookRecord, bool) {
+	for _, webhook := range storage.Current.ListWebhooks() {
+		if webhook.Id == id {
+			return webhook, true
+		}
+	}
+	return storage.WebhookRecord{}, false
+}
+
+func asWebhook(record storage.WebhookRecord) *Webhook {
+	return &Webhook{Id: record.Id, Url: record.Url, CreatedAt: record.CreatedAt}
+}
+
+func asDelivery(record storage.DeadLetterRecord) *Delivery {
+	return &Delivery{
+		Id:            record.Id,
+		WebhookId:     record.WebhookId,
+		Url:           record.Url,
+		Event:         record.Event,
+		Attempts:      record.Attempts,
+		LastAttemptAt: record.LastAttemptAt,
+		LastError:     record.LastError,
+		Payload:       record.Payload,
+	}
+}
+
+func asDeadLetterRecord(delivery *Delivery) storage.DeadLetterRecord {
+	return storage.DeadLetterRecord{
+		Id:            delivery.Id,
+		WebhookId:     delivery.WebhookId,
+		Url:           delivery.Url,
+		Event:         delivery.Event,
+		Attempts:      delivery.Attempts,
+		LastAttemptAt: delivery.LastAttemptAt,
+		LastError:     delivery.LastError,
+		Payload:       delivery.Payload,
+	}
+}
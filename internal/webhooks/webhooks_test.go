@@ -0,0 +1,234 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"leaderboard/internal/config"
+	"leaderboard/internal/events"
+	"leaderboard/internal/model"
+	"leaderboard/internal/storage"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// received is a delivery accepted by the test receiver
+type received struct {
+	header  http.Header
+	payload Payload
+	valid   bool
+}
+
+// receiver accepts the deliveries once failures requests have been answered with an error
+func receiver(t *testing.T, failures int32) (*httptest.Server, chan received) {
+	deliveries := make(chan received, 10)
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		delivery := received{header: r.Header, valid: r.Header.Get(HeaderSignature) == Sign("secret", body)}
+		_ = json.Unmarshal(body, &delivery.payload)
+		deliveries <- delivery
+	}))
+	t.Cleanup(server.Close)
+	return server, deliveries
+}
+
+// setupWebhooks stores a started competition where bob leads alice and attaches the webhooks
+func setupWebhooks(t *testing.T) model.ICompetition {
+	return setupWebhooksWithPlayers(t, "alice", "bob")
+}
+
+func setupWebhooksWithPlayers(t *testing.T, playerIds ...string) model.ICompetition {
+	storage.Current = storage.NewMemoryStore()
+	comp := model.NewCompetition(1)
+	for _, id := range playerIds {
+		player := model.NewPlayer(id, 1, "US")
+		_ = storage.Current.PutPlayer(player)
+		_ = comp.AddPlayer(player)
+	}
+	_ = comp.Start()
+	_ = comp.AddScore("bob", 20)
+	_ = storage.Current.PutCompetition(comp)
+
+	initialBackoff, maxAttempts := config.WebhookInitialBackoff, config.WebhookMaxAttempts
+	config.WebhookInitialBackoff = time.Millisecond
+	detach := Attach()
+	t.Cleanup(func() {
+		detach()
+		config.WebhookInitialBackoff, config.WebhookMaxAttempts = initialBackoff, maxAttempts
+		storage.Current = storage.NewMemoryStore()
+	})
+	return comp
+}
+
+func receive(t *testing.T, deliveries chan received) received {
+	t.Helper()
+	select {
+	case delivery := <-deliveries:
+		return delivery
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected a delivery")
+		return received{}
+	}
+}
+
+func TestCompetitionEnded_DeliversSignedLeaderboard(t *testing.T) {
+	comp := setupWebhooks(t)
+	// The first attempts fail and are retried
+	server, deliveries := receiver(t, 2)
+	if _, err := RegisterWebhook(server.URL, "secret"); err != nil {
+		t.Fatalf("RegisterWebhook() returned error %v", err)
+	}
+
+	_ = comp.End()
+
+	delivery := receive(t, deliveries)
+	if !delivery.valid {
+		t.Errorf("expected a valid signature, got %q", delivery.header.Get(HeaderSignature))
+	}
+	if delivery.header.Get(HeaderEvent) != EventCompetitionEnded || delivery.header.Get(HeaderDelivery) != delivery.payload.DeliveryId {
+		t.Errorf("unexpected headers %v", delivery.header)
+	}
+	leaderboard := delivery.payload.Leaderboard
+	if leaderboard == nil || leaderboard.Id != comp.Id() || len(leaderboard.Leaderboard) != 2 || leaderboard.Leaderboard[0].PlayerId != "bob" {
+		t.Errorf("expected the final leaderboard led by bob, got %+v", leaderboard)
+	}
+	if leaderboard != nil && !leaderboard.EndsAt.Equal(comp.EndsAt()) {
+		t.Errorf("expected the scheduled end %v, got %v", comp.EndsAt(), leaderboard.EndsAt)
+	}
+	if delivery.payload.EndedAt.IsZero() {
+		t.Errorf("expected the time the competition was ended, got %+v", delivery.payload)
+	}
+}
+
+func TestDetach_InterruptsRetries(t *testing.T) {
+	storage.Current = storage.NewMemoryStore()
+	defer func(initialBackoff time.Duration) {
+		config.WebhookInitialBackoff = initialBackoff
+		storage.Current = storage.NewMemoryStore()
+	}(config.WebhookInitialBackoff)
+	config.WebhookInitialBackoff = time.Hour
+	attempted := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		attempted <- struct{}{}
+	}))
+	defer server.Close()
+	webhook, _ := RegisterWebhook(server.URL, "secret")
+	detach := Attach()
+
+	events.Publish(events.CompetitionEnded{LeaderboardId: "comp1"})
+	select {
+	case <-attempted:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected a delivery attempt")
+	}
+	stopped := make(chan struct{})
+	go func() {
+		detach()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected detach to interrupt the backoff")
+	}
+	if letters := GetDeadLetters(); len(letters) != 1 || letters[0].WebhookId != webhook.Id || letters[0].Attempts != 1 {
+		t.Errorf("expected the interrupted delivery to be dead lettered after 1 attempt, got %+v", letters)
+	}
+}
+
+func TestDeliver_DeadLetterAndReplay(t *testing.T) {
+	comp := setupWebhooks(t)
+	config.WebhookMaxAttempts = 2
+	server, deliveries := receiver(t, 2)
+	webhook, _ := RegisterWebhook(server.URL, "secret")
+
+	_ = comp.End()
+	for deadline := time.Now().Add(5 * time.Second); len(GetDeadLetters()) == 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	letters := GetDeadLetters()
+	if len(letters) != 1 || letters[0].WebhookId != webhook.Id || letters[0].Attempts != 2 || letters[0].LastError == "" {
+		t.Fatalf("expected the delivery to be dead lettered after 2 attempts, got %+v", letters)
+	}
+	if records := storage.Current.ListDeadLetters(); len(records) != 1 || records[0].Id != letters[0].Id {
+		t.Errorf("expected the dead letter to be kept by the store, got %+v", records)
+	}
+
+	if _, err := ReplayDelivery("unknown"); err != ErrDeliveryNotFound {
+		t.Errorf("expected %v, got %v", ErrDeliveryNotFound, err)
+	}
+	if _, err := ReplayDelivery(letters[0].Id); err != nil {
+		t.Fatalf("ReplayDelivery() returned error %v", err)
+	}
+	if delivery := receive(t, deliveries); !delivery.valid || delivery.payload.DeliveryId != letters[0].Id {
+		t.Errorf("expected the dead letter to be delivered again, got %+v", delivery.payload)
+	}
+	if len(GetDeadLetters()) != 0 {
+		t.Errorf("expected no dead letters left, got %+v", GetDeadLetters())
+	}
+}
+
+func TestCompetitionEnded_DeliversAllStandingsOfDeletedCompetition(t *testing.T) {
+	playerIds := make([]string, config.MaxLeaderboardPageSize+10)
+	for i := range playerIds {
+		playerIds[i] = fmt.Sprintf("p%03d", i)
+	}
+	defer func(maxPlayers int) { config.MaxPlayersForCompetition = maxPlayers }(config.MaxPlayersForCompetition)
+	config.MaxPlayersForCompetition = len(playerIds)
+	comp := setupWebhooksWithPlayers(t, playerIds...)
+	_ = comp.Disqualify("p000")
+	server, deliveries := receiver(t, 0)
+	_, _ = RegisterWebhook(server.URL, "secret")
+
+	_ = comp.End()
+	_ = storage.Current.DeleteCompetition(comp.Id())
+
+	leaderboard := receive(t, deliveries).payload.Leaderboard
+	if leaderboard == nil || leaderboard.Total != len(playerIds)-1 || len(leaderboard.Leaderboard) != len(playerIds)-1 {
+		t.Fatalf("expected the %d ranked players, got %+v", len(playerIds)-1, leaderboard)
+	}
+	for i, score := range leaderboard.Leaderboard {
+		if score.PlayerId == "p000" || score.Rank != i+1 {
+			t.Errorf("expected rank %d without the disqualified p000, got %+v", i+1, score)
+		}
+	}
+}
+
+func TestWebhooks_Registration(t *testing.T) {
+	setupWebhooks(t)
+
+	tests := []struct {
+		url      string
+		secret   string
+		expected error
+	}{
+		{"ftp://rewards.local/hook", "secret", ErrUrlInvalid},
+		{"/hook", "secret", ErrUrlInvalid},
+		{"http://rewards.local/hook", "", ErrSecretEmpty},
+	}
+	for _, tt := range tests {
+		if _, err := RegisterWebhook(tt.url, tt.secret); err != tt.expected {
+			t.Errorf("for %q expected %v, got %v", tt.url, tt.expected, err)
+		}
+	}
+
+	webhook, _ := RegisterWebhook("http://rewards.local/hook", "secret")
+	if webhooks := ListWebhooks(); len(webhooks) != 1 || webhooks[0].Id != webhook.Id {
+		t.Errorf("expected the registered webhook, got %+v", webhooks)
+	}
+	if err := DeleteWebhook(webhook.Id); err != nil {
+		t.Errorf("DeleteWebhook() returned error %v", err)
+	}
+	if err := DeleteWebhook(webhook.Id); err != ErrWebhookNotFound {
+		t.Errorf("expected %v, got %v", ErrWebhookNotFound, err)
+	}
+}
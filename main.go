@@ -2,19 +2,81 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"leaderboard/internal/api"
+	"leaderboard/internal/config"
+	"leaderboard/internal/leaderboard"
+	"leaderboard/internal/live"
+	"leaderboard/internal/matchmaking"
+	"leaderboard/internal/model"
+	"leaderboard/internal/notifications"
 	"leaderboard/internal/storage"
+	"leaderboard/internal/webhooks"
 )
 
 func main() {
-	storage.LoadDummyPlayers()
+	flag.StringVar(&config.StorageType, "storage", config.StorageType, "Storage type: memory, file or wal")
+	flag.StringVar(&config.StorageFilePath, "storage-file", config.StorageFilePath, "Snapshot file used by the file and wal storages")
+	flag.StringVar(&config.WalFilePath, "wal-file", config.WalFilePath, "Write-ahead log used by the wal storage")
+	flag.StringVar(&config.MatchmakingMode, "matchmaking", config.MatchmakingMode, "Matchmaking mode: "+strings.Join(matchmaking.Modes(), ", "))
+	flag.StringVar(&config.MatchmakingEngine, "matchmaking-engine", config.MatchmakingEngine, "Matchmaking engine: loop or mutex")
+	flag.StringVar(&config.RankTiePolicy, "rank-ties", config.RankTiePolicy, "Rank of tied scores: ordinal, competition or dense")
+	flag.StringVar(&config.ScoringMode, "scoring-mode", config.ScoringMode, "Scoring mode of competitions: increment, penalties, best, latest or window")
+	flag.StringVar(&config.TieBreaker, "tie-breaker", config.TieBreaker, "Order of tied scores: player_id, earliest, fewest_submissions, higher_level, lower_level or shared")
+	flag.StringVar(&config.AdminToken, "admin-token", config.AdminToken, "Bearer token required by the admin endpoints, disabled if empty")
+	flag.Parse()
+
+	if err := matchmaking.SetStrategy(config.MatchmakingMode); err != nil {
+		log.Fatalf("Unknown matchmaking mode %q", config.MatchmakingMode)
+	}
+	if err := model.ValidateTiePolicy(config.RankTiePolicy); err != nil {
+		log.Fatalf("Unknown rank tie policy %q", config.RankTiePolicy)
+	}
+	if err := model.ValidateTieBreaker(config.TieBreaker); err != nil {
+		log.Fatalf("Unknown tie-breaker %q", config.TieBreaker)
+	}
+	if err := model.ValidateScoringMode(config.ScoringMode); err != nil {
+		log.Fatalf("Unknown scoring mode %q", config.ScoringMode)
+	}
+	for mode, scoringMode := range config.ScoringModes {
+		if err := model.ValidateScoringMode(scoringMode); err != nil {
+			log.Fatalf("Unknown scoring mode %q for matchmaking mode %q", scoringMode, mode)
+		}
+	}
+	for mode, tieBreaker := range config.TieBreakers {
+		if err := model.ValidateTieBreaker(tieBreaker); err != nil {
+			log.Fatalf("Unknown tie-breaker %q for matchmaking mode %q", tieBreaker, mode)
+		}
+	}
+	if err := matchmaking.SetEngine(config.MatchmakingEngine); err != nil {
+		log.Fatalf("Unknown matchmaking engine %q", config.MatchmakingEngine)
+	}
+
+	store, err := openStore()
+	if err != nil {
+		log.Fatalf("Failed to open %s storage: %v", config.StorageType, err)
+	}
+	storage.Current = store
+
+	if len(storage.Current.ListPlayers()) == 0 {
+		storage.LoadDummyPlayers()
+	}
+	live.Attach()
+	notifications.Attach()
+	detachWebhooks := webhooks.Attach()
+	matchmaking.Restore()
+	leaderboard.RestoreAggregates()
+	stopFinalizer := matchmaking.StartFinalizer(config.FinalizeInterval)
 
 	server := &http.Server{
 		Addr:    ":8080", // TODO: Conmfigure port from environment variable or config file
@@ -38,4 +100,25 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second) // TODO: Configure graceful shutdown timeout
 	defer cancel()
 	_ = server.Shutdown(ctx)
+	stopFinalizer()
+	detachWebhooks()
+
+	if closer, ok := storage.Current.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("Failed to close storage: %v", err)
+		}
+	}
+}
+
+func openStore() (storage.Store, error) {
+	switch config.StorageType {
+	case "memory":
+		return storage.NewMemoryStore(), nil
+	case "file":
+		return storage.OpenFileStore(config.StorageFilePath)
+	case "wal":
+		return storage.OpenJournaledStore(config.StorageFilePath, config.WalFilePath, config.WalSyncInterval, config.WalCompactionInterval)
+	default:
+		return nil, fmt.Errorf("unknown storage type %q", config.StorageType)
+	}
 }